@@ -0,0 +1,69 @@
+package assignment
+
+import "sort"
+
+// Entry is a submission judges review, a participant competing alone or, when
+// TeamID isn't 0, a whole team
+type Entry struct {
+	UserID uint
+	TeamID uint
+}
+
+// Plan picks judges for every entry reviewed by fewer than reviews judges so far.
+// assigned holds the judges each entry already has, which stay. An entry never
+// gets a judge twice nor one conflicted with it, and every pick goes to the judge
+// with the fewest entries, so loads stay within one of each other wherever
+// conflicts allow. The entries with the fewest judges to pick from are served
+// first.
+//
+// Plan returns the judges picked for each entry and the entries that are still
+// short of reviews because too few judges are free of conflicts.
+func Plan(entries []Entry, judges []uint, reviews int, assigned map[Entry][]uint, conflicted func(judgeID uint, entry Entry) bool) (map[Entry][]uint, []Entry) {
+	load := make(map[uint]int)
+	for _, entryJudges := range assigned {
+		for _, judgeID := range entryJudges {
+			load[judgeID]++
+		}
+	}
+
+	eligible := make(map[Entry][]uint)
+	for _, entry := range entries {
+		taken := make(map[uint]bool)
+		for _, judgeID := range assigned[entry] {
+			taken[judgeID] = true
+		}
+		for _, judgeID := range judges {
+			if !taken[judgeID] && !conflicted(judgeID, entry) {
+				eligible[entry] = append(eligible[entry], judgeID)
+			}
+		}
+	}
+
+	ordered := append([]Entry(nil), entries...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return len(eligible[ordered[i]]) < len(eligible[ordered[j]])
+	})
+
+	planned := make(map[Entry][]uint)
+	var short []Entry
+	for _, entry := range ordered {
+		need := reviews - len(assigned[entry])
+		if need <= 0 {
+			continue
+		}
+
+		candidates := append([]uint(nil), eligible[entry]...)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return load[candidates[i]] < load[candidates[j]]
+		})
+		if need > len(candidates) {
+			short = append(short, entry)
+			need = len(candidates)
+		}
+		for _, judgeID := range candidates[:need] {
+			planned[entry] = append(planned[entry], judgeID)
+			load[judgeID]++
+		}
+	}
+	return planned, short
+}
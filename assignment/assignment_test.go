@@ -0,0 +1,50 @@
+package assignment
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func noConflicts(uint, Entry) bool { return false }
+
+func TestPlanBalancesLoad(t *testing.T) {
+	var entries []Entry
+	for i := uint(1); i <= 30; i++ {
+		entries = append(entries, Entry{UserID: i})
+	}
+	judges := []uint{100, 101, 102, 103}
+
+	planned, short := Plan(entries, judges, 2, nil, noConflicts)
+	require.Empty(t, short)
+
+	load := make(map[uint]int)
+	for _, entry := range entries {
+		require.Len(t, planned[entry], 2)
+		require.NotEqual(t, planned[entry][0], planned[entry][1])
+		for _, judgeID := range planned[entry] {
+			load[judgeID]++
+		}
+	}
+	for _, judgeID := range judges {
+		require.Equal(t, 15, load[judgeID])
+	}
+}
+
+func TestPlanRespectsConflictsAndExistingJudges(t *testing.T) {
+	team := Entry{TeamID: 4}
+	solo := Entry{UserID: 7}
+	conflicted := func(judgeID uint, entry Entry) bool { return judgeID == 100 && entry == team }
+
+	// the team already has judge 101, and judge 100 can't review it
+	planned, short := Plan([]Entry{team, solo}, []uint{100, 101, 102}, 2, map[Entry][]uint{team: {101}}, conflicted)
+	require.Empty(t, short)
+	require.Equal(t, []uint{102}, planned[team])
+	require.Equal(t, []uint{100, 101}, planned[solo])
+}
+
+func TestPlanReportsShortEntries(t *testing.T) {
+	entry := Entry{UserID: 7}
+	planned, short := Plan([]Entry{entry}, []uint{100, 101}, 3, nil, noConflicts)
+	require.Equal(t, []uint{100, 101}, planned[entry])
+	require.Equal(t, []Entry{entry}, short)
+}
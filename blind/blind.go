@@ -0,0 +1,48 @@
+package blind
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"win-a-thon/assignment"
+	"win-a-thon/config"
+)
+
+// Secret is the key the entry IDs of the application are derived with
+var Secret string
+
+// GetSecret reads the key entry IDs are derived with from the application settings
+func GetSecret() (string, error) {
+	appConfig := config.GetConfig()
+	if _, err := toml.DecodeFile("config/env.default.toml", &appConfig); err != nil {
+		return "", err
+	}
+
+	secret := appConfig.Application.BlindEntrySecret
+	if secret == "" {
+		return "", errors.New("blind_entry_secret is not set")
+	}
+	return secret, nil
+}
+
+// EntryID is the opaque ID an entry goes by while a hackathon is judged blind. It
+// is the same every time for an entry, yet can't be traced back to the participant
+// or team without the secret.
+func EntryID(secret string, hackathonID uint, entry assignment.Entry) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d:%d:%d", hackathonID, entry.UserID, entry.TeamID)
+	return hex.EncodeToString(mac.Sum(nil)[:12])
+}
+
+// Find looks up the entry with an opaque ID among the entries of a hackathon
+func Find(secret string, hackathonID uint, id string, entries []assignment.Entry) (assignment.Entry, bool) {
+	for _, entry := range entries {
+		if hmac.Equal([]byte(EntryID(secret, hackathonID, entry)), []byte(id)) {
+			return entry, true
+		}
+	}
+	return assignment.Entry{}, false
+}
@@ -0,0 +1,30 @@
+package blind
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"win-a-thon/assignment"
+)
+
+func TestEntryIDIsStableAndDistinct(t *testing.T) {
+	solo := assignment.Entry{UserID: 4}
+	team := assignment.Entry{TeamID: 4}
+
+	id := EntryID("secret", 1, solo)
+	require.Len(t, id, 24)
+	require.Equal(t, id, EntryID("secret", 1, solo))
+	require.NotEqual(t, id, EntryID("secret", 1, team))
+	require.NotEqual(t, id, EntryID("secret", 2, solo))
+	require.NotEqual(t, id, EntryID("other", 1, solo))
+}
+
+func TestFind(t *testing.T) {
+	entries := []assignment.Entry{{UserID: 4}, {TeamID: 4}, {UserID: 9}}
+
+	entry, ok := Find("secret", 1, EntryID("secret", 1, entries[1]), entries)
+	require.True(t, ok)
+	require.Equal(t, entries[1], entry)
+
+	_, ok = Find("secret", 1, EntryID("secret", 2, entries[1]), entries)
+	require.False(t, ok)
+}
@@ -0,0 +1,65 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"io"
+	"regexp"
+	"strings"
+	"win-a-thon/config"
+)
+
+// Default is where uploaded artifacts are kept, set up from the configuration on start
+var Default Store
+
+// Reasons a store refuses a blob
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("blob keys are slash separated names of letters, digits, dots, dashes and underscores")
+)
+
+// Store keeps blobs by key. Keys are slash separated paths like
+// hackathons/1/team-2/4f1c, and putting a blob under a key that is taken replaces it.
+type Store interface {
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	// Get opens the blob under key, or fails with ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob under key, if there is one
+	Delete(ctx context.Context, key string) error
+}
+
+var segment = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// ValidKey reports whether key can name a blob, which it can't when it is empty,
+// too long or could climb out of the store with a .. segment
+func ValidKey(key string) bool {
+	if key == "" || len(key) > 512 {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "." || part == ".." || !segment.MatchString(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetStore opens the store the artifacts settings ask for, the local filesystem
+// or an S3 compatible object store
+func GetStore() (Store, error) {
+	appConfig := config.GetConfig()
+	if _, err := toml.DecodeFile("config/env.default.toml", &appConfig); err != nil {
+		return nil, err
+	}
+
+	settings := appConfig.Artifacts
+	switch settings.Store {
+	case "", "local":
+		return NewLocal(settings.LocalDir)
+	case "s3":
+		return NewS3(settings.S3Endpoint, settings.S3Region, settings.S3Bucket, settings.S3AccessKey, settings.S3SecretKey)
+	}
+	return nil, fmt.Errorf("unknown artifact store %q", settings.Store)
+}
@@ -0,0 +1,60 @@
+package blobstore
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"hackathons/1/team-2/4f1c": true,
+		"deck.pdf":                 true,
+		"":                         false,
+		"../etc/passwd":            false,
+		"hackathons/../secret":     false,
+		"/absolute":                false,
+		"double//slash":            false,
+		"with space":               false,
+		strings.Repeat("a", 513):   false,
+	} {
+		require.Equal(t, want, ValidKey(key), key)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(ctx, "hackathons/1/user-7/deck", strings.NewReader("slides"), 6, "application/pdf"))
+	content, err := store.Get(ctx, "hackathons/1/user-7/deck")
+	require.NoError(t, err)
+	read, err := ioutil.ReadAll(content)
+	content.Close()
+	require.NoError(t, err)
+	require.Equal(t, "slides", string(read))
+
+	require.NoError(t, store.Delete(ctx, "hackathons/1/user-7/deck"))
+	_, err = store.Get(ctx, "hackathons/1/user-7/deck")
+	require.Equal(t, ErrNotFound, err)
+	require.NoError(t, store.Delete(ctx, "hackathons/1/user-7/deck"))
+
+	require.Equal(t, ErrInvalidKey, store.Put(ctx, "../outside", strings.NewReader("x"), 1, ""))
+}
+
+func TestSignedLinks(t *testing.T) {
+	now := time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC)
+	expires := now.Add(15 * time.Minute)
+	signature := Sign("secret", "/hackathons/1/artifacts/5/download", expires)
+
+	require.True(t, Verify("secret", "/hackathons/1/artifacts/5/download", expires.Unix(), signature, now))
+	// expired, for another file, stretched or signed with another secret
+	require.False(t, Verify("secret", "/hackathons/1/artifacts/5/download", expires.Unix(), signature, expires.Add(time.Second)))
+	require.False(t, Verify("secret", "/hackathons/1/artifacts/6/download", expires.Unix(), signature, now))
+	require.False(t, Verify("secret", "/hackathons/1/artifacts/5/download", expires.Add(time.Hour).Unix(), signature, now))
+	require.False(t, Verify("other", "/hackathons/1/artifacts/5/download", expires.Unix(), signature, now))
+}
@@ -0,0 +1,81 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Local keeps blobs as files under a directory of the local filesystem
+type Local struct {
+	root string
+}
+
+// NewLocal returns a store keeping blobs under root, which is created if need be
+func NewLocal(root string) (*Local, error) {
+	if root == "" {
+		return nil, errors.New("the local artifact store needs a directory")
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, err
+	}
+	return &Local{root: root}, nil
+}
+
+func (local *Local) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(local.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes the blob to a temporary file first, so that a failed upload never
+// leaves half a blob under key
+func (local *Local) Put(_ context.Context, key string, content io.Reader, _ int64, _ string) error {
+	path, err := local.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (local *Local) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := local.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (local *Local) Delete(_ context.Context, key string) error {
+	path, err := local.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package blobstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Sign returns the signature of a download link to path that is good until expires,
+// so that whoever holds the link can download the blob without logging in
+func Sign(secret string, path string, expires time.Time) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(path + "\n" + strconv.FormatInt(expires.Unix(), 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature was made by Sign for a link to path expiring at
+// the unix time expires, and the link hasn't expired by now
+func Verify(secret string, path string, expires int64, signature string, now time.Time) bool {
+	if now.Unix() > expires {
+		return false
+	}
+	expected := Sign(secret, path, time.Unix(expires, 0))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
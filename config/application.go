@@ -6,4 +6,8 @@ type application struct {
 	ListenIP   string `toml:"listen_ip"`
 	Email      string `toml:"winathon_mail"`
 	Password   string `toml:"winathon_password"`
+	// the reverse proxies in front of the application, whose X-Forwarded-For is believed
+	TrustedProxies []string `toml:"trusted_proxies"`
+	// the key the opaque IDs of entries judged blind are derived with
+	BlindEntrySecret string `toml:"blind_entry_secret"`
 }
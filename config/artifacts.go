@@ -0,0 +1,13 @@
+package config
+
+// Artifacts : struct to hold where uploaded submission files are kept and how they are scanned
+type Artifacts struct {
+	Store       string `toml:"store"`
+	LocalDir    string `toml:"local_dir"`
+	S3Endpoint  string `toml:"s3_endpoint"`
+	S3Region    string `toml:"s3_region"`
+	S3Bucket    string `toml:"s3_bucket"`
+	S3AccessKey string `toml:"s3_access_key"`
+	S3SecretKey string `toml:"s3_secret_key"`
+	ScanCommand string `toml:"scan_command"`
+}
@@ -8,6 +8,9 @@ var (
 type appConfig struct {
 	Application application `toml:"application"`
 	Database    Database    `toml:"database"`
+	Retention   Retention   `toml:"retention"`
+	LinkCheck   LinkCheck   `toml:"link_check"`
+	Artifacts   Artifacts   `toml:"artifacts"`
 }
 
 func GetConfig() appConfig {
@@ -0,0 +1,8 @@
+package config
+
+// LinkCheck : struct to hold how often and how patiently submitted links are probed
+type LinkCheck struct {
+	Enabled  bool   `toml:"enabled"`
+	Interval string `toml:"interval"`
+	Timeout  string `toml:"timeout"`
+}
@@ -0,0 +1,7 @@
+package config
+
+// Retention : struct to hold how long soft-deleted records are kept before being purged
+type Retention struct {
+	PurgeAfterDays int    `toml:"purge_after_days"`
+	Interval       string `toml:"interval"`
+}
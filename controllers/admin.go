@@ -1,8 +1,15 @@
 package controllers
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
 	"win-a-thon/repo"
 	"win-a-thon/token"
 	"win-a-thon/utils"
@@ -37,31 +44,233 @@ func GetAdminApproval(c *gin.Context) {
 	}
 
 	if user.IsAdmin == true {
-		err := repo.AdminAppoved(hackathonid, value)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"message": "Hackathon doesn't exist",
+		if value != utils.ApprovalApprove && value != utils.ApprovalReject {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "value should be 1 to approve or 0 to reject",
 			})
 			return
-		} else if value == "0" {
-			err := repo.DeleteHackathonByAdmin(hackathonid)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, err)
+		}
+
+		if value == utils.ApprovalReject {
+			var req utils.RejectHackathonRequest
+			if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Reason) == "" {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"message": "A rejection reason is required",
+				})
+				return
+			}
+			reason := strings.TrimSpace(req.Reason)
+			if utf8.RuneCountInString(reason) > utils.MaxReasonLength {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"message": fmt.Sprintf("The rejection reason can't be longer than %d characters", utils.MaxReasonLength),
+				})
+				return
 			}
-			utils.Notify(organiser.Email, "Approval", "Unfortunately your hackathon has been disapproved.")
+
+			err := repo.RejectHackathon(hackathonid, user.ID, reason)
+			if err == lifecycle.ErrInvalidTransition {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"message": "Hackathon is not awaiting approval",
+				})
+				return
+			} else if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"message": "Hackathon doesn't exist",
+				})
+				return
+			}
+			unindexHackathon(hackathon.ID)
+			utils.Notify(organiser.Email, "Approval", "Unfortunately your hackathon has been disapproved. Reason: "+reason+
+				"\r\nYou can revise it and request approval again.")
 			c.JSON(http.StatusOK, gin.H{
-				"message": "Hackathon disapproved and deleted from database",
+				"message": "Hackathon rejected",
+				"reason":  reason,
 			})
 			return
-		} else {
-			utils.Notify(organiser.Email, "Approval", "Yay! your hackathon has been approved.")
-			c.JSON(http.StatusOK, gin.H{
-				"message": "Hackathon approved",
+		}
+
+		err := repo.AdminAppoved(hackathonid, user.ID)
+		if err == lifecycle.ErrInvalidTransition {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Hackathon is not awaiting approval",
+			})
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Hackathon doesn't exist",
 			})
+			return
 		}
+		hackathon.AdminApproved = true
+		hackathon.Status = lifecycle.StatusApproved
+		indexHackathon(hackathon)
+		utils.Notify(organiser.Email, "Approval", "Yay! your hackathon has been approved.")
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Hackathon approved",
+		})
 	} else {
 		c.JSON(http.StatusOK, gin.H{
 			"message": "Sorry, you don't have the right to organise a hackathon. Please buy our premium version to unlock all features.",
 		})
 	}
 }
+
+// authorisedAdmin loads the logged in user and aborts the request unless they are an admin.
+func authorisedAdmin(c *gin.Context) (models.User, bool) {
+	authPayload := c.MustGet(utils.AuthorizationPayloadKey).(*token.Payload)
+	user, err := repo.GetProfileByUsername(authPayload.Username)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return user, false
+	}
+	if !user.IsAdmin {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "this API is for admins only"})
+		return user, false
+	}
+	return user, true
+}
+
+func ListDeletedUsers(c *gin.Context) {
+	if _, ok := authorisedAdmin(c); !ok {
+		return
+	}
+
+	var users []models.User
+	if err := repo.ListDeletedUsers(&users); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "service unavailable"})
+		return
+	}
+
+	type deletedUser struct {
+		ID        uint      `json:"id"`
+		Username  string    `json:"username"`
+		FullName  string    `json:"full_name"`
+		Email     string    `json:"email"`
+		DeletedAt time.Time `json:"deleted_at"`
+	}
+	deletedUsers := make([]deletedUser, 0)
+
+	for _, val := range users {
+		deletedUsers = append(deletedUsers, deletedUser{
+			val.ID,
+			val.Username,
+			val.FullName,
+			val.Email,
+			val.DeletedAt.Time,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "successful",
+		"users":  deletedUsers,
+	})
+}
+
+func ListDeletedHackathons(c *gin.Context) {
+	if _, ok := authorisedAdmin(c); !ok {
+		return
+	}
+
+	var hackathons []models.Hackathon
+	if err := repo.ListDeletedHackathons(&hackathons); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "service unavailable"})
+		return
+	}
+
+	type deletedHackathon struct {
+		ID               uint      `json:"id"`
+		Title            string    `json:"title"`
+		OrganisationName string    `json:"organisation_name"`
+		OrganiserID      int       `json:"organiser_id"`
+		DeletedAt        time.Time `json:"deleted_at"`
+	}
+	deletedHackathons := make([]deletedHackathon, 0)
+
+	for _, val := range hackathons {
+		deletedHackathons = append(deletedHackathons, deletedHackathon{
+			val.ID,
+			val.Title,
+			val.OrganisationName,
+			val.OrganiserID,
+			val.DeletedAt.Time,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":     "successful",
+		"hackathons": deletedHackathons,
+	})
+}
+
+func RestoreUser(c *gin.Context) {
+	if _, ok := authorisedAdmin(c); !ok {
+		return
+	}
+
+	err := repo.RestoreUser(c.Params.ByName("user_id"))
+	if err == gorm.ErrRecordNotFound {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "no deleted user with this id"})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "service unavailable"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user restored successfully"})
+}
+
+func RestoreHackathon(c *gin.Context) {
+	if _, ok := authorisedAdmin(c); !ok {
+		return
+	}
+
+	hackathonID := c.Params.ByName("hackathon_id")
+	err := repo.RestoreHackathon(hackathonID)
+	if err == gorm.ErrRecordNotFound {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "no deleted hackathon with this id"})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "service unavailable"})
+		return
+	}
+
+	if hackathon, err := repo.HackathonFromHackathonID(hackathonID); err == nil {
+		indexHackathon(hackathon)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "hackathon restored successfully"})
+}
+
+func PurgeUser(c *gin.Context) {
+	if _, ok := authorisedAdmin(c); !ok {
+		return
+	}
+
+	err := repo.PurgeUser(c.Params.ByName("user_id"))
+	if err == gorm.ErrRecordNotFound {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "no deleted user with this id"})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "service unavailable"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user purged permanently"})
+}
+
+func PurgeHackathon(c *gin.Context) {
+	if _, ok := authorisedAdmin(c); !ok {
+		return
+	}
+
+	err := repo.PurgeHackathon(c.Params.ByName("hackathon_id"))
+	if err == gorm.ErrRecordNotFound {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "no deleted hackathon with this id"})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "service unavailable"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "hackathon purged permanently"})
+}
@@ -10,11 +10,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 	"win-a-thon/database"
 	"win-a-thon/models"
 	"win-a-thon/token"
+	"win-a-thon/utils"
 )
 
 func TestGetAdminApprovalSuccessForTrue(t *testing.T) {
@@ -95,7 +97,8 @@ func TestGetAdminApprovalSuccessForTrue(t *testing.T) {
 		"SELECT * FROM `hackathons` WHERE id = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).WithArgs("1").WillReturnRows(hackathonMockRow3)
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE `hackathons` SET `created_at`=?,`updated_at`=?,`deleted_at`=?,`title`=?,`starting_time`=?,`ending_time`=?,`result_time`=?,`organisation_name`=?,`organiser_id`=?,`description`=?,`admin_approved`=? WHERE `id` = ?")).WithArgs().WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `hackathons` SET `admin_approved`=?,`status`=?,`updated_at`=? WHERE (id = ? AND status = ?) AND `hackathons`.`deleted_at` IS NULL")).WithArgs(true, "approved", AnyTime{}, 1, "pending_approval").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `hackathon_transitions` (`hackathon_id`,`from_status`,`to_status`,`actor_id`,`reason`,`created_at`) VALUES (?,?,?,?,?,?)")).WithArgs(1, "pending_approval", "approved", 0, "approved by admin", AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
 	GetAdminApproval(ctx)
@@ -105,6 +108,7 @@ func TestGetAdminApprovalSuccessForTrue(t *testing.T) {
 func TestGetAdminApprovalSuccessForFalse(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("PATCH", "/hackathons/1/approve/0", strings.NewReader(`{"reason":"Timeline is unclear"}`))
 
 	ctx.Keys = make(map[string]interface{})
 	ctx.Keys["authorization_payload"] = &token.Payload{
@@ -179,15 +183,17 @@ func TestGetAdminApprovalSuccessForFalse(t *testing.T) {
 		"SELECT * FROM `hackathons` WHERE id = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).WithArgs("1").WillReturnRows(hackathonMockRow3)
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE `hackathons` SET `created_at`=?,`updated_at`=?,`deleted_at`=?,`title`=?,`starting_time`=?,`ending_time`=?,`result_time`=?,`organisation_name`=?,`organiser_id`=?,`description`=?,`admin_approved`=? WHERE `id` = ?")).WithArgs().WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectCommit()
-
-	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE `hackathons` SET `deleted_at`=? WHERE id = ? AND `hackathons`.`deleted_at` IS NULL")).WithArgs(AnyTime{}, "1").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `hackathons` SET `admin_approved`=?,`status`=?,`updated_at`=? WHERE (id = ? AND status = ?) AND `hackathons`.`deleted_at` IS NULL")).WithArgs(false, "rejected", AnyTime{}, 1, "pending_approval").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `hackathon_transitions` (`hackathon_id`,`from_status`,`to_status`,`actor_id`,`reason`,`created_at`) VALUES (?,?,?,?,?,?)")).WithArgs(1, "pending_approval", "rejected", 0, "Timeline is unclear", AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
 	GetAdminApproval(ctx)
 	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"message":"Hackathon rejected","reason":"Timeline is unclear"}`, w.Body.String())
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
 }
 
 func TestGetAdminApprovalIsAdminFalse(t *testing.T) {
@@ -267,7 +273,7 @@ func TestGetAdminApprovalIsAdminFalse(t *testing.T) {
 		"SELECT * FROM `hackathons` WHERE id = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).WithArgs("1").WillReturnRows(hackathonMockRow3)
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE `hackathons` SET `created_at`=?,`updated_at`=?,`deleted_at`=?,`title`=?,`starting_time`=?,`ending_time`=?,`result_time`=?,`organisation_name`=?,`organiser_id`=?,`description`=?,`admin_approved`=? WHERE `id` = ?")).WithArgs().WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `hackathons` SET `created_at`=?,`updated_at`=?,`deleted_at`=?,`title`=?,`starting_time`=?,`ending_time`=?,`result_time`=?,`organisation_name`=?,`organiser_id`=?,`description`=?,`admin_approved`=?,`status`=?,`cancellation_reason`=?,`max_participants`=?,`min_team_size`=?,`max_team_size`=?,`registration_opens_at`=?,`registration_closes_at`=?,`score_aggregation`=?,`score_normalization`=?,`reviews_per_submission`=?,`blind_judging`=?,`tie_breakers`=?,`voting_closes_at`=?,`votes_per_user`=?,`submissions_frozen_at`=? WHERE `id` = ?")).WithArgs().WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
 	GetAdminApproval(ctx)
@@ -563,3 +569,228 @@ func TestGetAdminApprovalSuccessForError(t *testing.T) {
 	GetAdminApproval(ctx)
 	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestListDeletedHackathonsNotAdmin(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "name",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	userRows := sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "username", "full_name", "hashed_password", "email", "linked_in", "git_hub", "web_link", "organisation", "is_admin"}).
+		AddRow(time.Now(), time.Now(), nil, "name", "fullname", "password", "email", "abc", "abc", "abc", "abc", false)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(userRows)
+
+	ListDeletedHackathons(ctx)
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `{"message":"this API is for admins only"}`, w.Body.String())
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestListDeletedHackathons(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "admin",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	userRows := sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "username", "full_name", "hashed_password", "email", "linked_in", "git_hub", "web_link", "organisation", "is_admin"}).
+		AddRow(time.Now(), time.Now(), nil, "admin", "fullname", "password", "email", "abc", "abc", "abc", "abc", true)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("admin").
+		WillReturnRows(userRows)
+
+	date := time.Date(2021, 8, 2, 0, 0, 0, 0, time.UTC)
+	hackathonMockRow := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "title", "organisation_name", "organiser_id"}).
+		AddRow(1, date, date, date, "Test Hackathon", "Winathon", 2)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE deleted_at IS NOT NULL ORDER BY deleted_at desc")).
+		WillReturnRows(hackathonMockRow)
+
+	ListDeletedHackathons(ctx)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+
+	expected := `{"hackathons":[{"id":1,"title":"Test Hackathon","organisation_name":"Winathon","organiser_id":2,"deleted_at":"2021-08-02T00:00:00Z"}],"status":"successful"}`
+	assert.Equal(t, expected, w.Body.String())
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestRestoreUserNotFound(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "admin",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	ctx.Params = []gin.Param{
+		{
+			Key:   "user_id",
+			Value: "7",
+		},
+	}
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	userRows := sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "username", "full_name", "hashed_password", "email", "linked_in", "git_hub", "web_link", "organisation", "is_admin"}).
+		AddRow(time.Now(), time.Now(), nil, "admin", "fullname", "password", "email", "abc", "abc", "abc", "abc", true)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("admin").
+		WillReturnRows(userRows)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `users` SET `deleted_at`=?,`updated_at`=? WHERE id = ? AND deleted_at IS NOT NULL")).
+		WithArgs(nil, AnyTime{}, "7").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	RestoreUser(ctx)
+	assert.EqualValues(t, http.StatusNotFound, w.Code)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestGetAdminApprovalInvalidValue(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "admin",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	ctx.Params = []gin.Param{{Key: "hackathon_id", Value: "1"}, {Key: "value", Value: "yes"}}
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("admin").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "is_admin"}).AddRow(5, "admin", true))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "organiser_id", "status"}).AddRow(1, 2, "pending_approval"))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE ID = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email"}).AddRow(2, "organiser", "organiser@winathon.dev"))
+
+	GetAdminApproval(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"value should be 1 to approve or 0 to reject"}`, w.Body.String())
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestGetAdminApprovalRejectionReasonTooLong(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("PATCH", "/hackathons/1/approve/0", strings.NewReader(`{"reason":"`+strings.Repeat("é", utils.MaxReasonLength+1)+`"}`))
+
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "admin",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	ctx.Params = []gin.Param{{Key: "hackathon_id", Value: "1"}, {Key: "value", Value: "0"}}
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("admin").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "is_admin"}).AddRow(5, "admin", true))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "organiser_id", "status"}).AddRow(1, 2, "pending_approval"))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE ID = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email"}).AddRow(2, "organiser", "organiser@winathon.dev"))
+
+	GetAdminApproval(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"The rejection reason can't be longer than 500 characters"}`, w.Body.String())
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
@@ -0,0 +1,387 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+	"win-a-thon/assignment"
+	"win-a-thon/blobstore"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+	"win-a-thon/scan"
+	"win-a-thon/token"
+	"win-a-thon/utils"
+)
+
+// upload is a file uploaded with a submission that passed the size, type and
+// virus checks
+type upload struct {
+	file        *multipart.FileHeader
+	name        string
+	contentType string
+}
+
+// isMultipart reports whether a submission comes as a form with files rather than JSON
+func isMultipart(c *gin.Context) bool {
+	return c.ContentType() == "multipart/form-data"
+}
+
+// receiveArtifacts reads the files of a multipart submission and checks each of
+// them, answering the request and returning false when one doesn't pass
+func receiveArtifacts(c *gin.Context) ([]upload, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, utils.MaxArtifactsPerUpload*utils.MaxArtifactSize+1<<20)
+	form, err := c.MultipartForm()
+	if err != nil && strings.Contains(err.Error(), "request body too large") {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"message": fmt.Sprintf("Submissions can upload up to %d files of %d MB each", utils.MaxArtifactsPerUpload, utils.MaxArtifactSize>>20)})
+		return nil, false
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "The submitted form couldn't be read"})
+		return nil, false
+	}
+
+	files := form.File["artifacts"]
+	if len(files) > utils.MaxArtifactsPerUpload {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("Submissions can upload up to %d files at a time", utils.MaxArtifactsPerUpload)})
+		return nil, false
+	}
+	uploads := make([]upload, 0, len(files))
+	for _, file := range files {
+		name := artifactName(file.Filename)
+		if file.Size > utils.MaxArtifactSize {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"message": fmt.Sprintf("%s is larger than %d MB", name, utils.MaxArtifactSize>>20)})
+			return nil, false
+		}
+
+		// the type is sniffed from the content, what the browser claims is ignored
+		contentType, err := sniff(file)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return nil, false
+		}
+		if !utils.ArtifactTypes[contentType] {
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"message": fmt.Sprintf("%s isn't a PDF, zip archive, image or video", name)})
+			return nil, false
+		}
+
+		err = scan.File(c.Request.Context(), name, func() (io.ReadCloser, error) { return file.Open() })
+		if errors.Is(err, scan.ErrInfected) {
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"message": fmt.Sprintf("%s didn't pass the virus scan", name)})
+			return nil, false
+		} else if err != nil {
+			fmt.Println("artifact scan failed: ", err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"message": "Uploads can't be scanned right now, try again later"})
+			return nil, false
+		}
+		uploads = append(uploads, upload{file: file, name: name, contentType: contentType})
+	}
+	return uploads, true
+}
+
+func sniff(file *multipart.FileHeader) (string, error) {
+	content, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer content.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// artifactName is the name of an uploaded file without its directories, or
+// anything that can't be shown in a download
+func artifactName(filename string) string {
+	name := path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, name)
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "" || name == "." || name == "/" {
+		return "artifact"
+	}
+	return name
+}
+
+// artifactKey is a fresh blob key for a file uploaded by an entry
+func artifactKey(hackathonID uint, entry assignment.Entry) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	owner := fmt.Sprintf("user-%d", entry.UserID)
+	if entry.TeamID != 0 {
+		owner = fmt.Sprintf("team-%d", entry.TeamID)
+	}
+	return fmt.Sprintf("hackathons/%d/%s/%s", hackathonID, owner, hex.EncodeToString(random)), nil
+}
+
+// storeArtifacts puts the files uploaded by an entry in the blob store and records
+// them, answering the request and returning false when they can't all be kept
+func storeArtifacts(c *gin.Context, hackathonID uint, entry assignment.Entry, uploaderID uint, uploads []upload) ([]models.Artifact, bool) {
+	ctx := c.Request.Context()
+	artifacts := make([]models.Artifact, 0, len(uploads))
+	discard := func() {
+		discardBlobs(c, artifacts)
+	}
+
+	for _, upload := range uploads {
+		key, err := artifactKey(hackathonID, entry)
+		if err != nil {
+			discard()
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return nil, false
+		}
+		content, err := upload.file.Open()
+		if err != nil {
+			discard()
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return nil, false
+		}
+		checksum := sha256.New()
+		err = blobstore.Default.Put(ctx, key, io.TeeReader(content, checksum), upload.file.Size, upload.contentType)
+		content.Close()
+		if err != nil {
+			fmt.Println("storing artifact failed: ", err)
+			discard()
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return nil, false
+		}
+		artifacts = append(artifacts, models.Artifact{
+			HackathonID: hackathonID,
+			UserID:      entry.UserID,
+			TeamID:      entry.TeamID,
+			UploaderID:  uploaderID,
+			Name:        upload.name,
+			ContentType: upload.contentType,
+			Size:        upload.file.Size,
+			Checksum:    hex.EncodeToString(checksum.Sum(nil)),
+			Key:         key,
+		})
+	}
+
+	if err := repo.AddArtifacts(artifacts, utils.MaxArtifactsPerEntry); err == repo.ErrTooManyArtifacts {
+		discard()
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("Entries can keep up to %d files, remove some first", utils.MaxArtifactsPerEntry)})
+		return nil, false
+	} else if err != nil {
+		discard()
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return nil, false
+	}
+	return artifacts, true
+}
+
+// discardBlobs deletes the stored files of artifacts, logging the ones that couldn't be
+func discardBlobs(c *gin.Context, artifacts []models.Artifact) {
+	for _, artifact := range artifacts {
+		if err := blobstore.Default.Delete(c.Request.Context(), artifact.Key); err != nil {
+			fmt.Println("discarding artifact failed: ", err)
+		}
+	}
+}
+
+// discardArtifacts removes files stored by storeArtifacts, records and blobs, when
+// the submission they came with fails afterwards
+func discardArtifacts(c *gin.Context, artifacts []models.Artifact) {
+	if err := repo.DeleteArtifacts(artifacts); err != nil {
+		// the blobs are kept for the records left pointing at them
+		fmt.Println("discarding artifacts failed: ", err)
+		return
+	}
+	discardBlobs(c, artifacts)
+}
+
+// describeArtifacts lists files for whoever may download them, each with a signed
+// link that works for a while without logging in
+func describeArtifacts(artifacts []models.Artifact, now time.Time) []gin.H {
+	expires := now.Add(utils.ArtifactLinkTTL).Truncate(time.Second)
+	rows := make([]gin.H, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		download := fmt.Sprintf("/hackathons/%d/artifacts/%d/download", artifact.HackathonID, artifact.ID)
+		signature := blobstore.Sign(utils.TokenSymmetricKey, download, expires)
+		rows = append(rows, gin.H{
+			"id":           artifact.ID,
+			"entry_id":     entryID(artifact.HackathonID, assignment.Entry{UserID: artifact.UserID, TeamID: artifact.TeamID}),
+			"name":         artifact.Name,
+			"content_type": artifact.ContentType,
+			"size":         artifact.Size,
+			"checksum":     artifact.Checksum,
+			"uploaded_at":  artifact.CreatedAt,
+			"download_url": fmt.Sprintf("%s?expires=%d&signature=%s", download, expires.Unix(), signature),
+			"expires_at":   expires,
+		})
+	}
+	return rows
+}
+
+// ListArtifacts lists the files uploaded in a hackathon with links to download
+// them. Judges and the organiser see every entry's files once the hackathon has
+// ended, participants see the files of their own entry.
+func ListArtifacts(c *gin.Context) {
+	authPayload := c.MustGet(utils.AuthorizationPayloadKey).(*token.Payload)
+	user, err := repo.GetProfileByUsername(authPayload.Username)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "User doesn't exist"})
+		return
+	}
+
+	hackathon, err := repo.HackathonFromHackathonID(c.Params.ByName("hackathon_id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "This hackathon id doesnt exist"})
+		return
+	}
+
+	judge, err := canJudge(hackathon, user)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	var artifacts []models.Artifact
+	if judge {
+		if lifecycle.Guard(hackathon, lifecycle.ActionViewSubmissions, time.Now()) != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Can't view the submission right now. Try again once the hackathon ends!!"})
+			return
+		}
+		err = repo.ListArtifacts(&artifacts, hackathon.ID)
+		// judges assigned a review queue only see the files of the entries in it
+		if err == nil && hackathon.ReviewsPerSubmission > 0 && hackathon.OrganiserID != int(user.ID) {
+			artifacts, err = assignedArtifacts(artifacts, hackathon.ID, user.ID)
+		}
+	} else {
+		var participant models.Participant
+		if err := repo.GetSubmission(&participant, int(hackathon.ID), user.ID); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Not authorised to view the artifacts!"})
+			return
+		}
+		err = repo.ListEntryArtifacts(&artifacts, hackathon.ID, repo.EntryOf(participant))
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"hackathon_id": hackathon.ID, "artifacts": describeArtifacts(artifacts, time.Now())})
+}
+
+// assignedArtifacts keeps the files of the entries in the review queue of a judge
+func assignedArtifacts(artifacts []models.Artifact, hackathonID uint, judgeID uint) ([]models.Artifact, error) {
+	var participants []models.Participant
+	if err := repo.AssignedSubmissions(&participants, hackathonID, judgeID); err != nil {
+		return nil, err
+	}
+	assigned := make(map[assignment.Entry]bool)
+	for _, participant := range participants {
+		assigned[repo.EntryOf(participant)] = true
+	}
+	kept := make([]models.Artifact, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		if assigned[assignment.Entry{UserID: artifact.UserID, TeamID: artifact.TeamID}] {
+			kept = append(kept, artifact)
+		}
+	}
+	return kept, nil
+}
+
+// DownloadArtifact sends a file to whoever holds a signed link to it that hasn't
+// expired yet
+func DownloadArtifact(c *gin.Context) {
+	hackathonID, artifactID := c.Params.ByName("hackathon_id"), c.Params.ByName("artifact_id")
+	download := fmt.Sprintf("/hackathons/%s/artifacts/%s/download", hackathonID, artifactID)
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil || !blobstore.Verify(utils.TokenSymmetricKey, download, expires, c.Query("signature"), time.Now()) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "This download link is invalid or has expired"})
+		return
+	}
+
+	hid, err := strconv.ParseUint(hackathonID, 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "This artifact doesn't exist"})
+		return
+	}
+	artifact, err := repo.ArtifactFromID(artifactID, uint(hid))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "This artifact doesn't exist"})
+		return
+	}
+	content, err := blobstore.Default.Get(c.Request.Context(), artifact.Key)
+	if err == blobstore.ErrNotFound {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "This artifact doesn't exist"})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, artifact.Size, artifact.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": artifact.Name}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, no-store",
+	})
+}
+
+// DeleteArtifact lets a participant remove a file their entry uploaded, until the
+// hackathon ends
+func DeleteArtifact(c *gin.Context) {
+	authPayload := c.MustGet(utils.AuthorizationPayloadKey).(*token.Payload)
+	user, err := repo.GetProfileByUsername(authPayload.Username)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "User doesn't exist"})
+		return
+	}
+
+	hackathon, err := repo.HackathonFromHackathonID(c.Params.ByName("hackathon_id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "This hackathon id doesnt exist"})
+		return
+	}
+	var participant models.Participant
+	if err := repo.GetSubmission(&participant, int(hackathon.ID), user.ID); err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "user has not participated"})
+		return
+	}
+	if lifecycle.Guard(hackathon, lifecycle.ActionSubmit, time.Now()) != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Files can't be removed once the hackathon has ended"})
+		return
+	}
+
+	artifact, err := repo.ArtifactFromID(c.Params.ByName("artifact_id"), hackathon.ID)
+	entry := repo.EntryOf(participant)
+	if err != nil || artifact.UserID != entry.UserID || artifact.TeamID != entry.TeamID {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "This artifact doesn't exist"})
+		return
+	}
+	if err := repo.DeleteArtifact(&artifact); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	if err := blobstore.Default.Delete(c.Request.Context(), artifact.Key); err != nil {
+		fmt.Println("deleting artifact failed: ", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "removed successfully"})
+}
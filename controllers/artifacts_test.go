@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"win-a-thon/blobstore"
+	"win-a-thon/database"
+	"win-a-thon/token"
+	"win-a-thon/utils"
+)
+
+// submitterRouter routes a submission by tim, a participant of the running
+// hackathon 1, and expects them and the hackathon to be loaded
+func submitterRouter(t *testing.T) (*gin.Engine, *httptest.ResponseRecorder, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(w)
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("tim").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(0, "tim"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).
+		WithArgs("1", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id"}).AddRow(1, 0))
+	started, ends := time.Now().Add(-time.Hour), time.Now().Add(24*time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `hackathons` WHERE (id = ? AND admin_approved = ?)")).
+		WithArgs("1", true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "starting_time", "ending_time", "result_time", "organiser_id", "admin_approved"}).
+			AddRow(1, started, ends, ends, 1, true))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `participants` WHERE hackathon_id = ?")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
+	r.Use(func(c *gin.Context) {
+		c.Set(utils.AuthorizationPayloadKey, &token.Payload{Username: "tim", IssuedAt: time.Now(), ExpiredAt: time.Now().Add(time.Hour)})
+	})
+	r.PATCH("/:hackathon_id/submit", UpdateSubmission)
+	return r, w, mock
+}
+
+// submissionForm is a multipart submission of a code link along with files
+func submissionForm(t *testing.T, files map[string]string) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	require.NoError(t, form.WriteField("code_url", "https://github.com/tim/app"))
+	for name, content := range files {
+		part, err := form.CreateFormFile("artifacts", name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, form.Close())
+	return &body, form.FormDataContentType()
+}
+
+func TestUpdateSubmissionWithArtifact(t *testing.T) {
+	store, err := blobstore.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	blobstore.Default = store
+	r, w, mock := submitterRouter(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ? AND user_id = ? AND `participants`.`deleted_at` IS NULL AND `participants`.`hackathon_id` = ? ORDER BY `participants`.`hackathon_id` LIMIT 1")).
+		WithArgs(1, 0, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id"}).AddRow(1, 0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `hackathons` WHERE id = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1 FOR UPDATE")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `artifacts` WHERE hackathon_id = ? AND user_id = ? AND team_id = ?")).
+		WithArgs(1, 0, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `artifacts` (`hackathon_id`,`user_id`,`team_id`,`uploader_id`,`name`,`content_type`,`size`,`checksum`,`key`,`created_at`) VALUES (?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs(1, 0, 0, 0, "deck.pdf", "application/pdf", 16, sqlmock.AnyArg(), sqlmock.AnyArg(), AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `participants`")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `submission_revisions`")).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	body, contentType := submissionForm(t, map[string]string{"../../deck.pdf": "%PDF-1.4 slides\n"})
+	req, _ := http.NewRequest("PATCH", "/1/submit", body)
+	req.Header.Set("Content-Type", contentType)
+	r.ServeHTTP(w, req)
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"deck.pdf"`)
+	assert.Contains(t, w.Body.String(), `"download_url":"/hackathons/1/artifacts/5/download?expires=`)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestUpdateSubmissionFailedDiscardsArtifacts(t *testing.T) {
+	root := t.TempDir()
+	store, err := blobstore.NewLocal(root)
+	require.NoError(t, err)
+	blobstore.Default = store
+	r, w, mock := submitterRouter(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ? AND user_id = ? AND `participants`.`deleted_at` IS NULL AND `participants`.`hackathon_id` = ? ORDER BY `participants`.`hackathon_id` LIMIT 1")).
+		WithArgs(1, 0, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id"}).AddRow(1, 0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `hackathons` WHERE id = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1 FOR UPDATE")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `artifacts` WHERE hackathon_id = ? AND user_id = ? AND team_id = ?")).
+		WithArgs(1, 0, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `artifacts`")).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `participants`")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `submission_revisions`")).WillReturnError(errors.New("connection lost"))
+	// the submission isn't saved without its revision, and the file is taken back
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `artifacts` WHERE `artifacts`.`id` = ?")).
+		WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	body, contentType := submissionForm(t, map[string]string{"deck.pdf": "%PDF-1.4 slides\n"})
+	req, _ := http.NewRequest("PATCH", "/1/submit", body)
+	req.Header.Set("Content-Type", contentType)
+	r.ServeHTTP(w, req)
+
+	assert.EqualValues(t, http.StatusInternalServerError, w.Code)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			t.Errorf("%s was left in the store", path)
+		}
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func TestUpdateSubmissionArtifactType(t *testing.T) {
+	r, w, mock := submitterRouter(t)
+
+	body, contentType := submissionForm(t, map[string]string{"deck.pdf": "#!/bin/sh\nrm -rf /\n"})
+	req, _ := http.NewRequest("PATCH", "/1/submit", body)
+	req.Header.Set("Content-Type", contentType)
+	r.ServeHTTP(w, req)
+
+	assert.EqualValues(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, `{"message":"deck.pdf isn't a PDF, zip archive, image or video"}`, w.Body.String())
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestDownloadArtifact(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store, err := blobstore.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	blobstore.Default = store
+	require.NoError(t, store.Put(context.Background(), "hackathons/1/team-2/a", strings.NewReader("%PDF-1.4 slides\n"), 16, "application/pdf"))
+
+	driver, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	require.NoError(t, err)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `artifacts` WHERE id = ? AND hackathon_id = ? ORDER BY `artifacts`.`id` LIMIT 1")).
+		WithArgs("5", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "team_id", "name", "content_type", "size", "key"}).
+			AddRow(5, 1, 2, "pitch deck.pdf", "application/pdf", 16, "hackathons/1/team-2/a"))
+
+	r := gin.New()
+	r.GET("/hackathons/:hackathon_id/artifacts/:artifact_id/download", DownloadArtifact)
+	download := "/hackathons/1/artifacts/5/download"
+	expires := time.Now().Add(time.Minute)
+	signed := fmt.Sprintf("%s?expires=%d&signature=%s", download, expires.Unix(), blobstore.Sign(utils.TokenSymmetricKey, download, expires))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", signed, nil)
+	r.ServeHTTP(w, req)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Equal(t, "%PDF-1.4 slides\n", w.Body.String())
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="pitch deck.pdf"`, w.Header().Get("Content-Disposition"))
+
+	// the link doesn't open another file, nor work once it has expired
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", strings.Replace(signed, "/5/", "/6/", 1), nil)
+	r.ServeHTTP(w, req)
+	assert.EqualValues(t, http.StatusForbidden, w.Code)
+
+	expired := time.Now().Add(-time.Second)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("%s?expires=%d&signature=%s", download, expired.Unix(), blobstore.Sign(utils.TokenSymmetricKey, download, expired)), nil)
+	r.ServeHTTP(w, req)
+	assert.EqualValues(t, http.StatusForbidden, w.Code)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestListArtifactsAssignedJudge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	driver, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	require.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("judy").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(4, "judy"))
+	ended := time.Now().Add(-time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "starting_time", "ending_time", "result_time", "organiser_id", "admin_approved", "status", "reviews_per_submission"}).
+			AddRow(1, ended.Add(-time.Hour), ended, time.Now().Add(time.Hour), 1, true, "judging", 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `judges` WHERE hackathon_id = ? AND user_id = ?")).
+		WithArgs(1, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `artifacts` WHERE hackathon_id = ? ORDER BY id")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "user_id", "team_id", "name"}).
+			AddRow(5, 1, 2, 0, "deck.pdf").
+			AddRow(6, 1, 0, 3, "demo.mp4"))
+	// only the solo entry of user 2 is in the queue of the judge
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ?")).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "team_id"}).AddRow(1, 2, nil))
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(utils.AuthorizationPayloadKey, &token.Payload{Username: "judy", IssuedAt: time.Now(), ExpiredAt: time.Now().Add(time.Hour)})
+	})
+	r.GET("/:hackathon_id/artifacts", ListArtifacts)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/1/artifacts", nil)
+	r.ServeHTTP(w, req)
+
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"deck.pdf"`)
+	assert.NotContains(t, w.Body.String(), `"name":"demo.mp4"`)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
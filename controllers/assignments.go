@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+)
+
+// AssignJudges fills the review queues of the judges so every submission gets the
+// number of reviews the hackathon asks for. Running it again only tops up entries
+// short of reviews, like those of a judge who dropped out.
+func AssignJudges(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if lifecycle.Guard(hackathon, lifecycle.ActionViewSubmissions, now) != nil || lifecycle.Guard(hackathon, lifecycle.ActionViewResults, now) == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Judges are assigned after the hackathon ends, until results are declared"})
+		return
+	}
+	if hackathon.ReviewsPerSubmission == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Set reviews_per_submission for the hackathon first"})
+		return
+	}
+
+	made, short, err := repo.AssignJudges(hackathon)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":          "judges assigned successfully",
+		"assigned":        made,
+		"short_of_judges": len(short),
+	})
+}
+
+// ListAssignments shows the organiser the review queue of every judge and how far
+// they are through it
+func ListAssignments(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	var assignments []models.Assignment
+	var scores []models.JudgeScore
+	if repo.ListAssignments(&assignments, hackathon.ID) != nil || repo.HackathonScores(&scores, hackathon.ID) != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	type entryKey struct{ judgeID, userID, teamID uint }
+	scored := make(map[entryKey]bool)
+	for _, score := range scores {
+		scored[entryKey{score.JudgeID, score.UserID, score.TeamID}] = true
+	}
+
+	type queue struct {
+		Judge    string `json:"judge"`
+		Assigned int    `json:"assigned"`
+		Scored   int    `json:"scored"`
+	}
+	queues := make([]queue, 0)
+	index := make(map[uint]int)
+	for _, a := range assignments {
+		i, ok := index[a.JudgeID]
+		if !ok {
+			judge, err := repo.UserFromUserID(int(a.JudgeID))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+				return
+			}
+			i = len(queues)
+			index[a.JudgeID] = i
+			queues = append(queues, queue{Judge: judge.Username})
+		}
+		queues[i].Assigned++
+		if scored[entryKey{a.JudgeID, a.UserID, a.TeamID}] {
+			queues[i].Scored++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reviews_per_submission": hackathon.ReviewsPerSubmission,
+		"judges":                 queues,
+	})
+}
@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+	"win-a-thon/token"
+	"win-a-thon/utils"
+)
+
+// DeclareConflict lets a judge declare a conflict of interest with a participant,
+// which keeps them from scoring the participant's entry
+func DeclareConflict(c *gin.Context) {
+	var req utils.DeclareConflictRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "field names incorrect"})
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "A reason is required"})
+		return
+	}
+	if utf8.RuneCountInString(req.Reason) > utils.MaxReasonLength {
+		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("The reason can't be longer than %d characters", utils.MaxReasonLength)})
+		return
+	}
+
+	authPayload := c.MustGet(utils.AuthorizationPayloadKey).(*token.Payload)
+	judge, err := repo.GetProfileByUsername(authPayload.Username)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "User doesn't exist"})
+		return
+	}
+
+	hackathon, err := repo.HackathonFromHackathonID(c.Params.ByName("hackathon_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This hackathon id doesnt exist"})
+		return
+	}
+
+	isJudge, err := repo.IsJudge(hackathon.ID, judge.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	if !isJudge {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Only judges declare conflicts of interest"})
+		return
+	}
+
+	now := time.Now()
+	if lifecycle.Guard(hackathon, lifecycle.ActionEdit, now) != nil || lifecycle.Guard(hackathon, lifecycle.ActionViewResults, now) == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Conflicts can't be declared once results are declared"})
+		return
+	}
+
+	user, err := repo.GetProfileByUsername(c.Params.ByName("username"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Username doesn't exist"})
+		return
+	}
+	participant, err := repo.ParticipantFromUserID(strconv.Itoa(int(hackathon.ID)), strconv.Itoa(int(user.ID)))
+	if err != nil || participant.UserId != int(user.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "participant with username doesn't exist"})
+		return
+	}
+
+	conflict := models.Conflict{HackathonID: hackathon.ID, JudgeID: judge.ID, UserID: user.ID, Reason: req.Reason}
+	if err := repo.DeclareConflict(&conflict, hackathon, participant); err != nil {
+		if err, ok := err.(*mysql.MySQLError); ok && err.Number == utils.DuplicateRecordErrorCode {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "You already declared a conflict with " + user.Username})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	// the entry goes to another judge
+	response := gin.H{"status": "conflict declared successfully", "username": user.Username}
+	if hackathon.ReviewsPerSubmission > 0 && lifecycle.Guard(hackathon, lifecycle.ActionViewSubmissions, now) == nil {
+		made, short, err := repo.AssignJudges(hackathon)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return
+		}
+		response["reassigned"] = made
+		response["short_of_judges"] = len(short)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetConflictReport shows the organiser every judge and participant with a conflict
+// of interest, declared or from the same organisation, and whether a score the
+// judge gave the participant's entry before it was known still counts
+func GetConflictReport(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	var judges []models.User
+	var users []models.User
+	var participants []models.Participant
+	var conflicts []models.Conflict
+	var scores []models.JudgeScore
+	if repo.ListJudges(&judges, hackathon.ID) != nil ||
+		repo.ParticipantUsers(&users, hackathon.ID) != nil ||
+		repo.GetSubmissions(&participants, c.Params.ByName("hackathon_id")) != nil ||
+		repo.ListConflicts(&conflicts, hackathon.ID) != nil ||
+		repo.HackathonScores(&scores, hackathon.ID) != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	teams, err := hackathonTeams(participants, hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	byID := make(map[uint]models.User)
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	type pair struct{ judgeID, userID uint }
+	declared := make(map[pair]string)
+	for _, conflict := range conflicts {
+		declared[pair{conflict.JudgeID, conflict.UserID}] = conflict.Reason
+	}
+	type entryKey struct{ judgeID, userID, teamID uint }
+	scored := make(map[entryKey]bool)
+	for _, score := range scores {
+		scored[entryKey{score.JudgeID, score.UserID, score.TeamID}] = true
+	}
+
+	type conflictRow struct {
+		Judge       string `json:"judge"`
+		Participant string `json:"participant"`
+		Team        string `json:"team,omitempty"`
+		Source      string `json:"source"` // declared or same_organisation
+		Reason      string `json:"reason"`
+		Scored      bool   `json:"scored"`
+	}
+	report := make([]conflictRow, 0)
+	for _, judge := range judges {
+		for _, participant := range participants {
+			user := byID[uint(participant.UserId)]
+			row := conflictRow{Judge: judge.Username, Participant: user.Username}
+			if reason, ok := declared[pair{judge.ID, user.ID}]; ok {
+				row.Source, row.Reason = "declared", reason
+			} else if judge.SameOrganisation(user) {
+				row.Source, row.Reason = "same_organisation", "Both are from "+strings.TrimSpace(user.Organisation)
+			} else {
+				continue
+			}
+
+			key := entryKey{judgeID: judge.ID, userID: user.ID}
+			if participant.TeamID != nil {
+				key = entryKey{judgeID: judge.ID, teamID: *participant.TeamID}
+				row.Team = teams[*participant.TeamID].Name
+			}
+			row.Scored = scored[key]
+			report = append(report, row)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"conflicts": report})
+}
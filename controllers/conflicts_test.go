@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDeclareConflictNotJudging(t *testing.T) {
+	ctx, w, mock := organiserContext(t, "POST", "/hackathons/1/conflicts/player", `{"reason":"We work together"}`)
+	ctx.Params = append(ctx.Params, gin.Param{Key: "username", Value: "player"})
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `judges` WHERE hackathon_id = ? AND user_id = ?")).
+		WithArgs(1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	DeclareConflict(ctx)
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `{"message":"Only judges declare conflicts of interest"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestDeclareConflictLongReason(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("POST", "/hackathons/1/conflicts/player", strings.NewReader(`{"reason":"`+strings.Repeat("a", 501)+`"}`))
+
+	DeclareConflict(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"The reason can't be longer than 500 characters"}`, w.Body.String())
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+	"win-a-thon/assignment"
+	"win-a-thon/blind"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+	"win-a-thon/token"
+	"win-a-thon/utils"
+)
+
+// blinded reports whether who is behind each submission of a hackathon is hidden
+// from its judges, which it is in blind mode until the results are published
+func blinded(hackathon models.Hackathon) bool {
+	return hackathon.BlindJudging && lifecycle.Guard(hackathon, lifecycle.ActionViewResults, time.Now()) != nil
+}
+
+// entryID is the opaque ID of an entry of a hackathon
+func entryID(hackathonID uint, entry assignment.Entry) string {
+	return blind.EntryID(blind.Secret, hackathonID, entry)
+}
+
+// entryFromID loads the participants of the entry with the entry_id of the request,
+// all the members of a team, and aborts the request when there is no such entry
+func entryFromID(c *gin.Context, hackathon models.Hackathon) (assignment.Entry, []models.Participant, bool) {
+	var participants []models.Participant
+	if err := repo.GetSubmissions(&participants, strconv.Itoa(int(hackathon.ID))); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return assignment.Entry{}, nil, false
+	}
+
+	entries := make([]assignment.Entry, len(participants))
+	for i, participant := range participants {
+		entries[i] = repo.EntryOf(participant)
+	}
+	entry, ok := blind.Find(blind.Secret, hackathon.ID, c.Params.ByName("entry_id"), entries)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "This entry doesn't exist"})
+		return entry, nil, false
+	}
+
+	var members []models.Participant
+	for i, participant := range participants {
+		if entries[i] == entry {
+			members = append(members, participant)
+		}
+	}
+	return entry, members, true
+}
+
+// GetEntry shows a judge the submission of an entry by its ID, along with who is
+// behind it unless the hackathon is still judged blind
+func GetEntry(c *gin.Context) {
+	authPayload := c.MustGet(utils.AuthorizationPayloadKey).(*token.Payload)
+	user, err := repo.GetProfileByUsername(authPayload.Username)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "User doesn't exist"})
+		return
+	}
+
+	hackathon, err := repo.HackathonFromHackathonID(c.Params.ByName("hackathon_id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "This hackathon id doesnt exist"})
+		return
+	}
+
+	judge, err := canJudge(hackathon, user)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	if !judge {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Not authorised to view submission!"})
+		return
+	}
+
+	if lifecycle.Guard(hackathon, lifecycle.ActionViewSubmissions, time.Now()) != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Can't view the submission right now. Try again once the hackathon ends!!"})
+		return
+	}
+
+	entry, members, ok := entryFromID(c, hackathon)
+	if !ok {
+		return
+	}
+
+	final, err := finalSubmissions(&hackathon)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	snapshot := final[entry]
+
+	response := gin.H{"hackathon_id": hackathon.ID, "entry_id": c.Params.ByName("entry_id"), "code_url": snapshot.CodeUrl, "demo_url": snapshot.DemoUrl}
+	var team models.Team
+	if entry.TeamID != 0 {
+		team, err = repo.TeamFromID(strconv.Itoa(int(entry.TeamID)), hackathon.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return
+		}
+	}
+
+	if !blinded(hackathon) {
+		usernames := make([]string, 0, len(members))
+		for _, member := range members {
+			memberUser, err := repo.UserFromUserID(member.UserId)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+				return
+			}
+			usernames = append(usernames, memberUser.Username)
+		}
+		if entry.TeamID != 0 {
+			response["team"] = team.Name
+			response["members"] = usernames
+		} else {
+			response["username"] = usernames[0]
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// JudgeEntry records the score a judge gives an entry by its ID, which is how
+// submissions are judged in blind mode
+func JudgeEntry(c *gin.Context) {
+	hackathon, judged, ok := judgeScore(c)
+	if !ok {
+		return
+	}
+
+	entry, _, ok := entryFromID(c, hackathon)
+	if !ok {
+		return
+	}
+
+	err := repo.JudgeEntry(&judged, hackathon, entry)
+	respondJudged(c, judged, err)
+}
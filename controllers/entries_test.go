@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+	"win-a-thon/assignment"
+	"win-a-thon/blind"
+	"win-a-thon/database"
+	"win-a-thon/token"
+	"win-a-thon/utils"
+)
+
+// blindContext prepares a request by the organiser "name" (user 1) of hackathon 1,
+// which ended and is judged blind until its results are declared in an hour
+func blindContext(t *testing.T, method string, path string) (*gin.Context, *httptest.ResponseRecorder, sqlmock.Sqlmock) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "name",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	ctx.Params = []gin.Param{{Key: "hackathon_id", Value: "1"}}
+	ctx.Request, _ = http.NewRequest(method, path, nil)
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "name"))
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "starting_time", "ending_time", "result_time", "organiser_id", "admin_approved", "status", "blind_judging", "submissions_frozen_at"}).
+			AddRow(1, "Test Hackathon", now.Add(-2*time.Hour), now.Add(-time.Hour), now.Add(time.Hour), 1, true, "registration_open", true, now.Add(-time.Hour)))
+
+	return ctx, w, mock
+}
+
+func TestEntryIDIsNotDerivedFromTheTokenKey(t *testing.T) {
+	secret := blind.Secret
+	blind.Secret = "blind entry secret"
+	defer func() { blind.Secret = secret }()
+
+	entry := assignment.Entry{UserID: 7}
+	assert.Equal(t, blind.EntryID("blind entry secret", 1, entry), entryID(1, entry))
+	assert.NotEqual(t, blind.EntryID(utils.TokenSymmetricKey, 1, entry), entryID(1, entry))
+}
+
+func TestGetSubmissionsBlind(t *testing.T) {
+	ctx, w, mock := blindContext(t, "GET", "/hackathons/1/submissions")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "demo_url", "code_url"}).AddRow(1, 7, "https://demo", "https://code"))
+	expectFinalSubmissions(mock, sqlmock.NewRows([]string{"hackathon_id", "user_id", "demo_url", "code_url"}).AddRow(1, 7, "https://demo", "https://code"))
+
+	GetSubmissions(ctx)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"submissions":[{"hackathon_id":1,"entry_id":"`+entryID(1, assignment.Entry{UserID: 7})+
+		`","code_url":"https://code","demo_url":"https://demo"}],"total_submissions":1}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestJudgeSubmissionByUsernameWhenBlind(t *testing.T) {
+	ctx, w, mock := blindContext(t, "PATCH", "/hackathons/1/submissions/player/judge")
+	ctx.Params = append(ctx.Params, gin.Param{Key: "username", Value: "player"})
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `criterions` WHERE hackathon_id = ? ORDER BY id")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	JudgeSubmission(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"This hackathon is judged blind. Judge submissions by entry ID"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
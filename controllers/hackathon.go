@@ -1,21 +1,74 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
+	"win-a-thon/lifecycle"
 	"win-a-thon/models"
 	"win-a-thon/repo"
+	"win-a-thon/scoring"
 	"win-a-thon/token"
 	"win-a-thon/utils"
 )
 
+// bindHackathonListQuery reads the filtering, sorting and pagination options of a
+// listing from the query string and answers with a bad request when they are invalid
+func bindHackathonListQuery(c *gin.Context) (utils.HackathonListQuery, bool) {
+	var query utils.HackathonListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid query parameters"})
+		return query, false
+	}
+	if err := query.Validate(); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return query, false
+	}
+	return query, true
+}
+
+// invalidCursor answers with a bad request, and returns true, when listing failed
+// because of the cursor the client sent
+func invalidCursor(c *gin.Context, err error) bool {
+	if errors.Is(err, repo.ErrInvalidCursor) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return true
+	}
+	return false
+}
+
+// newPageInfo links to the next page of the current listing, keeping its other query parameters
+func newPageInfo(c *gin.Context, total int64, nextCursor string) utils.PageInfo {
+	info := utils.PageInfo{Total: total}
+	if nextCursor != "" {
+		next := *c.Request.URL
+		values := next.Query()
+		values.Set("cursor", nextCursor)
+		next.RawQuery = values.Encode()
+
+		info.NextCursor = nextCursor
+		info.Next = next.RequestURI()
+	}
+	return info
+}
+
 func ListHackathons(c *gin.Context) {
+	query, ok := bindHackathonListQuery(c)
+	if !ok {
+		return
+	}
+
 	var hackathons []models.Hackathon
-	err := repo.ListHackathons(&hackathons, true)
+	total, nextCursor, err := repo.ListHackathons(&hackathons, true, query)
+	if invalidCursor(c, err) {
+		return
+	}
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, "Service unavailable")
 		return
@@ -27,14 +80,18 @@ func ListHackathons(c *gin.Context) {
 	}
 
 	type hackathonConcise struct {
-		ID uint `json:"id"`
+		ID           uint      `json:"id"`
 		Title        string    `json:"title"`
 		StartingTime time.Time `json:"starting_time"`
 		EndingTime   time.Time `json:"ending_time"`
 		Participants int64     `json:"participants"`
+		// SeatsRemaining is null for hackathons without a participant limit
+		SeatsRemaining   *int64 `json:"seats_remaining"`
+		RegistrationOpen bool   `json:"registration_open"`
 	}
 	var hackathonConciseList []hackathonConcise
 
+	now := time.Now()
 	for _, val := range hackathons {
 		count, err := repo.CountParticipants(strconv.Itoa(int(val.ID)))
 		if err != nil {
@@ -47,66 +104,139 @@ func ListHackathons(c *gin.Context) {
 			val.StartingTime,
 			val.EndingTime,
 			count,
+			seatsRemaining(val, count),
+			lifecycle.RegistrationGuard(val, now) == nil,
 		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":     "successful",
 		"hackathons": hackathonConciseList,
+		"pagination": newPageInfo(c, total, nextCursor),
 	})
 }
 
-func CreateHackathon(c *gin.Context) {
-	authPayload := c.MustGet(utils.AuthorizationPayloadKey).(*token.Payload)
-	user, err := repo.GetProfileByUsername(authPayload.Username)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
-		return
+// seatsRemaining returns how many seats of a hackathon are still free, or nil when
+// it has no participant limit
+func seatsRemaining(hackathon models.Hackathon, participants int64) *int64 {
+	if hackathon.MaxParticipants == 0 {
+		return nil
 	}
-
-	var hackathon models.Hackathon
-
-	err = c.BindJSON(&hackathon)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "field names incorrect"})
-		return
+	seats := int64(hackathon.MaxParticipants) - participants
+	if seats < 0 {
+		seats = 0
 	}
+	return &seats
+}
 
+// validateHackathon checks the fields shared by creating and editing a hackathon
+func validateHackathon(hackathon models.Hackathon) error {
 	if hackathon.Title == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Title cannot be empty"})
-		return
+		return errors.New("Title cannot be empty")
 	}
 
 	if hackathon.Description == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Description cannot be empty"})
-		return
+		return errors.New("Description cannot be empty")
 	}
 
 	if hackathon.EndingTime.Before(hackathon.StartingTime) {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Ending time should be after starting time"})
-		return
+		return errors.New("Ending time should be after starting time")
 	}
 
 	if hackathon.ResultTime.Before(hackathon.EndingTime) {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Result time should be after ending time"})
-		return
+		return errors.New("Result time should be after ending time")
 	}
 
 	if hackathon.EndingTime.Sub(hackathon.StartingTime) < time.Hour {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Hackathon should be at least an hour long"})
-		return
+		return errors.New("Hackathon should be at least an hour long")
 	}
 
 	if hackathon.ResultTime.Sub(hackathon.EndingTime) < time.Hour {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Judging period should be at least an hour long"})
+		return errors.New("Judging period should be at least an hour long")
+	}
+
+	if hackathon.MaxParticipants < 0 {
+		return errors.New("Maximum participants can't be negative")
+	}
+
+	if hackathon.MinTeamSize < 0 || hackathon.MaxTeamSize < 0 {
+		return errors.New("Team sizes can't be negative")
+	}
+
+	if hackathon.MaxTeamSize > 0 && hackathon.MinTeamSize > hackathon.MaxTeamSize {
+		return errors.New("Minimum team size can't be more than the maximum")
+	}
+
+	closes := hackathon.EndingTime
+	if hackathon.RegistrationClosesAt != nil {
+		closes = *hackathon.RegistrationClosesAt
+		if closes.After(hackathon.EndingTime) {
+			return errors.New("Registration should close by the ending time")
+		}
+	}
+	if hackathon.RegistrationOpensAt != nil && !hackathon.RegistrationOpensAt.Before(closes) {
+		return errors.New("Registration should open before it closes")
+	}
+
+	if !scoring.IsValid(hackathon.ScoreAggregation) {
+		return errors.New("Score aggregation should be mean, median or trimmed_mean")
+	}
+
+	if !scoring.IsValidNormalization(hackathon.ScoreNormalization) {
+		return errors.New("Score normalization should be z_score or rank")
+	}
+
+	if hackathon.ReviewsPerSubmission < 0 {
+		return errors.New("Reviews per submission can't be negative")
+	}
+
+	if _, err := scoring.ParseTieBreakers(hackathon.TieBreakers); err != nil {
+		return err
+	}
+
+	if hackathon.VotingClosesAt != nil && (!hackathon.VotingClosesAt.After(hackathon.EndingTime) || hackathon.VotingClosesAt.After(hackathon.ResultTime)) {
+		return errors.New("Voting should close after the ending time and by the result time")
+	}
+
+	if hackathon.VotesPerUser < 0 {
+		return errors.New("Votes per user can't be negative")
+	}
+	return nil
+}
+
+func CreateHackathon(c *gin.Context) {
+	authPayload := c.MustGet(utils.AuthorizationPayloadKey).(*token.Payload)
+	user, err := repo.GetProfileByUsername(authPayload.Username)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	var hackathon models.Hackathon
+
+	err = c.BindJSON(&hackathon)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "field names incorrect"})
+		return
+	}
+
+	if err := validateHackathon(hackathon); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 		return
 	}
 
 	hackathon.OrganiserID = int(user.ID)
+	hackathon.CancellationReason = ""
+	hackathon.SubmissionsFrozenAt = nil
 
-	if user.IsAdmin {
+	// organisers may keep a hackathon as a draft, anything else goes to approval
+	if hackathon.Status == lifecycle.StatusDraft {
+		hackathon.AdminApproved = false
+	} else if user.IsAdmin {
+		hackathon.Status = lifecycle.StatusApproved
 		hackathon.AdminApproved = true
 	} else {
+		hackathon.Status = lifecycle.StatusPendingApproval
 		hackathon.AdminApproved = false
 	}
 
@@ -124,13 +254,183 @@ func CreateHackathon(c *gin.Context) {
 		}
 	}
 
-	if user.IsAdmin {
+	if err == nil {
+		indexHackathon(hackathon)
+	}
+
+	if hackathon.Status == lifecycle.StatusDraft {
+		c.JSON(http.StatusOK, gin.H{"status": "Hackathon saved as a draft. Request approval once it is ready."})
+	} else if user.IsAdmin {
 		c.JSON(http.StatusOK, gin.H{"status": "Request sent successfully. As you are an admin, hackathon has been automatically approved"})
 	} else {
 		c.JSON(http.StatusOK, gin.H{"status": "Request sent successfully. Kindly wait for admin approval."})
 	}
 }
 
+// timeChanged reports whether an optional time was set, unset or moved
+func timeChanged(updated *time.Time, current *time.Time) bool {
+	if updated == nil || current == nil {
+		return (updated == nil) != (current == nil)
+	}
+	return !updated.Equal(*current)
+}
+
+func UpdateHackathon(c *gin.Context) {
+	hackathon, user, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	var req utils.UpdateHackathonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "field names incorrect"})
+		return
+	}
+
+	updated := hackathon
+	if req.Title != "" {
+		updated.Title = req.Title
+	}
+	if req.Description != "" {
+		updated.Description = req.Description
+	}
+	if req.OrganisationName != "" {
+		updated.OrganisationName = req.OrganisationName
+	}
+	if !req.StartingTime.IsZero() {
+		updated.StartingTime = req.StartingTime
+	}
+	if !req.EndingTime.IsZero() {
+		updated.EndingTime = req.EndingTime
+	}
+	if !req.ResultTime.IsZero() {
+		updated.ResultTime = req.ResultTime
+	}
+	if req.ClearRegistration && (req.RegistrationOpensAt != nil || req.RegistrationClosesAt != nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "The registration window can't be cleared and set at once"})
+		return
+	}
+	if req.ClearRegistration {
+		updated.RegistrationOpensAt = nil
+		updated.RegistrationClosesAt = nil
+	}
+	if req.RegistrationOpensAt != nil {
+		updated.RegistrationOpensAt = req.RegistrationOpensAt
+	}
+	if req.RegistrationClosesAt != nil {
+		updated.RegistrationClosesAt = req.RegistrationClosesAt
+	}
+	if req.ScoreAggregation != "" {
+		updated.ScoreAggregation = req.ScoreAggregation
+	}
+	if req.ScoreNormalization == "none" {
+		updated.ScoreNormalization = ""
+	} else if req.ScoreNormalization != "" {
+		updated.ScoreNormalization = req.ScoreNormalization
+	}
+	if req.ReviewsPerSubmission != nil {
+		updated.ReviewsPerSubmission = *req.ReviewsPerSubmission
+	}
+	if req.BlindJudging != nil {
+		updated.BlindJudging = *req.BlindJudging
+	}
+	if req.TieBreakers != nil {
+		updated.TieBreakers = *req.TieBreakers
+	}
+	if req.ClearVoting && (req.VotingClosesAt != nil || req.VotesPerUser != nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Voting can't be turned off and set at once"})
+		return
+	}
+	if req.ClearVoting {
+		updated.VotingClosesAt = nil
+		updated.VotesPerUser = 0
+	}
+	if req.VotingClosesAt != nil {
+		updated.VotingClosesAt = req.VotingClosesAt
+	}
+	if req.VotesPerUser != nil {
+		updated.VotesPerUser = *req.VotesPerUser
+	}
+
+	if err := validateHackathon(updated); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// the title, organisation and timeline are what admins approve, the description is cosmetic
+	timelineChanged := !updated.StartingTime.Equal(hackathon.StartingTime) ||
+		!updated.EndingTime.Equal(hackathon.EndingTime) ||
+		!updated.ResultTime.Equal(hackathon.ResultTime) ||
+		timeChanged(updated.RegistrationOpensAt, hackathon.RegistrationOpensAt) ||
+		timeChanged(updated.RegistrationClosesAt, hackathon.RegistrationClosesAt)
+	material := timelineChanged || updated.Title != hackathon.Title || updated.OrganisationName != hackathon.OrganisationName
+
+	now := time.Now()
+	if lifecycle.Guard(hackathon, lifecycle.ActionEdit, now) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Archived hackathons can't be edited"})
+		return
+	}
+	if updated.BlindJudging != hackathon.BlindJudging && lifecycle.Guard(hackathon, lifecycle.ActionViewSubmissions, now) == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Blind judging can't be changed once judging has started"})
+		return
+	}
+	votingChanged := updated.VotesPerUser != hackathon.VotesPerUser || timeChanged(updated.VotingClosesAt, hackathon.VotingClosesAt)
+	if votingChanged && lifecycle.Guard(hackathon, lifecycle.ActionViewSubmissions, now) == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Voting can't be changed once it has opened"})
+		return
+	}
+	// the leaderboard and the prizes follow from how scores are combined and ranked
+	scoringChanged := updated.ScoreAggregation != hackathon.ScoreAggregation ||
+		updated.ScoreNormalization != hackathon.ScoreNormalization || updated.TieBreakers != hackathon.TieBreakers
+	if scoringChanged && lifecycle.Guard(hackathon, lifecycle.ActionViewResults, now) == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Scoring can't be changed once results are published"})
+		return
+	}
+	if material && lifecycle.Guard(hackathon, lifecycle.ActionEditMaterial, now) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Title, organisation and timeline can't be changed once the hackathon has started"})
+		return
+	}
+
+	reapprove := material && !user.IsAdmin && lifecycle.IsApproved(lifecycle.Stored(hackathon))
+
+	err := repo.UpdateHackathon(&hackathon, updated, reapprove, user.ID)
+	if err != nil {
+		if err, ok := err.(*mysql.MySQLError); ok && err.Number == utils.DuplicateRecordErrorCode {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Hackathon Title already exists"})
+			return
+		}
+		if err == lifecycle.ErrInvalidTransition {
+			c.JSON(http.StatusConflict, gin.H{"message": "The status of this hackathon changed meanwhile, try again"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	indexHackathon(hackathon)
+
+	if timelineChanged {
+		message := fmt.Sprintf("The timeline of hackathon %s has changed. It now starts at %s, ends at %s and results are declared at %s.",
+			hackathon.Title, hackathon.StartingTime.Format(time.RFC1123), hackathon.EndingTime.Format(time.RFC1123), hackathon.ResultTime.Format(time.RFC1123))
+		if hackathon.RegistrationClosesAt != nil {
+			message += fmt.Sprintf(" Registration closes at %s.", hackathon.RegistrationClosesAt.Format(time.RFC1123))
+		}
+		if err := notifyParticipants(hackathon.ID, "Hackathon timeline changed", message); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	status := "Hackathon updated successfully"
+	if reapprove {
+		status = "Hackathon updated successfully. Kindly wait for admin approval of the changes."
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":           status,
+		"hackathon_id":     hackathon.ID,
+		"hackathon_status": lifecycle.Current(hackathon, now),
+	})
+}
+
 func ViewHackathonDetails(c *gin.Context) {
 	var hackathon models.Hackathon
 	id := c.Params.ByName("hackathon_id")
@@ -148,15 +448,29 @@ func ViewHackathonDetails(c *gin.Context) {
 			"message": "This hackathon doesn't exist",
 		})
 	} else {
+		prizes := make([]models.Prize, 0)
+		if err := repo.ListPrizes(&prizes, hackathon.ID); err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
-			"id": hackathon.ID,
-			"title":             hackathon.Title,
-			"starting_time":     hackathon.StartingTime,
-			"ending_time":       hackathon.EndingTime,
-			"description":       hackathon.Description,
-			"result_time":       hackathon.ResultTime,
-			"organisation_name": hackathon.OrganisationName,
-			"participants":      count,
+			"id":                     hackathon.ID,
+			"title":                  hackathon.Title,
+			"starting_time":          hackathon.StartingTime,
+			"ending_time":            hackathon.EndingTime,
+			"description":            hackathon.Description,
+			"result_time":            hackathon.ResultTime,
+			"organisation_name":      hackathon.OrganisationName,
+			"participants":           count,
+			"max_participants":       hackathon.MaxParticipants,
+			"seats_remaining":        seatsRemaining(hackathon, count),
+			"registration_opens_at":  hackathon.RegistrationOpensAt,
+			"registration_closes_at": hackathon.RegistrationClosesAt,
+			"registration_open":      lifecycle.RegistrationGuard(hackathon, time.Now()) == nil,
+			"status":                 lifecycle.Current(hackathon, time.Now()),
+			"cancellation_reason":    hackathon.CancellationReason,
+			"prizes":                 prizes,
 		})
 	}
 }
@@ -186,19 +500,64 @@ func Participate(c *gin.Context) {
 	var statusHackathon models.Hackathon
 	err, _ = repo.ViewHackathonDetails(&statusHackathon, id)
 
-	if time.Now().After(statusHackathon.EndingTime) {
+	if err := lifecycle.RegistrationGuard(statusHackathon, time.Now()); err == lifecycle.ErrCancelled {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "hackathon has been cancelled!"})
+		return
+	} else if err == lifecycle.ErrNotApproved {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "hackathon is not open for registration!"})
+		return
+	} else if err == lifecycle.ErrRegistrationNotOpen {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "registration has not opened yet!"})
+		return
+	} else if err == lifecycle.ErrRegistrationClosed {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "registration is closed!"})
+		return
+	} else if err != nil {
 		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "hackathon ended already!"})
 		return
 	}
+	if !notJudging(c, statusHackathon, user.ID) {
+		return
+	}
 
 	participant.UserId = int(user.ID)
 	participant.HackathonId, err = strconv.Atoi(id)
 	if err != nil {
 		c.AbortWithStatus(http.StatusInternalServerError)
 	}
-	err = repo.CreateParticipant(&participant)
+
+	// participants may pick their track now or when they submit
+	var req utils.ParticipateRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "field names incorrect"})
+		return
+	}
+	if req.TrackID != nil {
+		track, ok := pickTrack(c, *req.TrackID, statusHackathon.ID)
+		if !ok {
+			return
+		}
+		participant.TrackID = &track.ID
+	}
+	// hackathons with a seat limit put latecomers on the waitlist
+	var position int64
+	if statusHackathon.MaxParticipants > 0 {
+		position, err = repo.JoinHackathon(&participant, statusHackathon.MaxParticipants)
+	} else {
+		err = repo.CreateParticipant(&participant)
+	}
 	if err != nil {
 		c.AbortWithStatus(http.StatusNotFound)
+	} else if position > 0 {
+		subject := "Waitlisted for hackathon " + statusHackathon.Title
+		message := fmt.Sprintf("Hackathon %s is full. You are number %d on the waitlist and will be emailed if a seat opens up.", statusHackathon.Title, position)
+		if err := utils.Notify(user.Email, subject, message); err != nil {
+			fmt.Println(err)
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "hackathon is full, added to the waitlist",
+			"hackathon_id":      statusHackathon.ID,
+			"title":             statusHackathon.Title,
+			"waitlist_position": position})
 	} else {
 		var email Email
 		email.Subject = "Participation in hackathon" + statusHackathon.Title
@@ -216,6 +575,47 @@ func Participate(c *gin.Context) {
 	}
 }
 
+// Withdraw takes the logged in user off a hackathon, or off its waitlist. A seat
+// they free goes to the next user on the waitlist, who is emailed about it.
+func Withdraw(c *gin.Context) {
+	authPayload := c.MustGet(utils.AuthorizationPayloadKey).(*token.Payload)
+	user, err := repo.GetProfileByUsername(authPayload.Username)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "User doesn't exist"})
+		return
+	}
+
+	hackathon, err := repo.HackathonFromHackathonID(c.Params.ByName("hackathon_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This hackathon doesnt exist"})
+		return
+	}
+
+	if lifecycle.Guard(hackathon, lifecycle.ActionParticipate, time.Now()) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "You can no longer withdraw from this hackathon"})
+		return
+	}
+
+	promoted, err := repo.WithdrawParticipant(hackathon.ID, user.ID, hackathon.MaxParticipants)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "You are not registered for this hackathon"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	if promoted != nil {
+		subject := "Seat confirmed in hackathon " + hackathon.Title
+		message := "A seat opened up in hackathon " + hackathon.Title + " and you have been moved off the waitlist. You are now participating."
+		if err := utils.Notify(promoted.Email, subject, message); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "withdrawn successfully", "hackathon_id": hackathon.ID})
+}
+
 func GetParticipants(c *gin.Context) {
 	hackathonID := c.Params.ByName("hackathon_id")
 
@@ -252,7 +652,7 @@ func GetParticipants(c *gin.Context) {
 	}
 
 	type ParticpantsConcise struct {
-		ID uint `json:"id"`
+		ID           uint   `json:"id"`
 		Username     string `json:"username"`
 		FullName     string `json:"full_name"`
 		Email        string `json:"email"`
@@ -301,7 +701,15 @@ func ListUnapprovedHackathons(c *gin.Context) {
 		return
 	}
 
-	err = repo.ListHackathons(&hackathons, false)
+	query, ok := bindHackathonListQuery(c)
+	if !ok {
+		return
+	}
+
+	total, nextCursor, err := repo.ListHackathons(&hackathons, false, query)
+	if invalidCursor(c, err) {
+		return
+	}
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, "service unavailable")
 		return
@@ -338,6 +746,7 @@ func ListUnapprovedHackathons(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":     "successful",
 		"hackathons": hackathonConciseList,
+		"pagination": newPageInfo(c, total, nextCursor),
 	})
 }
 
@@ -354,10 +763,18 @@ func ListOrganisedHackathons(c *gin.Context) {
 
 	id := user.ID // We get this from middleware
 
+	query, ok := bindHackathonListQuery(c)
+	if !ok {
+		return
+	}
+
 	var hackathons []models.Hackathon
 
-	err = repo.ListOrganisedHackathons(&hackathons, id)
+	total, nextCursor, err := repo.ListOrganisedHackathons(&hackathons, id, query)
 
+	if invalidCursor(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Service unavailable"})
 		return
@@ -369,24 +786,43 @@ func ListOrganisedHackathons(c *gin.Context) {
 	}
 
 	type HackathonsWrapper struct {
-		ID uint `json:"id"`
-		Title            string    `json:"title"`
-		StartingTime     time.Time `json:"starting_time"`
-		EndingTime       time.Time `json:"ending_time"`
-		ResultTime       time.Time `json:"result_time"`
-		Description      string    `json:"description"`
-		OrganisationName string    `json:"organisation_name"`
-		AdminApproved    bool      `json:"admin_approved"`
-		Participants     int64     `json:"participants"`
+		ID               uint                         `json:"id"`
+		Title            string                       `json:"title"`
+		StartingTime     time.Time                    `json:"starting_time"`
+		EndingTime       time.Time                    `json:"ending_time"`
+		ResultTime       time.Time                    `json:"result_time"`
+		Description      string                       `json:"description"`
+		OrganisationName string                       `json:"organisation_name"`
+		AdminApproved    bool                         `json:"admin_approved"`
+		Status           string                       `json:"status"`
+		Participants     int64                        `json:"participants"`
+		Reviews          []models.HackathonTransition `json:"reviews"`
 	}
 	var HackathonsWrapperList []HackathonsWrapper
 
+	ids := make([]uint, 0, len(hackathons))
+	for _, val := range hackathons {
+		ids = append(ids, val.ID)
+	}
+	var reviews []models.HackathonTransition
+	if err := repo.ListHackathonReviews(&reviews, ids); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	reviewsByHackathon := make(map[uint][]models.HackathonTransition)
+	for _, review := range reviews {
+		reviewsByHackathon[review.HackathonID] = append(reviewsByHackathon[review.HackathonID], review)
+	}
+
 	for _, val := range hackathons {
 		count, err := repo.CountParticipants(strconv.Itoa(int(val.ID)))
 		if err != nil {
 			c.AbortWithStatus(http.StatusInternalServerError)
 			return
 		}
+		if reviewsByHackathon[val.ID] == nil {
+			reviewsByHackathon[val.ID] = []models.HackathonTransition{}
+		}
 		HackathonsWrapperList = append(HackathonsWrapperList, HackathonsWrapper{
 			val.ID,
 			val.Title,
@@ -396,12 +832,15 @@ func ListOrganisedHackathons(c *gin.Context) {
 			val.Description,
 			val.OrganisationName,
 			val.AdminApproved,
+			lifecycle.Current(val, time.Now()),
 			count,
+			reviewsByHackathon[val.ID],
 		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status": "successful",
+		"status":               "successful",
 		"organised_hackathons": HackathonsWrapperList,
+		"pagination":           newPageInfo(c, total, nextCursor),
 	})
-}
\ No newline at end of file
+}
@@ -25,6 +25,7 @@ import (
 func TestListHackathons(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons", nil)
 
 	driver, mock, err := sqlmock.New()
 	if err != nil {
@@ -44,8 +45,13 @@ func TestListHackathons(t *testing.T) {
 		" Hackathon", true)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
-			"time desc")).WithArgs(true).WillReturnRows(hackathonMockRow)
+		"SELECT count(*) FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(true, "cancelled").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
+			"time desc")).WithArgs(true, "cancelled").WillReturnRows(hackathonMockRow)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT count(*) FROM `participants` WHERE hackathon_id = ?")).WithArgs("1").WillReturnRows(sqlmock.NewRows(
@@ -56,7 +62,7 @@ func TestListHackathons(t *testing.T) {
 		t.Fatal("Wrong error code")
 	}
 
-	expected := "{\"hackathons\":[{\"id\":1,\"title\":\"\",\"starting_time\":\"2021-08-02T00:00:00Z\",\"ending_time\":\"2021-08-02T00:00:00Z\",\"participants\":1}],\"status\":\"successful\"}"
+	expected := "{\"hackathons\":[{\"id\":1,\"title\":\"\",\"starting_time\":\"2021-08-02T00:00:00Z\",\"ending_time\":\"2021-08-02T00:00:00Z\",\"participants\":1,\"seats_remaining\":null,\"registration_open\":false}],\"pagination\":{\"total\":1},\"status\":\"successful\"}"
 
 	ctx.Writer.Flush()
 
@@ -70,6 +76,7 @@ func TestListHackathons(t *testing.T) {
 func TestListHackathonsRepoError1(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons", nil)
 
 	driver, mock, err := sqlmock.New()
 	if err != nil {
@@ -82,8 +89,13 @@ func TestListHackathonsRepoError1(t *testing.T) {
 	}
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
-			"time desc")).WithArgs(true).WillReturnError(errors.New("Custom Repo Error"))
+		"SELECT count(*) FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(true, "cancelled").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
+			"time desc")).WithArgs(true, "cancelled").WillReturnError(errors.New("Custom Repo Error"))
 
 	ListHackathons(ctx)
 	if ctx.Writer.Status() != http.StatusInternalServerError {
@@ -98,6 +110,7 @@ func TestListHackathonsRepoError1(t *testing.T) {
 func TestListHackathonsEmptyList(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons", nil)
 
 	driver, mock, err := sqlmock.New()
 	if err != nil {
@@ -110,8 +123,13 @@ func TestListHackathonsEmptyList(t *testing.T) {
 	}
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
-			"time desc")).WithArgs(true).WillReturnRows(sqlmock.NewRows(nil))
+		"SELECT count(*) FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(true, "cancelled").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
+			"time desc")).WithArgs(true, "cancelled").WillReturnRows(sqlmock.NewRows(nil))
 
 	ListHackathons(ctx)
 	if ctx.Writer.Status() != http.StatusOK {
@@ -126,6 +144,7 @@ func TestListHackathonsEmptyList(t *testing.T) {
 func TestListHackathonsRepoError2(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons", nil)
 
 	driver, mock, err := sqlmock.New()
 	if err != nil {
@@ -145,8 +164,13 @@ func TestListHackathonsRepoError2(t *testing.T) {
 		" Hackathon", true)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
-			"time desc")).WithArgs(true).WillReturnRows(hackathonMockRow)
+		"SELECT count(*) FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(true, "cancelled").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
+			"time desc")).WithArgs(true, "cancelled").WillReturnRows(hackathonMockRow)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT count(*) FROM `participants` WHERE hackathon_id = ?")).WithArgs("1").WillReturnError(errors.New("Custom error"))
@@ -164,6 +188,7 @@ func TestListHackathonsRepoError2(t *testing.T) {
 func TestListUnapprovedHackathons(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons", nil)
 	ctx.Keys = make(map[string]interface{})
 	ctx.Keys["authorization_payload"] = &token.Payload{
 		Username:  "admin",
@@ -211,15 +236,20 @@ func TestListUnapprovedHackathons(t *testing.T) {
 		" Hackathon", false)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
-			"time desc")).WithArgs(false).WillReturnRows(hackathonMockRow)
+		"SELECT count(*) FROM `hackathons` WHERE admin_approved = ? AND status = ? AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(false, "pending_approval").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND status = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
+			"time desc")).WithArgs(false, "pending_approval").WillReturnRows(hackathonMockRow)
 
 	ListUnapprovedHackathons(ctx)
 	if ctx.Writer.Status() != http.StatusOK {
 		t.Fatal("Wrong error code")
 	}
 
-	expected := "{\"hackathons\":[{\"id\":1,\"title\":\"\",\"starting_time\":\"2021-08-02T00:00:00Z\",\"ending_time\":\"2021-08-02T00:00:00Z\",\"result_time\":\"2021-08-02T00:00:00Z\",\"organisation_name\":\"Winathon\",\"description\":\"It is a test Hackathon\"}],\"status\":\"successful\"}"
+	expected := "{\"hackathons\":[{\"id\":1,\"title\":\"\",\"starting_time\":\"2021-08-02T00:00:00Z\",\"ending_time\":\"2021-08-02T00:00:00Z\",\"result_time\":\"2021-08-02T00:00:00Z\",\"organisation_name\":\"Winathon\",\"description\":\"It is a test Hackathon\"}],\"pagination\":{\"total\":1},\"status\":\"successful\"}"
 
 	ctx.Writer.Flush()
 
@@ -234,6 +264,7 @@ func TestListUnapprovedHackathons(t *testing.T) {
 func TestListUnapprovedHackathonsRepoError1(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons", nil)
 	ctx.Keys = make(map[string]interface{})
 	ctx.Keys["authorization_payload"] = &token.Payload{
 		Username:  "admin",
@@ -270,6 +301,7 @@ func TestListUnapprovedHackathonsRepoError1(t *testing.T) {
 func TestListUnapprovedHackathonsRepoError2(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons", nil)
 	ctx.Keys = make(map[string]interface{})
 	ctx.Keys["authorization_payload"] = &token.Payload{
 		Username:  "admin",
@@ -321,6 +353,7 @@ func TestListUnapprovedHackathonsRepoError2(t *testing.T) {
 func TestListUnapprovedHackathonsRepoError3(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons", nil)
 	ctx.Keys = make(map[string]interface{})
 	ctx.Keys["authorization_payload"] = &token.Payload{
 		Username:  "admin",
@@ -361,8 +394,13 @@ func TestListUnapprovedHackathonsRepoError3(t *testing.T) {
 
 	// For repo.ListHackathons
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
-			"time desc")).WithArgs(false).WillReturnError(errors.New("Custom Error"))
+		"SELECT count(*) FROM `hackathons` WHERE admin_approved = ? AND status = ? AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(false, "pending_approval").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND status = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
+			"time desc")).WithArgs(false, "pending_approval").WillReturnError(errors.New("Custom Error"))
 
 	ListUnapprovedHackathons(ctx)
 	if ctx.Writer.Status() != http.StatusInternalServerError {
@@ -377,6 +415,7 @@ func TestListUnapprovedHackathonsRepoError3(t *testing.T) {
 func TestListUnapprovedHackathonsRepoError4(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons", nil)
 	ctx.Keys = make(map[string]interface{})
 	ctx.Keys["authorization_payload"] = &token.Payload{
 		Username:  "admin",
@@ -417,8 +456,13 @@ func TestListUnapprovedHackathonsRepoError4(t *testing.T) {
 
 	// For repo.ListHackathons
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
-			"time desc")).WithArgs(false).WillReturnRows(sqlmock.NewRows(nil))
+		"SELECT count(*) FROM `hackathons` WHERE admin_approved = ? AND status = ? AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(false, "pending_approval").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND status = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
+			"time desc")).WithArgs(false, "pending_approval").WillReturnRows(sqlmock.NewRows(nil))
 
 	ListUnapprovedHackathons(ctx)
 	if ctx.Writer.Status() != http.StatusOK {
@@ -471,9 +515,12 @@ func TestCreateHackathon(t *testing.T) {
 
 	// For repo.CreateHackathon
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `hackathons` (`created_at`,`updated_at`,`deleted_at`,`title`,`starting_time`,`ending_time`,`result_time`,`organisation_name`,`organiser_id`,`description`,`admin_approved`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `hackathons` (`created_at`,`updated_at`,`deleted_at`,`title`,`starting_time`,`ending_time`,`result_time`,`organisation_name`,`organiser_id`,`description`,`admin_approved`,`status`,`cancellation_reason`,`max_participants`,`min_team_size`,`max_team_size`,`registration_opens_at`,`registration_closes_at`,`score_aggregation`,`score_normalization`,`reviews_per_submission`,`blind_judging`,`tie_breakers`,`voting_closes_at`,`votes_per_user`,`submissions_frozen_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")).
 		WithArgs().
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `hackathon_transitions` (`hackathon_id`,`from_status`,`to_status`,`actor_id`,`reason`,`created_at`) VALUES (?,?,?,?,?,?)")).
+		WithArgs(1, "", "approved", 0, "created", AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
 	r.Use(func(c *gin.Context) {
@@ -545,6 +592,23 @@ func TestCreateHackathonBadInput(t *testing.T) {
 			"result_time" : "2021-08-02T01:00:00+05:30",
 			"description" : "ABC",
 			"organisation_name" : "ABCD"
+		}`, `{
+			"title" : "debangan1",
+			"starting_time" : "2021-08-02T00:00:00+05:30",
+			"ending_time" : "2021-08-02T01:00:00+05:30",
+			"result_time" : "2021-08-02T02:00:00+05:30",
+			"registration_closes_at" : "2021-08-02T01:30:00+05:30",
+			"description" : "ABC",
+			"organisation_name" : "ABCD"
+		}`, `{
+			"title" : "debangan1",
+			"starting_time" : "2021-08-02T00:00:00+05:30",
+			"ending_time" : "2021-08-02T01:00:00+05:30",
+			"result_time" : "2021-08-02T02:00:00+05:30",
+			"registration_opens_at" : "2021-08-01T12:00:00+05:30",
+			"registration_closes_at" : "2021-08-01T12:00:00+05:30",
+			"description" : "ABC",
+			"organisation_name" : "ABCD"
 		}`}
 	for _, input := range inputStrings {
 		t.Run("Test", func(t *testing.T) {
@@ -648,7 +712,7 @@ func TestCreateHackathonRepoError1(t *testing.T) {
 
 	// For repo.CreateHackathon
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `hackathons` (`created_at`,`updated_at`,`deleted_at`,`title`,`starting_time`,`ending_time`,`result_time`,`organisation_name`,`organiser_id`,`description`,`admin_approved`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `hackathons` (`created_at`,`updated_at`,`deleted_at`,`title`,`starting_time`,`ending_time`,`result_time`,`organisation_name`,`organiser_id`,`description`,`admin_approved`,`status`,`cancellation_reason`,`max_participants`,`min_team_size`,`max_team_size`,`registration_opens_at`,`registration_closes_at`,`score_aggregation`,`score_normalization`,`reviews_per_submission`,`blind_judging`,`tie_breakers`,`voting_closes_at`,`votes_per_user`,`submissions_frozen_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")).
 		WithArgs().
 		WillReturnError(&mySQL.MySQLError{Number: utils.DuplicateRecordErrorCode})
 	mock.ExpectRollback()
@@ -717,7 +781,7 @@ func TestCreateHackathonRepoError2(t *testing.T) {
 
 	// For repo.CreateHackathon
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `hackathons` (`created_at`,`updated_at`,`deleted_at`,`title`,`starting_time`,`ending_time`,`result_time`,`organisation_name`,`organiser_id`,`description`,`admin_approved`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `hackathons` (`created_at`,`updated_at`,`deleted_at`,`title`,`starting_time`,`ending_time`,`result_time`,`organisation_name`,`organiser_id`,`description`,`admin_approved`,`status`,`cancellation_reason`,`max_participants`,`min_team_size`,`max_team_size`,`registration_opens_at`,`registration_closes_at`,`score_aggregation`,`score_normalization`,`reviews_per_submission`,`blind_judging`,`tie_breakers`,`voting_closes_at`,`votes_per_user`,`submissions_frozen_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")).
 		WithArgs().
 		WillReturnError(errors.New("Custom Error"))
 	mock.ExpectRollback()
@@ -1189,6 +1253,7 @@ func TestGetParticipantsRepoError5(t *testing.T) {
 func TestListOrganisedHackathons(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons", nil)
 	ctx.Keys = make(map[string]interface{})
 	ctx.Keys["authorization_payload"] = &token.Payload{
 		Username:  "admin",
@@ -1235,11 +1300,24 @@ func TestListOrganisedHackathons(t *testing.T) {
 		"approved"}).AddRow(1, date, date, nil, "Test Hackathon", date, date, date, "Winathon", int(user.ID), "It is a test"+
 		" Hackathon", true)
 
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `hackathons` WHERE (organiser_id = ?) AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(int(user.ID)).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT * FROM `hackathons` WHERE (organiser_id = ?) AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_time desc")).
 		WithArgs(int(user.ID)).
 		WillReturnRows(hackathonMockRow)
 
+	// For repo.ListHackathonReviews
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathon_transitions` WHERE hackathon_id IN (?) AND to_status IN (?,?,?) ORDER BY id")).
+		WithArgs(1, "pending_approval", "approved", "rejected").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "from_status", "to_status", "actor_id", "reason", "created_at"}).
+			AddRow(1, 1, "", "pending_approval", 2, "created", date).
+			AddRow(2, 1, "pending_approval", "approved", 5, "approved by admin", date))
+
 	// For repo.Countparticipants
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT count(*) FROM `participants` WHERE hackathon_id = ?")).WithArgs("1").WillReturnRows(sqlmock.NewRows(
@@ -1250,7 +1328,7 @@ func TestListOrganisedHackathons(t *testing.T) {
 		t.Fatal("Wrong error code")
 	}
 
-	expected := "{\"organised_hackathons\":[{\"id\":1,\"title\":\"\",\"starting_time\":\"2021-08-04T00:00:00Z\",\"ending_time\":\"2021-08-04T00:00:00Z\",\"result_time\":\"2021-08-04T00:00:00Z\",\"description\":\"It is a test Hackathon\",\"organisation_name\":\"Winathon\",\"admin_approved\":true,\"participants\":1}],\"status\":\"successful\"}"
+	expected := "{\"organised_hackathons\":[{\"id\":1,\"title\":\"\",\"starting_time\":\"2021-08-04T00:00:00Z\",\"ending_time\":\"2021-08-04T00:00:00Z\",\"result_time\":\"2021-08-04T00:00:00Z\",\"description\":\"It is a test Hackathon\",\"organisation_name\":\"Winathon\",\"admin_approved\":true,\"status\":\"results_published\",\"participants\":1,\"reviews\":[{\"id\":1,\"hackathon_id\":1,\"from_status\":\"\",\"to_status\":\"pending_approval\",\"actor_id\":2,\"reason\":\"created\",\"created_at\":\"2021-08-04T00:00:00Z\"},{\"id\":2,\"hackathon_id\":1,\"from_status\":\"pending_approval\",\"to_status\":\"approved\",\"actor_id\":5,\"reason\":\"approved by admin\",\"created_at\":\"2021-08-04T00:00:00Z\"}]}],\"pagination\":{\"total\":1},\"status\":\"successful\"}"
 
 	ctx.Writer.Flush()
 
@@ -1264,6 +1342,7 @@ func TestListOrganisedHackathons(t *testing.T) {
 func TestListOrganisedHackathonsRepoError1(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons", nil)
 	ctx.Keys = make(map[string]interface{})
 	ctx.Keys["authorization_payload"] = &token.Payload{
 		Username:  "admin",
@@ -1300,6 +1379,7 @@ func TestListOrganisedHackathonsRepoError1(t *testing.T) {
 func TestListOrganisedHackathonsRepoError2(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons", nil)
 	ctx.Keys = make(map[string]interface{})
 	ctx.Keys["authorization_payload"] = &token.Payload{
 		Username:  "admin",
@@ -1339,6 +1419,11 @@ func TestListOrganisedHackathonsRepoError2(t *testing.T) {
 		WillReturnRows(userRows)
 
 	// For repo.ListOrganisedHackathons
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `hackathons` WHERE (organiser_id = ?) AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(int(user.ID)).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT * FROM `hackathons` WHERE (organiser_id = ?) AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_time desc")).
 		WithArgs(int(user.ID)).
@@ -1357,6 +1442,7 @@ func TestListOrganisedHackathonsRepoError2(t *testing.T) {
 func TestListOrganisedHackathonsRepoError3(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons", nil)
 	ctx.Keys = make(map[string]interface{})
 	ctx.Keys["authorization_payload"] = &token.Payload{
 		Username:  "admin",
@@ -1396,6 +1482,11 @@ func TestListOrganisedHackathonsRepoError3(t *testing.T) {
 		WillReturnRows(userRows)
 
 	// For repo.ListOrganisedHackathons
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `hackathons` WHERE (organiser_id = ?) AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(int(user.ID)).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT * FROM `hackathons` WHERE (organiser_id = ?) AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_time desc")).
 		WithArgs(int(user.ID)).
@@ -1414,6 +1505,7 @@ func TestListOrganisedHackathonsRepoError3(t *testing.T) {
 func TestListOrganisedHackathonsRepoError4(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons", nil)
 	ctx.Keys = make(map[string]interface{})
 	ctx.Keys["authorization_payload"] = &token.Payload{
 		Username:  "admin",
@@ -1460,11 +1552,24 @@ func TestListOrganisedHackathonsRepoError4(t *testing.T) {
 		"approved"}).AddRow(1, date, date, nil, "Test Hackathon", date, date, date, "Winathon", int(user.ID), "It is a test"+
 		" Hackathon", true)
 
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `hackathons` WHERE (organiser_id = ?) AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(int(user.ID)).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT * FROM `hackathons` WHERE (organiser_id = ?) AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_time desc")).
 		WithArgs(int(user.ID)).
 		WillReturnRows(hackathonMockRow)
 
+	// For repo.ListHackathonReviews
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathon_transitions` WHERE hackathon_id IN (?) AND to_status IN (?,?,?) ORDER BY id")).
+		WithArgs(1, "pending_approval", "approved", "rejected").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "from_status", "to_status", "actor_id", "reason", "created_at"}).
+			AddRow(1, 1, "", "pending_approval", 2, "created", date).
+			AddRow(2, 1, "pending_approval", "approved", 5, "approved by admin", date))
+
 	// For repo.Countparticipants
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT count(*) FROM `participants` WHERE hackathon_id = ?")).WithArgs("1").
@@ -1479,3 +1584,305 @@ func TestListOrganisedHackathonsRepoError4(t *testing.T) {
 		t.Errorf("Failed to meet expectations, got error: %v", err)
 	}
 }
+
+func TestListHackathonsInvalidStatus(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons?status=someday", nil)
+
+	ListHackathons(ctx)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"status should be one of upcoming, active, judging or completed"}`, w.Body.String())
+}
+
+func TestListHackathonsNextPage(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons?status=active&limit=1", nil)
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	date := time.Date(2021, 8, 2, 0, 0, 0, 0, time.UTC)
+	hackathonMockRows := sqlmock.NewRows([]string{"id", "title", "starting_time", "ending_time"}).
+		AddRow(4, "Test Hackathon", date, date).
+		AddRow(2, "Other Hackathon", date, date)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND (starting_time <= ? AND ending_time > ?) AND `hackathons`.`deleted_at` IS NULL")).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND (starting_time <= ? AND ending_time > ?) AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_time desc,id desc LIMIT 2")).
+		WillReturnRows(hackathonMockRows)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `participants` WHERE hackathon_id = ?")).WithArgs("4").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(3))
+
+	ListHackathons(ctx)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	cursor := utils.EncodeCursor(utils.Cursor{Value: "2021-08-02T00:00:00Z", ID: 4})
+	expected := `{"hackathons":[{"id":4,"title":"Test Hackathon","starting_time":"2021-08-02T00:00:00Z","ending_time":"2021-08-02T00:00:00Z","participants":3,"seats_remaining":null,"registration_open":false}],` +
+		`"pagination":{"total":2,"next_cursor":"` + cursor + `","next":"/hackathons?cursor=` + cursor + `\u0026limit=1\u0026status=active"},"status":"successful"}`
+	assert.Equal(t, expected, w.Body.String())
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+// updateHackathonContext prepares a PATCH /hackathons/1 request by user 3 on a hackathon
+// organised by them, approved and opening for registration tomorrow
+func updateHackathonContext(t *testing.T, body string) (*httptest.ResponseRecorder, *gin.Context, sqlmock.Sqlmock) {
+	return updateHackathonContextAt(t, body, time.Now().Add(24*time.Hour).Truncate(time.Second), "registration_open")
+}
+
+// updateHackathonContextAt prepares a PATCH /hackathons/1 request by user 3 on a
+// hackathon organised by them, starting at start and stored with status
+func updateHackathonContextAt(t *testing.T, body string, start time.Time, status string) (*httptest.ResponseRecorder, *gin.Context, sqlmock.Sqlmock) {
+	return updateHackathonContextOf(t, body, sqlmock.NewRows([]string{"id", "title", "starting_time", "ending_time", "result_time", "organisation_name", "organiser_id", "description", "admin_approved", "status"}).
+		AddRow(1, "Test Hackathon", start, start.Add(24*time.Hour), start.Add(48*time.Hour), "Winathon", 3, "It is a test Hackathon", true, status))
+}
+
+// updateHackathonContextOf prepares a PATCH /hackathons/1 request by user 3 on the
+// hackathon stored as hackathonRows, which is organised by them
+func updateHackathonContextOf(t *testing.T, body string, hackathonRows *sqlmock.Rows) (*httptest.ResponseRecorder, *gin.Context, sqlmock.Sqlmock) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("PATCH", "/hackathons/1", strings.NewReader(body))
+	ctx.Params = []gin.Param{{Key: "hackathon_id", Value: "1"}}
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "name",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	userRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "username", "is_admin"}).
+		AddRow(3, time.Now(), time.Now(), nil, "name", false)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(userRows)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(hackathonRows)
+
+	return w, ctx, mock
+}
+
+func TestUpdateHackathonInvalidTimeline(t *testing.T) {
+	ending := time.Now().Add(24*time.Hour + 30*time.Minute).UTC().Format(time.RFC3339)
+	w, ctx, mock := updateHackathonContext(t, `{"ending_time":"`+ending+`"}`)
+
+	UpdateHackathon(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Hackathon should be at least an hour long"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestUpdateHackathonCosmetic(t *testing.T) {
+	w, ctx, mock := updateHackathonContext(t, `{"description":"A friendlier description"}`)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `hackathons` SET `updated_at`=?,`title`=?,`starting_time`=?,`ending_time`=?,`result_time`=?,`organisation_name`=?,`description`=?,`registration_opens_at`=?,`registration_closes_at`=?,`score_aggregation`=?,`score_normalization`=?,`reviews_per_submission`=?,`blind_judging`=?,`tie_breakers`=?,`voting_closes_at`=?,`votes_per_user`=? WHERE id = ? AND `hackathons`.`deleted_at` IS NULL")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	UpdateHackathon(ctx)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"hackathon_id":1,"hackathon_status":"registration_open","status":"Hackathon updated successfully"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestUpdateHackathonMaterialNeedsReapproval(t *testing.T) {
+	starting := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	body := fmt.Sprintf(`{"starting_time":"%s","ending_time":"%s","result_time":"%s"}`,
+		starting.Format(time.RFC3339), starting.Add(24*time.Hour).Format(time.RFC3339), starting.Add(48*time.Hour).Format(time.RFC3339))
+	w, ctx, mock := updateHackathonContext(t, body)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `hackathons` SET `updated_at`=?,`title`=?,`starting_time`=?,`ending_time`=?,`result_time`=?,`organisation_name`=?,`description`=?,`registration_opens_at`=?,`registration_closes_at`=?,`score_aggregation`=?,`score_normalization`=?,`reviews_per_submission`=?,`blind_judging`=?,`tie_breakers`=?,`voting_closes_at`=?,`votes_per_user`=? WHERE id = ? AND `hackathons`.`deleted_at` IS NULL")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `hackathons` SET `admin_approved`=?,`status`=?,`updated_at`=? WHERE (id = ? AND status = ?) AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(false, "pending_approval", AnyTime{}, 1, "registration_open").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `hackathon_transitions` (`hackathon_id`,`from_status`,`to_status`,`actor_id`,`reason`,`created_at`) VALUES (?,?,?,?,?,?)")).
+		WithArgs(1, "registration_open", "pending_approval", 3, "edited, awaiting re-approval", AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE id IN (SELECT `user_id` FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL) AND `users`.`deleted_at` IS NULL")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email"}))
+
+	UpdateHackathon(ctx)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"hackathon_id":1,"hackathon_status":"pending_approval","status":"Hackathon updated successfully. Kindly wait for admin approval of the changes."}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestUpdateHackathonScoringAfterResults(t *testing.T) {
+	start := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	for _, body := range []string{`{"score_normalization":"z_score"}`, `{"score_aggregation":"median"}`, `{"tie_breakers":"earliest_submission"}`} {
+		w, ctx, mock := updateHackathonContextAt(t, body, start, "results_published")
+
+		UpdateHackathon(ctx)
+		assert.EqualValues(t, http.StatusBadRequest, w.Code, body)
+		assert.Equal(t, `{"message":"Scoring can't be changed once results are published"}`, w.Body.String(), body)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Failed to meet expectations, got error: %v", err)
+		}
+	}
+}
+
+func TestUpdateHackathonRegistrationAfterStart(t *testing.T) {
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	opens, closes := start.Add(-48*time.Hour).UTC().Format(time.RFC3339), start.Add(-time.Minute).UTC().Format(time.RFC3339)
+	w, ctx, mock := updateHackathonContextAt(t, `{"registration_opens_at":"`+opens+`","registration_closes_at":"`+closes+`"}`, start, "running")
+
+	UpdateHackathon(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Title, organisation and timeline can't be changed once the hackathon has started"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestUpdateHackathonKeepsTheOtherRegistrationEnd(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	closes := start.Add(-time.Hour)
+	rows := sqlmock.NewRows([]string{"id", "title", "starting_time", "ending_time", "result_time", "organisation_name", "organiser_id", "description", "admin_approved", "status", "registration_closes_at"}).
+		AddRow(1, "Test Hackathon", start, start.Add(24*time.Hour), start.Add(48*time.Hour), "Winathon", 3, "It is a test Hackathon", true, "registration_open", closes)
+	// the stored closing time stays and is checked against the new opening time
+	w, ctx, mock := updateHackathonContextOf(t, `{"registration_opens_at":"`+closes.Add(time.Minute).UTC().Format(time.RFC3339)+`"}`, rows)
+
+	UpdateHackathon(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Registration should open before it closes"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestUpdateHackathonClearAndSetAtOnce(t *testing.T) {
+	closes := time.Now().Add(12 * time.Hour).UTC().Format(time.RFC3339)
+	for body, message := range map[string]string{
+		`{"clear_registration":true,"registration_closes_at":"` + closes + `"}`: "The registration window can't be cleared and set at once",
+		`{"clear_voting":true,"votes_per_user":2}`:                              "Voting can't be turned off and set at once",
+	} {
+		w, ctx, mock := updateHackathonContext(t, body)
+
+		UpdateHackathon(ctx)
+		assert.EqualValues(t, http.StatusBadRequest, w.Code, body)
+		assert.Equal(t, `{"message":"`+message+`"}`, w.Body.String(), body)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Failed to meet expectations, got error: %v", err)
+		}
+	}
+}
+
+func TestUpdateHackathonKeepsTheVotingClosingTime(t *testing.T) {
+	start := time.Now().Add(-30 * time.Hour).Truncate(time.Second)
+	votingCloses := start.Add(30 * time.Hour)
+	rows := sqlmock.NewRows([]string{"id", "title", "starting_time", "ending_time", "result_time", "organisation_name", "organiser_id", "description", "admin_approved", "status", "voting_closes_at", "votes_per_user"}).
+		AddRow(1, "Test Hackathon", start, start.Add(24*time.Hour), start.Add(48*time.Hour), "Winathon", 3, "It is a test Hackathon", true, "judging", votingCloses, 3)
+	// voting is open, so only settings given as they are can pass
+	w, ctx, mock := updateHackathonContextOf(t, `{"votes_per_user":3,"description":"Now with voting"}`, rows)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `hackathons` SET `updated_at`=?,`title`=?,`starting_time`=?,`ending_time`=?,`result_time`=?,`organisation_name`=?,`description`=?,`registration_opens_at`=?,`registration_closes_at`=?,`score_aggregation`=?,`score_normalization`=?,`reviews_per_submission`=?,`blind_judging`=?,`tie_breakers`=?,`voting_closes_at`=?,`votes_per_user`=? WHERE id = ? AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(AnyTime{}, "Test Hackathon", start, start.Add(24*time.Hour), start.Add(48*time.Hour), "Winathon", "Now with voting", nil, nil, "", "", 0, false, "", votingCloses, 3, 1).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	UpdateHackathon(ctx)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"hackathon_id":1,"hackathon_status":"judging","status":"Hackathon updated successfully"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestWithdrawAfterHackathonEnded(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "name",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	ctx.Params = []gin.Param{{Key: "hackathon_id", Value: "1"}}
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	userRows := sqlmock.NewRows([]string{"id", "username", "email"}).AddRow(3, "name", "name@example.com")
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(userRows)
+
+	ended := time.Now().Add(-time.Hour)
+	hackathonRows := sqlmock.NewRows([]string{"id", "title", "starting_time", "ending_time", "result_time", "organiser_id", "admin_approved", "status", "max_participants"}).
+		AddRow(1, "Test Hackathon", ended.Add(-2*time.Hour), ended, ended.Add(2*time.Hour), 2, true, "running", 10)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(hackathonRows)
+
+	Withdraw(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"You can no longer withdraw from this hackathon"}`, w.Body.String())
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
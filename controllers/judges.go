@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+	"win-a-thon/utils"
+)
+
+// canJudge reports whether a user may score the submissions of a hackathon, which
+// its organiser and the judges they invited can
+func canJudge(hackathon models.Hackathon, user models.User) (bool, error) {
+	if hackathon.OrganiserID == int(user.ID) {
+		return true, nil
+	}
+	return repo.IsJudge(hackathon.ID, user.ID)
+}
+
+// judgesOpen answers with a bad request, and returns false, once the judges of a
+// hackathon can no longer change because its results are out
+func judgesOpen(c *gin.Context, hackathon models.Hackathon) bool {
+	now := time.Now()
+	if lifecycle.Guard(hackathon, lifecycle.ActionEdit, now) != nil || lifecycle.Guard(hackathon, lifecycle.ActionViewResults, now) == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Judges can't be changed once results are declared"})
+		return false
+	}
+	return true
+}
+
+func ListJudges(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	var users []models.User
+	if err := repo.ListJudges(&users, hackathon.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	judges := make([]gin.H, 0, len(users))
+	for _, user := range users {
+		judges = append(judges, gin.H{"username": user.Username, "full_name": user.FullName, "organisation": user.Organisation})
+	}
+	c.JSON(http.StatusOK, gin.H{"judges": judges})
+}
+
+// InviteJudge lets the organiser add a user to the judges of a hackathon
+func InviteJudge(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok || !judgesOpen(c, hackathon) {
+		return
+	}
+
+	user, err := repo.GetProfileByUsername(c.Params.ByName("username"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Username doesn't exist"})
+		return
+	}
+	if hackathon.OrganiserID == int(user.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "The organiser already judges this hackathon"})
+		return
+	}
+
+	participant, err := repo.ParticipantFromUserID(strconv.Itoa(int(hackathon.ID)), strconv.Itoa(int(user.ID)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	if participant.UserId == int(user.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Participants can't judge the hackathon"})
+		return
+	}
+
+	judge := models.Judge{HackathonID: hackathon.ID, UserID: user.ID}
+	if err := repo.AddJudge(&judge); err != nil {
+		if err, ok := err.(*mysql.MySQLError); ok && err.Number == utils.DuplicateRecordErrorCode {
+			c.JSON(http.StatusBadRequest, gin.H{"message": user.Username + " already judges this hackathon"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	message := fmt.Sprintf("You have been invited to judge hackathon %s. Judging opens when the hackathon ends at %s.",
+		hackathon.Title, hackathon.EndingTime.Format(time.RFC1123))
+	if err := utils.Notify(user.Email, "Invitation to judge", message); err != nil {
+		fmt.Println(err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "judge invited successfully", "username": user.Username})
+}
+
+// RemoveJudge takes a judge off a hackathon, discarding the scores they gave. Once
+// judges are assigned, the entries the judge was assigned are reassigned.
+func RemoveJudge(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok || !judgesOpen(c, hackathon) {
+		return
+	}
+
+	user, err := repo.GetProfileByUsername(c.Params.ByName("username"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Username doesn't exist"})
+		return
+	}
+
+	if err := repo.RemoveJudge(hackathon, user.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": user.Username + " doesn't judge this hackathon"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	// the entries in the queue of the judge go to the others
+	response := gin.H{"status": "judge removed successfully"}
+	if hackathon.ReviewsPerSubmission > 0 && lifecycle.Guard(hackathon, lifecycle.ActionViewSubmissions, time.Now()) == nil {
+		made, short, err := repo.AssignJudges(hackathon)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return
+		}
+		response["reassigned"] = made
+		response["short_of_judges"] = len(short)
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestInviteParticipantAsJudge(t *testing.T) {
+	ctx, w, mock := organiserContext(t, "POST", "/hackathons/1/judges/player", "")
+	ctx.Params = append(ctx.Params, gin.Param{Key: "username", Value: "player"})
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("player").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(7, "player"))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL")).
+		WithArgs("1", "7").
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id"}).AddRow(1, 7))
+
+	InviteJudge(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Participants can't judge the hackathon"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestRemoveJudgeNotJudging(t *testing.T) {
+	ctx, w, mock := organiserContext(t, "DELETE", "/hackathons/1/judges/someone", "")
+	ctx.Params = append(ctx.Params, gin.Param{Key: "username", Value: "someone"})
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("someone").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(8, "someone"))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `judges` WHERE hackathon_id = ? AND user_id = ?")).
+		WithArgs(1, 8).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	RemoveJudge(ctx)
+	assert.EqualValues(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"message":"someone doesn't judge this hackathon"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+	"win-a-thon/token"
+	"win-a-thon/utils"
+)
+
+// authorisedHackathon loads the hackathon of the request and the logged in user, and
+// aborts the request unless the user organises it, or is an admin when allowAdmin is set.
+func authorisedHackathon(c *gin.Context, allowAdmin bool) (models.Hackathon, models.User, bool) {
+	authPayload := c.MustGet(utils.AuthorizationPayloadKey).(*token.Payload)
+	user, err := repo.GetProfileByUsername(authPayload.Username)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "User doesn't exist"})
+		return models.Hackathon{}, user, false
+	}
+
+	hackathon, err := repo.HackathonFromHackathonID(c.Params.ByName("hackathon_id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "This hackathon id doesnt exist"})
+		return hackathon, user, false
+	}
+
+	if hackathon.OrganiserID != int(user.ID) && !(allowAdmin && user.IsAdmin) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Not authorised! You are not the organiser."})
+		return hackathon, user, false
+	}
+	return hackathon, user, true
+}
+
+func RequestApproval(c *gin.Context) {
+	hackathon, user, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	reason := "approval requested"
+	if lifecycle.Stored(hackathon) == lifecycle.StatusRejected {
+		reason = "resubmitted after revision"
+	}
+
+	err := repo.TransitionHackathon(&hackathon, lifecycle.StatusPendingApproval, &user.ID, reason)
+	if err == lifecycle.ErrInvalidTransition {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Only drafts and rejected hackathons can be sent for approval"})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  hackathon.Status,
+		"message": "Request sent successfully. Kindly wait for admin approval.",
+	})
+}
+
+func ArchiveHackathon(c *gin.Context) {
+	hackathon, user, ok := authorisedHackathon(c, true)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if lifecycle.Guard(hackathon, lifecycle.ActionArchive, now) != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Only hackathons with published results can be archived"})
+		return
+	}
+
+	// the stored status may lag behind the timeline, catch it up before archiving
+	if err := repo.AdvanceHackathon(&hackathon, now); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+		return
+	}
+	if err := repo.TransitionHackathon(&hackathon, lifecycle.StatusArchived, &user.ID, "archived"); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  hackathon.Status,
+		"message": "Hackathon archived",
+	})
+}
+
+func GetHackathonHistory(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, true)
+	if !ok {
+		return
+	}
+
+	var transitions []models.HackathonTransition
+	if err := repo.ListHackathonTransitions(&transitions, hackathon.ID); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"hackathon_id": hackathon.ID,
+		"status":       lifecycle.Current(hackathon, time.Now()),
+		"transitions":  transitions,
+	})
+}
+
+func CancelHackathon(c *gin.Context) {
+	hackathon, user, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	var req utils.CancelHackathonRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Reason) == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "A cancellation reason is required"})
+		return
+	}
+	if utf8.RuneCountInString(strings.TrimSpace(req.Reason)) > utils.MaxReasonLength {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("The cancellation reason can't be longer than %d characters", utils.MaxReasonLength)})
+		return
+	}
+
+	// catch the stored status up first so the transition is checked against where the hackathon really is
+	if err := repo.AdvanceHackathon(&hackathon, time.Now()); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+		return
+	}
+
+	err := repo.CancelHackathon(&hackathon, strings.TrimSpace(req.Reason), user.ID)
+	if err == lifecycle.ErrInvalidTransition {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "This hackathon can no longer be cancelled"})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+		return
+	}
+
+	unindexHackathon(hackathon.ID)
+
+	message := "Unfortunately hackathon " + hackathon.Title + " has been cancelled by its organiser. Reason: " + hackathon.CancellationReason
+	if err := notifyParticipants(hackathon.ID, "Hackathon cancelled", message); err != nil {
+		fmt.Println(err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  hackathon.Status,
+		"message": "Hackathon cancelled and participants notified",
+	})
+}
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"win-a-thon/database"
+	"win-a-thon/token"
+)
+
+func TestRequestApprovalNotDraft(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "name",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	ctx.Params = []gin.Param{{Key: "hackathon_id", Value: "1"}}
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	userRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "username", "is_admin"}).
+		AddRow(3, time.Now(), time.Now(), nil, "name", false)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(userRows)
+
+	hackathonRows := sqlmock.NewRows([]string{"id", "title", "organiser_id", "admin_approved", "status"}).
+		AddRow(1, "Test Hackathon", 3, false, "pending_approval")
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(hackathonRows)
+
+	RequestApproval(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Only drafts and rejected hackathons can be sent for approval"}`, w.Body.String())
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestGetHackathonHistoryNotOrganiser(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "name",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	ctx.Params = []gin.Param{{Key: "hackathon_id", Value: "1"}}
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	userRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "username", "is_admin"}).
+		AddRow(4, time.Now(), time.Now(), nil, "name", false)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(userRows)
+
+	hackathonRows := sqlmock.NewRows([]string{"id", "title", "organiser_id", "admin_approved", "status"}).
+		AddRow(1, "Test Hackathon", 3, true, "running")
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(hackathonRows)
+
+	GetHackathonHistory(ctx)
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+// cancelHackathonContext prepares a PATCH /hackathons/1/cancel request by the organiser of a running hackathon
+func cancelHackathonContext(t *testing.T, body string) (*httptest.ResponseRecorder, *gin.Context, sqlmock.Sqlmock) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("PATCH", "/hackathons/1/cancel", strings.NewReader(body))
+	ctx.Params = []gin.Param{{Key: "hackathon_id", Value: "1"}}
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "name",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	userRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "username", "is_admin"}).
+		AddRow(3, time.Now(), time.Now(), nil, "name", false)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(userRows)
+
+	now := time.Now()
+	hackathonRows := sqlmock.NewRows([]string{"id", "title", "starting_time", "ending_time", "result_time", "organiser_id", "admin_approved", "status"}).
+		AddRow(1, "Test Hackathon", now.Add(-time.Hour), now.Add(time.Hour), now.Add(2*time.Hour), 3, true, "running")
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(hackathonRows)
+
+	return w, ctx, mock
+}
+
+func TestCancelHackathonWithoutReason(t *testing.T) {
+	w, ctx, mock := cancelHackathonContext(t, `{"reason":"  "}`)
+
+	CancelHackathon(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"A cancellation reason is required"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestCancelHackathonLongReason(t *testing.T) {
+	w, ctx, mock := cancelHackathonContext(t, `{"reason":"`+strings.Repeat("é", 501)+`"}`)
+
+	CancelHackathon(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"The cancellation reason can't be longer than 500 characters"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestCancelHackathon(t *testing.T) {
+	w, ctx, mock := cancelHackathonContext(t, `{"reason":"Venue unavailable"}`)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `hackathons` SET `cancellation_reason`=?,`updated_at`=? WHERE id = ? AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs("Venue unavailable", AnyTime{}, 1).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `hackathons` SET `status`=?,`updated_at`=? WHERE (id = ? AND status = ?) AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs("cancelled", AnyTime{}, 1, "running").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `hackathon_transitions` (`hackathon_id`,`from_status`,`to_status`,`actor_id`,`reason`,`created_at`) VALUES (?,?,?,?,?,?)")).
+		WithArgs(1, "running", "cancelled", 3, "Venue unavailable", AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE id IN (SELECT `user_id` FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL) AND `users`.`deleted_at` IS NULL")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email"}))
+
+	CancelHackathon(ctx)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"message":"Hackathon cancelled and participants notified","status":"cancelled"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
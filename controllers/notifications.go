@@ -127,3 +127,19 @@ func NotifyAll(c *gin.Context) {
 		})
 	}
 }
+
+// notifyParticipants emails everyone registered for a hackathon and returns the
+// first failure, if any, once every participant has been tried
+func notifyParticipants(hackathonID uint, subject string, message string) error {
+	var users []models.User
+	if err := repo.ParticipantUsers(&users, hackathonID); err != nil {
+		return err
+	}
+	var failed error
+	for _, user := range users {
+		if err := utils.Notify(user.Email, subject, message); err != nil && failed == nil {
+			failed = err
+		}
+	}
+	return failed
+}
@@ -1,11 +1,14 @@
 package controllers
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
-	"win-a-thon/database"
+	"win-a-thon/lifecycle"
+	"win-a-thon/links"
 	"win-a-thon/models"
 	"win-a-thon/repo"
 	"win-a-thon/token"
@@ -34,13 +37,15 @@ func UpdateSubmission(c *gin.Context) {
 
 	var statusHackathon models.Hackathon
 	err, _ = repo.ViewHackathonDetails(&statusHackathon, id)
-	if time.Now().Before(statusHackathon.StartingTime) {
-		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "hackathon not started yet!"})
+	if err := lifecycle.Guard(statusHackathon, lifecycle.ActionSubmit, time.Now()); err == lifecycle.ErrCancelled {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "hackathon has been cancelled!"})
 		return
-	}
-	if time.Now().After(statusHackathon.EndingTime) {
+	} else if err == lifecycle.ErrTooLate {
 		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "hackathon ended already!"})
 		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": "hackathon not started yet!"})
+		return
 	}
 	p.HackathonId, err = strconv.Atoi(id)
 	p.UserId = int(user.ID)
@@ -48,26 +53,132 @@ func UpdateSubmission(c *gin.Context) {
 	type Submission struct {
 		DemoUrl string `json:"demo_url"`
 		CodeUrl string `json:"code_url"`
+		TrackID *uint  `json:"track_id"`
 	}
 	var temp Submission
-	c.BindJSON(&temp)
+	var uploads []upload
+	hasDemo, hasCode := true, true
+	// files like a pitch deck or a build come along with the links in a multipart form,
+	// which can leave out links that stay as they were
+	if isMultipart(c) {
+		var ok bool
+		if uploads, ok = receiveArtifacts(c); !ok {
+			return
+		}
+		temp.DemoUrl, hasDemo = c.GetPostForm("demo_url")
+		temp.CodeUrl, hasCode = c.GetPostForm("code_url")
+		if trackID := c.PostForm("track_id"); trackID != "" {
+			track, err := strconv.ParseUint(trackID, 10, 64)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "track_id should be a number"})
+				return
+			}
+			temp.TrackID = new(uint)
+			*temp.TrackID = uint(track)
+		}
+	} else {
+		c.BindJSON(&temp)
+	}
+	keepLinks := func(demoUrl string, codeUrl string) {
+		if !hasDemo {
+			temp.DemoUrl = demoUrl
+		}
+		if !hasCode {
+			temp.CodeUrl = codeUrl
+		}
+	}
+	temp.CodeUrl, temp.DemoUrl = strings.TrimSpace(temp.CodeUrl), strings.TrimSpace(temp.DemoUrl)
+	for _, link := range []string{temp.CodeUrl, temp.DemoUrl} {
+		if err := links.Validate(link); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+	}
 
 	err = repo.GetSubmission(&p, p.HackathonId, user.ID)
 	if err != nil {
 		c.AbortWithStatus(http.StatusNotFound)
 	}
 
+	// a change of track is checked now but only made along with the submission
+	if temp.TrackID != nil {
+		if _, ok := pickTrack(c, *temp.TrackID, statusHackathon.ID); !ok {
+			return
+		}
+	}
+
+	// teams make one submission together, which needs enough members
+	if p.TeamID != nil {
+		team, err := repo.TeamFromID(strconv.Itoa(int(*p.TeamID)), statusHackathon.ID)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		members, err := repo.CountTeamMembers(team.ID)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if members < int64(statusHackathon.MinTeamSize) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("Teams need at least %d members to submit", statusHackathon.MinTeamSize)})
+			return
+		}
+		artifacts, ok := storeArtifacts(c, statusHackathon.ID, repo.EntryOf(p), user.ID, uploads)
+		if !ok {
+			return
+		}
+		keepLinks(team.DemoUrl, team.CodeUrl)
+		if err := repo.SubmitTeam(&team, &p, temp.DemoUrl, temp.CodeUrl, temp.TrackID, user.ID); err != nil {
+			discardArtifacts(c, artifacts)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		response := gin.H{
+			"hackathon_id": statusHackathon.ID,
+			"Title":        statusHackathon.Title,
+			"team":         team.Name,
+			"code_url":     team.CodeUrl,
+			"demo_url":     team.DemoUrl,
+			"message":      "submitted successfully",
+		}
+		if len(artifacts) > 0 {
+			response["artifacts"] = describeArtifacts(artifacts, time.Now())
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	if statusHackathon.MinTeamSize > 1 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("Teams need at least %d members to submit", statusHackathon.MinTeamSize)})
+		return
+	}
+
+	artifacts, ok := storeArtifacts(c, statusHackathon.ID, repo.EntryOf(p), user.ID, uploads)
+	if !ok {
+		return
+	}
+
+	submittedAt := time.Now()
+	keepLinks(p.DemoUrl, p.CodeUrl)
 	p.DemoUrl = temp.DemoUrl
 	p.CodeUrl = temp.CodeUrl
+	p.SubmittedAt = &submittedAt
 
-	database.DB.Save(&p)
+	if err := repo.SubmitParticipant(&p, temp.TrackID); err != nil {
+		discardArtifacts(c, artifacts)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"hackathon_id": statusHackathon.ID,
 		"Title":        statusHackathon.Title,
 		"code_url":     p.CodeUrl,
 		"demo_url":     p.DemoUrl,
 		"message":      "submitted successfully",
-	})
+	}
+	if len(artifacts) > 0 {
+		response["artifacts"] = describeArtifacts(artifacts, time.Now())
+	}
+	c.JSON(http.StatusOK, response)
 	return
 }
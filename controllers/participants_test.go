@@ -55,21 +55,23 @@ func TestUpdateSubmission(t *testing.T) {
 	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).WithArgs("tim").WillReturnRows(userRows)
 
 	participantMockRows := sqlmock.NewRows([]string{"hackathon_id", "user_id", "demo_url", "code_url", "score"}).AddRow(1, 0, "", "", 0)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ? AND user_id = ? ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs("1", 0).WillReturnRows(participantMockRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs("1", 0).WillReturnRows(participantMockRows)
 
 	var created_at = time.Now()
-	date := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	date := time.Now().Add(24 * time.Hour)
 	hackathonMockRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "titles", "starting_time", "ending_time", "result_time", "organisation_name", "organiser_id", "description", "admin_approved"}).AddRow("1", created_at, created_at, sql.NullTime{Time: time.Time{}, Valid: false}, "Test Hackathon", created_at, date, date, "Winathon", "1", "It is a test Hackathon", true)
 	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `hackathons` WHERE (id = ? AND admin_approved = ?)")).WithArgs("1", true).WillReturnRows(hackathonMockRows)
 
 	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `participants` WHERE hackathon_id = ?")).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
 	participantMockRows2 := sqlmock.NewRows([]string{"hackathon_id", "user_id", "demo_url", "code_url", "score"}).AddRow(1, 0, "", "", 0)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ? AND user_id = ? AND `participants`.`hackathon_id` = ? ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs(1, 0, 1).WillReturnRows(participantMockRows2)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ? AND user_id = ? AND `participants`.`deleted_at` IS NULL AND `participants`.`hackathon_id` = ? ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs(1, 0, 1).WillReturnRows(participantMockRows2)
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `participants` (`hackathon_id`,`user_id`,`demo_url`,`code_url`,`score`) VALUES (?,?,?,?,?)")).WithArgs(1, 0, "abc", "xyz", 0).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `participants` (`hackathon_id`,`user_id`,`demo_url`,`code_url`,`score`,`raw_score`,`judge_count`,`team_id`,`track_id`,`submitted_at`,`deleted_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).WithArgs(1, 0, "https://demo.example.com", "https://github.com/tim/app", 0.0, 0.0, 0, nil, nil, AnyTime{}, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	// every submission is kept as a revision, saved along with it
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `submission_revisions` (`hackathon_id`,`user_id`,`team_id`,`actor_id`,`demo_url`,`code_url`,`created_at`) VALUES (?,?,?,?,?,?,?)")).WithArgs(1, 0, 0, 0, "https://demo.example.com", "https://github.com/tim/app", AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
-	jsonParams := `{"demo_url":"abc","code_url":"xyz"}`
+	jsonParams := `{"demo_url":"https://demo.example.com","code_url":"https://github.com/tim/app"}`
 
 	r.Use(func(c *gin.Context) {
 		c.Set("authorization_payload", &token.Payload{
@@ -84,3 +86,41 @@ func TestUpdateSubmission(t *testing.T) {
 
 	assert.EqualValues(t, http.StatusOK, w.Code)
 }
+
+func TestUpdateSubmissionTrackNotMovedWhenRefused(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(w)
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).WithArgs("tim").WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(0, "tim"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs("1", 0).WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id"}).AddRow(1, 0))
+
+	started := time.Now().Add(-time.Hour)
+	date := time.Now().Add(24 * time.Hour)
+	hackathonMockRows := sqlmock.NewRows([]string{"id", "title", "starting_time", "ending_time", "result_time", "organiser_id", "admin_approved", "min_team_size"}).AddRow(1, "Test Hackathon", started, date, date, 1, true, 2)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `hackathons` WHERE (id = ? AND admin_approved = ?)")).WithArgs("1", true).WillReturnRows(hackathonMockRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `participants` WHERE hackathon_id = ?")).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ? AND user_id = ? AND `participants`.`deleted_at` IS NULL AND `participants`.`hackathon_id` = ? ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs(1, 0, 1).WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id"}).AddRow(1, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `tracks` WHERE id = ? AND hackathon_id = ? ORDER BY `tracks`.`id` LIMIT 1")).WithArgs("6", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "name"}).AddRow(6, 1, "Fintech"))
+
+	r.Use(func(c *gin.Context) {
+		c.Set("authorization_payload", &token.Payload{Username: "tim", IssuedAt: time.Now(), ExpiredAt: time.Now().Add(time.Hour)})
+	})
+	r.PATCH("/:hackathon_id/submit", UpdateSubmission)
+	req, _ := http.NewRequest("PATCH", "/1/submit", strings.NewReader(`{"demo_url":"https://demo.example.com","code_url":"https://github.com/tim/app","track_id":6}`))
+	r.ServeHTTP(w, req)
+
+	// the solo participant can't submit, so they stay in their track
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Teams need at least 2 members to submit"}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,24 +1,88 @@
 package controllers
 
 import (
-	"fmt"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+	"win-a-thon/lifecycle"
 	"win-a-thon/models"
 	"win-a-thon/repo"
+	"win-a-thon/scoring"
 	"win-a-thon/token"
 	"win-a-thon/utils"
 )
 
-func JudgeSubmission(c *gin.Context) {
-	var participant models.Participant
+// hackathonTeams loads the teams of a hackathon by ID, when any of the participants
+// competes in one
+func hackathonTeams(participants []models.Participant, hackathonID uint) (map[uint]models.Team, error) {
+	teams := make(map[uint]models.Team)
+	inTeams := false
+	for _, participant := range participants {
+		inTeams = inTeams || participant.TeamID != nil
+	}
+	if !inTeams {
+		return teams, nil
+	}
+
+	var list []models.Team
+	if err := repo.ListTeams(&list, hackathonID); err != nil {
+		return nil, err
+	}
+	for _, team := range list {
+		teams[team.ID] = team
+	}
+	return teams, nil
+}
+
+// hackathonTracks loads the tracks of a hackathon by ID, when any of the
+// participants has picked one
+func hackathonTracks(participants []models.Participant, hackathonID uint) (map[uint]models.Track, error) {
+	tracks := make(map[uint]models.Track)
+	inTracks := false
+	for _, participant := range participants {
+		inTracks = inTracks || participant.TrackID != nil
+	}
+	if !inTracks {
+		return tracks, nil
+	}
+
+	var list []models.Track
+	if err := repo.ListTracks(&list, hackathonID); err != nil {
+		return nil, err
+	}
+	for _, track := range list {
+		tracks[track.ID] = track
+	}
+	return tracks, nil
+}
+
+// rankedTrack reads the track a ranking is asked for from the track_id query
+// parameter. It answers with not found, and returns false, when the hackathon has
+// no such track; an empty track means the overall ranking.
+func rankedTrack(c *gin.Context, hackathonID uint) (models.Track, bool) {
+	trackID := c.Query("track_id")
+	if trackID == "" {
+		return models.Track{}, true
+	}
+	track, err := repo.TrackFromID(trackID, hackathonID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "This track doesn't exist"})
+		return track, false
+	}
+	return track, true
+}
+
+// judgeScore works out the score the logged in judge gives a submission, and aborts
+// the request unless they may judge the hackathon right now
+func judgeScore(c *gin.Context) (models.Hackathon, models.JudgeScore, bool) {
+	var req utils.JudgeSubmissionRequest
 
 	hackathon_id := c.Param("hackathon_id")
-	username := c.Param("username")
 
-	c.BindJSON(&participant)
+	c.BindJSON(&req)
 
 	//authorization
 	authPayload := c.MustGet(utils.AuthorizationPayloadKey).(*token.Payload)
@@ -26,55 +90,112 @@ func JudgeSubmission(c *gin.Context) {
 	LoggedInUser, err1 := repo.GetProfileByUsername(authPayload.Username)
 	if err1 != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "user doesn't exist"})
-		return
+		return models.Hackathon{}, models.JudgeScore{}, false
 	}
 
 	hackathon, err2 := repo.HackathonFromHackathonID(hackathon_id)
 	if err2 != nil {
 		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "This hackathon id doesnt exist"})
-		return
+		return hackathon, models.JudgeScore{}, false
 	}
 
-	if hackathon.OrganiserID != int(LoggedInUser.ID) {
+	judge, err := canJudge(hackathon, LoggedInUser)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "service unavailable"})
+		return hackathon, models.JudgeScore{}, false
+	}
+	if !judge {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Not authorised to judge participant"})
-		return
+		return hackathon, models.JudgeScore{}, false
 	}
 
-	//judge can give score only after ending of hackathon
-	if !time.Now().After(hackathon.EndingTime) {
+	//judge can give score only while the hackathon is being judged
+	if err := lifecycle.Guard(hackathon, lifecycle.ActionJudge, time.Now()); err == lifecycle.ErrTooLate {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Judging period is over!"})
+		return hackathon, models.JudgeScore{}, false
+	} else if err != nil {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Can't judge right now. Try again once the hackathon ends!!"})
-		return
+		return hackathon, models.JudgeScore{}, false
 	}
 
-	//judge can give score only after ending of hackathon
-	if !time.Now().After(hackathon.EndingTime) {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Can't judge right now. Try again once the hackathon ends!!"})
-		return
+	// hackathons with a rubric are scored per criterion and the total is worked out here
+	var criteria []models.Criterion
+	if err := repo.ListCriteria(&criteria, hackathon.ID); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "service unavailable"})
+		return hackathon, models.JudgeScore{}, false
 	}
 
-	err := repo.JudgeSubmisson(&participant, hackathon_id, username)
+	judged := models.JudgeScore{
+		JudgeID:  LoggedInUser.ID,
+		Score:    req.Score,
+		Feedback: strings.TrimSpace(req.Feedback),
+		Notes:    strings.TrimSpace(req.Notes),
+	}
+	if len(criteria) > 0 {
+		breakdown, err := rubricBreakdown(criteria, req.Criteria)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return hackathon, judged, false
+		}
+		judged.Criteria = breakdown
+		judged.Score = scoring.Weighted(criteria, breakdown)
+	} else if req.Score < 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Score can't be negative"})
+		return hackathon, judged, false
+	}
+	return hackathon, judged, true
+}
 
-	if err != nil {
+// respondJudged answers with the outcome of recording the score of a judge
+func respondJudged(c *gin.Context, judged models.JudgeScore, err error) {
+	if err == repo.ErrNotAssigned {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "This submission isn't in your review queue"})
+	} else if err == repo.ErrConflicted {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "You have a conflict of interest with this submission"})
+	} else if err != nil {
 		c.AbortWithStatusJSON(500, gin.H{
 			"message": "service unavailable",
 		})
 	} else {
 		c.JSON(http.StatusOK, gin.H{
 			"message": "submission judged successfully!",
+			"score":   judged.Score,
 		})
 	}
 }
 
+func JudgeSubmission(c *gin.Context) {
+	username := c.Param("username")
+
+	hackathon, judged, ok := judgeScore(c)
+	if !ok {
+		return
+	}
+
+	if blinded(hackathon) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "This hackathon is judged blind. Judge submissions by entry ID"})
+		return
+	}
+
+	err := repo.JudgeSubmisson(&judged, hackathon, username)
+	respondJudged(c, judged, err)
+}
+
 func GetSubmissions(c *gin.Context) {
 	hackathon_id := c.Params.ByName("hackathon_id")
 
 	var participants []models.Participant
 
+	// a team submits once, under the username of its captain
+	// in blind mode only the entry ID tells submissions apart
 	type submissions struct {
-		HackathonID int `json:"hackathon_id"`
-		Username string `json:"username"`
-		CodeUrl  string `json:"code_url"`
-		DemoUrl  string `json:"demo_url"`
+		HackathonID int      `json:"hackathon_id"`
+		EntryID     string   `json:"entry_id"`
+		Username    string   `json:"username,omitempty"`
+		CodeUrl     string   `json:"code_url"`
+		DemoUrl     string   `json:"demo_url"`
+		Team        string   `json:"team,omitempty"`
+		Members     []string `json:"members,omitempty"`
 	}
 
 	//authorization
@@ -91,18 +212,29 @@ func GetSubmissions(c *gin.Context) {
 		return
 	}
 
-	if hackathon.OrganiserID != int(LoggedInUser.ID) {
+	// judges need the submissions to score them
+	judge, err := canJudge(hackathon, LoggedInUser)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "request unsuccessful!"})
+		return
+	}
+	if !judge {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Not authorised to view submissions"})
 		return
 	}
 
 	//the organiser can get submissions only after the hackathon ends
-	if !time.Now().After(hackathon.EndingTime) {
+	if lifecycle.Guard(hackathon, lifecycle.ActionViewSubmissions, time.Now()) != nil {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Can't view submissions right now. Try again once the hackathon ends!!"})
 		return
 	}
 
-	err := repo.GetSubmissions(&participants, hackathon_id)
+	// judges assigned a review queue only see the submissions in it
+	if hackathon.ReviewsPerSubmission > 0 && hackathon.OrganiserID != int(LoggedInUser.ID) {
+		err = repo.AssignedSubmissions(&participants, hackathon.ID, LoggedInUser.ID)
+	} else {
+		err = repo.GetSubmissions(&participants, hackathon_id)
+	}
 	if err != nil {
 		c.AbortWithStatusJSON(500, gin.H{
 			"message": "request unsuccessful!",
@@ -111,14 +243,55 @@ func GetSubmissions(c *gin.Context) {
 	} else {
 		obj := make([]submissions, 0)
 
+		teams, err := hackathonTeams(participants, hackathon.ID)
+		if err != nil {
+			c.AbortWithStatus(500)
+			return
+		}
+		// judges see the submissions frozen when the hackathon ended
+		final, err := finalSubmissions(&hackathon)
+		if err != nil {
+			c.AbortWithStatus(500)
+			return
+		}
+		teamSubmissions := make(map[uint]int)
+		blind := blinded(hackathon)
+
 		for i := 0; i < len(participants); i++ {
+			snapshot := final[repo.EntryOf(participants[i])]
+			entry := entryID(hackathon.ID, repo.EntryOf(participants[i]))
+			if participants[i].TeamID == nil {
+				temp := submissions{participants[i].HackathonId, entry, "", snapshot.CodeUrl, snapshot.DemoUrl, "", nil}
+				obj = append(obj, temp)
+			} else if _, ok := teamSubmissions[*participants[i].TeamID]; !ok {
+				team := teams[*participants[i].TeamID]
+				teamSubmissions[team.ID] = len(obj)
+				name := team.Name
+				if blind {
+					name = ""
+				}
+				obj = append(obj, submissions{participants[i].HackathonId, entry, "", snapshot.CodeUrl, snapshot.DemoUrl, name, nil})
+			}
+			if blind {
+				continue
+			}
+
 			user, err := repo.UserFromUserID(participants[i].UserId)
 			if err != nil {
 				c.AbortWithStatus(500)
 				return
 			}
-			temp := submissions{participants[i].HackathonId, user.Username, participants[i].CodeUrl, participants[i].DemoUrl}
-			obj = append(obj, temp)
+			if participants[i].TeamID == nil {
+				obj[len(obj)-1].Username = user.Username
+				continue
+			}
+
+			team := teams[*participants[i].TeamID]
+			index := teamSubmissions[team.ID]
+			if user.ID == team.CaptainID {
+				obj[index].Username = user.Username
+			}
+			obj[index].Members = append(obj[index].Members, user.Username)
 		}
 
 		//responding back
@@ -137,15 +310,20 @@ func GetSubmissions(c *gin.Context) {
 func GetLeaderboard(c *gin.Context) {
 
 	type results struct {
-		UserName string `json:"user_name"`
-		FullName string `json:"full_name"`
-		Score    int    `json:"score"`
+		Rank     int     `json:"rank"`
+		UserName string  `json:"user_name"`
+		FullName string  `json:"full_name"`
+		Score    float64 `json:"score"`
+		Judges   int     `json:"judges"`
+		Team     string  `json:"team,omitempty"`
+		Track    string  `json:"track,omitempty"`
 	}
 
 	hackathon_id := c.Params.ByName("hackathon_id")
 	var participants []models.Participant
 
-	err := repo.GetLeaderboard(&participants, hackathon_id)
+	// the leaderboard of a single track is asked for with its track_id
+	err := repo.GetLeaderboard(&participants, hackathon_id, c.Query("track_id"))
 
 	if err != nil {
 		c.AbortWithStatusJSON(500, gin.H{"message": "service unavailable"})
@@ -159,7 +337,7 @@ func GetLeaderboard(c *gin.Context) {
 			if err != nil {
 				c.AbortWithStatus(500)
 			}
-			temp := results{user.Username, user.FullName, participants[i].Score}
+			temp := results{0, user.Username, user.FullName, participants[i].Score, participants[i].JudgeCount, "", ""}
 			obj = append(obj, temp)
 		}
 
@@ -169,10 +347,58 @@ func GetLeaderboard(c *gin.Context) {
 			return
 		}
 
-		if time.Now().After(hackathon.ResultTime) {
-			c.JSON(http.StatusOK, gin.H{
-				"leaderboard": obj,
-			})
+		track, ok := rankedTrack(c, hackathon.ID)
+		if !ok {
+			return
+		}
+
+		// members of a team are listed with its score
+		teams, err := hackathonTeams(participants, hackathon.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+			return
+		}
+		tracks, err := hackathonTracks(participants, hackathon.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+			return
+		}
+		for i := 0; i < len(participants); i++ {
+			if participants[i].TeamID != nil {
+				obj[i].Team = teams[*participants[i].TeamID].Name
+			}
+			if participants[i].TrackID != nil {
+				obj[i].Track = tracks[*participants[i].TrackID].Name
+			}
+		}
+
+		if lifecycle.Guard(hackathon, lifecycle.ActionViewResults, time.Now()) == nil {
+			// members of a team are listed together, at the rank of their team
+			standings, err := repo.RankParticipants(hackathon, participants)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+				return
+			}
+			rows := make(map[int]results)
+			for i := range participants {
+				rows[participants[i].UserId] = obj[i]
+			}
+			leaderboard := make([]results, 0, len(obj))
+			for _, standing := range standings {
+				for _, member := range standing.Members {
+					row := rows[member.UserId]
+					row.Rank = standing.Rank
+					leaderboard = append(leaderboard, row)
+				}
+			}
+
+			response := gin.H{
+				"leaderboard": leaderboard,
+			}
+			if track.ID != 0 {
+				response["track"] = track.Name
+			}
+			c.JSON(http.StatusOK, response)
 		} else {
 			c.JSON(http.StatusOK, gin.H{
 				"message": "results not declared yet!!",
@@ -206,49 +432,181 @@ func PrizeDistribution(c *gin.Context) {
 
 	//time constraint check
 
-	if !time.Now().After(hackathon.ResultTime) {
+	if lifecycle.Guard(hackathon, lifecycle.ActionDistributePrizes, time.Now()) != nil {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Results aren't declared yet!"})
 		return
 	}
 
-	var winners []models.Participant
-	err := repo.GetWinners(&winners, hackathon_id)
-	if err != nil {
-		fmt.Print(err)
+	var prizes []models.Prize
+	if err := repo.ListPrizes(&prizes, hackathon.ID); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
 		return
 	}
+	if len(prizes) == 0 {
+		prizes = defaultPrizes
+	}
 
-	var winners_user []models.User
-	var winners_usernames []string
-
-	for i := 0; i < len(winners); i++ {
-		temp, err := repo.UserFromUserID(winners[i].UserId)
-		if err != nil {
-			fmt.Println(err)
+	// every ranking with prizes is ranked as far down as its last prize goes
+	places := make(map[string]int)
+	var ranked []string
+	withTracks := false
+	for _, prize := range prizes {
+		withTracks = withTracks || prize.TrackID != nil
+		if prize.IsSpecial() {
+			continue
 		}
-
-		winners_user = append(winners_user, temp)
-		winners_usernames = append(winners_usernames, temp.Username)
+		key := trackKey(prize.TrackID)
+		if _, ok := places[key]; !ok {
+			ranked = append(ranked, key)
+		}
+		if prize.RankTo > places[key] {
+			places[key] = prize.RankTo
+		}
+	}
+	rankings := make(map[string][]scoring.Standing)
+	for _, track := range ranked {
+		var entries []scoring.Standing
+		if err := repo.GetWinners(&entries, hackathon, places[track], track); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+			return
+		}
+		rankings[track] = entries
 	}
 
-	message := "winners "
+	// the entries winning each prize, special awards going to the entry of their awardee
+	// and the People's Choice award to the entries with the most votes. Entries sharing
+	// a rank share the prizes for it, and the ranks they skip get none.
+	winners := make([][][]models.Participant, len(prizes))
+	unclaimed := make([]bool, len(prizes))
+	var all []models.Participant
+	for i, prize := range prizes {
+		if prize.PeoplesChoice {
+			entries, err := peoplesChoiceWinners(hackathon)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+				return
+			}
+			winners[i] = entries
+		} else if prize.IsSpecial() {
+			if prize.AwardeeID == nil {
+				continue
+			}
+			entry, err := awardeeEntry(hackathon.ID, *prize.AwardeeID)
+			if err == gorm.ErrRecordNotFound {
+				// the awardee withdrew, which leaves the award to nobody
+				unclaimed[i] = true
+				continue
+			}
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+				return
+			}
+			winners[i] = [][]models.Participant{entry}
+		} else {
+			for _, standing := range rankings[trackKey(prize.TrackID)] {
+				if standing.Rank >= prize.RankFrom && standing.Rank <= prize.RankTo {
+					winners[i] = append(winners[i], standing.Members)
+				}
+			}
+		}
+		for _, entry := range winners[i] {
+			all = append(all, entry...)
+		}
+	}
 
-	for i := 0; i < len(winners_user); i++ {
-		err := utils.Notify(winners_user[i].Email, "Prize distribution", "Congratulations! You’ve won the hackathon. Please follow this link to get your cash reward.")
-		if err != nil {
+	teams, err := hackathonTeams(all, hackathon.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+		return
+	}
+	tracks := make(map[uint]models.Track)
+	if withTracks {
+		var list []models.Track
+		if err := repo.ListTracks(&list, hackathon.ID); err != nil {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
 			return
-		} else {
-			message = message + strconv.Itoa(i+1) + ", "
 		}
+		for _, track := range list {
+			tracks[track.ID] = track
+		}
+	}
+
+	type award struct {
+		PrizeID   uint     `json:"prize_id"`
+		Title     string   `json:"title"`
+		Track     string   `json:"track,omitempty"`
+		Winners   []string `json:"winners"`
+		Shared    bool     `json:"shared,omitempty"`    // more entries tied for it than it has places
+		Unclaimed bool     `json:"unclaimed,omitempty"` // its awardee no longer takes part
+	}
+	awards := make([]award, 0, len(prizes))
+	notified := 0
+
+	for i, prize := range prizes {
+		shared := len(winners[i]) > prize.RankTo-prize.RankFrom+1
+		if prize.IsSpecial() {
+			shared = prize.PeoplesChoice && len(winners[i]) > 1
+		}
+		temp := award{prize.ID, prize.Title, "", []string{}, shared, unclaimed[i]}
+		if prize.TrackID != nil {
+			temp.Track = tracks[*prize.TrackID].Name
+		}
+
+		for _, entry := range winners[i] {
+			for _, participant := range entry {
+				user, err := repo.UserFromUserID(participant.UserId)
+				if err != nil {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+					return
+				}
+				team := ""
+				if participant.TeamID != nil {
+					team = teams[*participant.TeamID].Name
+				}
+				if err := utils.Notify(user.Email, "Prize distribution", prizeMessage(hackathon, prize, temp.Track, team)); err != nil {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+					return
+				}
+				temp.Winners = append(temp.Winners, user.Username)
+				notified++
+			}
+		}
+		awards = append(awards, temp)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"winners": winners_usernames,
-		"status":  message + "notified successfully!!",
+		"awards": awards,
+		"status": strconv.Itoa(notified) + " winners notified successfully!!",
 	})
 }
 
+// trackKey is the track ID used by the ranking of a prize, empty for the overall ranking
+func trackKey(trackID *uint) string {
+	if trackID == nil {
+		return ""
+	}
+	return strconv.Itoa(int(*trackID))
+}
+
+// awardeeEntry loads the entry the winner of a special award competes in, which is
+// their whole team when they are in one. It fails with gorm.ErrRecordNotFound once
+// the winner no longer takes part in the hackathon.
+func awardeeEntry(hackathonID uint, userID uint) ([]models.Participant, error) {
+	participant, err := repo.ParticipantFromUserID(strconv.Itoa(int(hackathonID)), strconv.Itoa(int(userID)))
+	if err != nil {
+		return nil, err
+	}
+	if participant.UserId == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if participant.TeamID == nil {
+		return []models.Participant{participant}, nil
+	}
+	var members []models.Participant
+	err = repo.TeamParticipants(&members, *participant.TeamID)
+	return members, err
+}
+
 func GetSubmissionOfParticipant(c *gin.Context) {
 
 	hackathon_id := c.Params.ByName("hackathon_id")
@@ -275,11 +633,16 @@ func GetSubmissionOfParticipant(c *gin.Context) {
 	}
 
 	//time constraint
-	if !time.Now().After(hackathon.EndingTime) {
+	if lifecycle.Guard(hackathon, lifecycle.ActionViewSubmissions, time.Now()) != nil {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Can't view the submission right now. Try again once the hackathon ends!!"})
 		return
 	}
 
+	if blinded(hackathon) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "This hackathon is judged blind. Look submissions up by entry ID"})
+		return
+	}
+
 	user, err := repo.GetProfileByUsername(username)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Username doesn't exist"})
@@ -290,12 +653,35 @@ func GetSubmissionOfParticipant(c *gin.Context) {
 
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "participant with username doesn't exist"})
+		return
+	}
+
+	final, err := finalSubmissions(&hackathon)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+		return
+	}
+	snapshot := final[repo.EntryOf(participant)]
+
+	if participant.TeamID != nil {
+		team, err := repo.TeamFromID(strconv.Itoa(int(*participant.TeamID)), hackathon.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "server unavailable"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusOK, gin.H{
+			"hackathon_id": participant.HackathonId,
+			"user name":    username,
+			"team":         team.Name,
+			"code_url":     snapshot.CodeUrl,
+			"demo_url":     snapshot.DemoUrl,
+		})
 	} else {
 		c.AbortWithStatusJSON(http.StatusOK, gin.H{
 			"hackathon_id": participant.HackathonId,
 			"user name": username,
-			"code_url":  participant.CodeUrl,
-			"demo_url":  participant.DemoUrl,
+			"code_url":  snapshot.CodeUrl,
+			"demo_url":  snapshot.DemoUrl,
 		})
 	}
 }
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
@@ -12,6 +13,7 @@ import (
 	"regexp"
 	"testing"
 	"time"
+	"win-a-thon/assignment"
 	"win-a-thon/database"
 	"win-a-thon/models"
 	"win-a-thon/token"
@@ -43,7 +45,7 @@ func TestGetLeaderboard(t *testing.T) {
 
 	// For repo.GetLeaderboard
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `participants` WHERE hackathon_id = ? ORDER BY score desc")).WithArgs().WillReturnRows(participantMockRows)
+		"SELECT * FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL ORDER BY score desc")).WithArgs().WillReturnRows(participantMockRows)
 
 	// For repo.UserFromUserID
 	var created_at = time.Now()
@@ -55,7 +57,7 @@ func TestGetLeaderboard(t *testing.T) {
 
 	// For repo.HackathonFromHackathonID
 	hackathonMockRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "titles", "starting_time", "ending_time", "res" +
-		"ult_time", "organisation_name", "organisation_id", "description", "admin_approved"}).AddRow(1, created_at, created_at, created_at, "Test Hackathon", created_at, created_at, created_at, "Winathon", 1, "It is a test Hackathon", true)
+		"ult_time", "organisation_name", "organisation_id", "description", "admin_approved", "submissions_frozen_at"}).AddRow(1, created_at, created_at, created_at, "Test Hackathon", created_at, created_at, created_at, "Winathon", 1, "It is a test Hackathon", true, created_at)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).WithArgs().WillReturnRows(hackathonMockRows)
@@ -65,7 +67,7 @@ func TestGetLeaderboard(t *testing.T) {
 		t.Fatal("Wrong error code")
 	}
 
-	expected := "{\"leaderboard\":[{\"user_name\":\"name\",\"full_name\":\"fullname\",\"score\":10}]}"
+	expected := "{\"leaderboard\":[{\"rank\":1,\"user_name\":\"name\",\"full_name\":\"fullname\",\"score\":10,\"judges\":0}]}"
 
 	ctx.Writer.Flush()
 
@@ -129,7 +131,7 @@ func TestGetSubmissions(t *testing.T) {
 
 	// For repo.HackathonFromHackathonID
 	hackathonMockRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "titles", "starting_time", "ending_time", "res" +
-		"ult_time", "organisation_name", "organisation_id", "description", "admin_approved"}).AddRow(1, created_at, created_at, created_at, "Test Hackathon", created_at, created_at, created_at, "Winathon", 1, "It is a test Hackathon", true)
+		"ult_time", "organisation_name", "organisation_id", "description", "admin_approved", "submissions_frozen_at"}).AddRow(1, created_at, created_at, created_at, "Test Hackathon", created_at, created_at, created_at, "Winathon", 1, "It is a test Hackathon", true, created_at)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).WithArgs().WillReturnRows(hackathonMockRows)
@@ -140,6 +142,7 @@ func TestGetSubmissions(t *testing.T) {
 
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT * FROM `participants` WHERE hackathon_id = ? ")).WithArgs().WillReturnRows(participantMockRows)
+	expectFinalSubmissions(mock, sqlmock.NewRows([]string{"hackathon_id", "user_id", "demo_url", "code_url"}).AddRow(1, 1, "abc", "abc"))
 
 	// For repo.UserFromUserID
 	var user_id = 1
@@ -154,7 +157,7 @@ func TestGetSubmissions(t *testing.T) {
 		t.Fatal("Wrong error code")
 	}
 
-	expected := `{"submissions":[{"hackathon_id":1,"username":"name","code_url":"abc","demo_url":"abc"}],"total_submissions":1}`
+	expected := `{"submissions":[{"hackathon_id":1,"entry_id":"` + entryID(1, assignment.Entry{UserID: 1}) + `","username":"name","code_url":"abc","demo_url":"abc"}],"total_submissions":1}`
 	ctx.Writer.Flush()
 
 	assert.Equal(t, w.Body.String(), expected)
@@ -340,6 +343,10 @@ func TestGetSubmissionsUnauthorized(t *testing.T) {
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).WithArgs().WillReturnRows(hackathonMockRows)
 
+	// nor is the user one of its judges
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `judges` WHERE hackathon_id = ? AND user_id = ?")).WithArgs(1, 0).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
 	GetSubmissions(ctx)
 	if ctx.Writer.Status() != http.StatusUnauthorized {
 		t.Fatal("Wrong error code")
@@ -408,7 +415,7 @@ func TestGetSubmissionsTimeConstraint(t *testing.T) {
 		WillReturnRows(userRows)
 
 	// For repo.HackathonFromHackathonID
-	end_time := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	end_time := time.Now().Add(24 * time.Hour)
 
 	hackathonMockRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "titles", "starting_time", "ending_time", "res" +
 		"ult_time", "organisation_name", "organiser_id", "description", "admin_approved"}).AddRow(1, created_at, created_at, created_at, "Test Hackathon", created_at, end_time, end_time, "Winathon", 0, "It is a test Hackathon", true)
@@ -486,7 +493,7 @@ func TestGetSubmissionsUser(t *testing.T) {
 
 	// For repo.HackathonFromHackathonID
 	hackathonMockRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "titles", "starting_time", "ending_time", "res" +
-		"ult_time", "organisation_name", "organisation_id", "description", "admin_approved"}).AddRow(1, created_at, created_at, created_at, "Test Hackathon", created_at, created_at, created_at, "Winathon", 1, "It is a test Hackathon", true)
+		"ult_time", "organisation_name", "organisation_id", "description", "admin_approved", "submissions_frozen_at"}).AddRow(1, created_at, created_at, created_at, "Test Hackathon", created_at, created_at, created_at, "Winathon", 1, "It is a test Hackathon", true, created_at)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).WithArgs().WillReturnRows(hackathonMockRows)
@@ -497,6 +504,7 @@ func TestGetSubmissionsUser(t *testing.T) {
 
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT * FROM `participants` WHERE hackathon_id = ? ")).WithArgs("1").WillReturnRows(participantMockRows)
+	expectFinalSubmissions(mock, sqlmock.NewRows([]string{"hackathon_id", "user_id", "demo_url", "code_url"}).AddRow(1, 2, "abc", "abc"))
 
 	// For repo.UserFromUserID
 	var user_id = 2
@@ -571,7 +579,7 @@ func TestGetSubmissionOfParticipant(t *testing.T) {
 
 	// For repo.HackathonFromHackathonID
 	hackathonMockRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "titles", "starting_time", "ending_time", "res" +
-		"ult_time", "organisation_name", "organisation_id", "description", "admin_approved"}).AddRow(1, created_at, created_at, created_at, "Test Hackathon", created_at, created_at, created_at, "Winathon", 1, "It is a test Hackathon", true)
+		"ult_time", "organisation_name", "organisation_id", "description", "admin_approved", "submissions_frozen_at"}).AddRow(1, created_at, created_at, created_at, "Test Hackathon", created_at, created_at, created_at, "Winathon", 1, "It is a test Hackathon", true, created_at)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).WithArgs().WillReturnRows(hackathonMockRows)
@@ -590,7 +598,8 @@ func TestGetSubmissionOfParticipant(t *testing.T) {
 		AddRow(1, 1, "abc", "abc", 10)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `participants` WHERE hackathon_id = ? AND user_id = ?")).WithArgs().WillReturnRows(participantMockRows)
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL")).WithArgs().WillReturnRows(participantMockRows)
+	expectFinalSubmissions(mock, sqlmock.NewRows([]string{"hackathon_id", "user_id", "demo_url", "code_url"}).AddRow(1, 1, "abc", "abc"))
 
 	GetSubmissionOfParticipant(ctx)
 
@@ -866,7 +875,7 @@ func TestGetSubmissionOfParticipantTimeConstraint(t *testing.T) {
 		WillReturnRows(userRows)
 
 	//For repo.HackathonFromHackathonID
-	end_time := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	end_time := time.Now().Add(24 * time.Hour)
 
 	hackathonMockRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "titles", "starting_time", "ending_time", "res" +
 		"ult_time", "organisation_name", "organisation_id", "description", "admin_approved"}).AddRow(1, created_at, created_at, created_at, "Test Hackathon", created_at, end_time, end_time, "Winathon", 1, "It is a test Hackathon", true)
@@ -946,7 +955,7 @@ func TestGetSubmissionOfParticipantUserNotFound2(t *testing.T) {
 
 	//For repo.HackathonFromHackathonID
 	hackathonMockRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "titles", "starting_time", "ending_time", "res" +
-		"ult_time", "organisation_name", "organisation_id", "description", "admin_approved"}).AddRow(1, created_at, created_at, created_at, "Test Hackathon", created_at, created_at, created_at, "Winathon", 1, "It is a test Hackathon", true)
+		"ult_time", "organisation_name", "organisation_id", "description", "admin_approved", "submissions_frozen_at"}).AddRow(1, created_at, created_at, created_at, "Test Hackathon", created_at, created_at, created_at, "Winathon", 1, "It is a test Hackathon", true, created_at)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).WithArgs().WillReturnRows(hackathonMockRows)
@@ -1029,12 +1038,19 @@ func TestPrizeDistribution(t *testing.T) {
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).WithArgs().WillReturnRows(hackathonMockRows)
 
+	// For repo.ListPrizes
+	prizeMockRows := sqlmock.NewRows([]string{"id", "hackathon_id", "title", "rank_from", "rank_to", "amount", "currency"}).
+		AddRow(1, 1, "First prize", 1, 1, 500, "USD")
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `prizes` WHERE hackathon_id = ? ORDER BY rank_from = 0, rank_from, id")).WithArgs(1).WillReturnRows(prizeMockRows)
+
 	// For repo.GetWinners
 	participantMockRows := sqlmock.NewRows([]string{"hackathon_id", "user_id", "demo_url", "code_url", "score"}).
 		AddRow(1, 1, "abc", "abc", 10)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `participants` WHERE hackathon_id = ?  ORDER BY score desc LIMIT 3")).WithArgs("1").WillReturnRows(participantMockRows)
+		"SELECT * FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL ORDER BY score desc")).WithArgs(1).WillReturnRows(participantMockRows)
 
 	// For repo.UserFromUserID
 	var user_id = 1
@@ -1060,6 +1076,118 @@ func TestPrizeDistribution(t *testing.T) {
 	}
 }
 
+func TestPrizeDistributionWithoutPrizes(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	ctx.Params = []gin.Param{{Key: "hackathon_id", Value: "1"}}
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "name",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	userRows := sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "name")
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(userRows)
+
+	ended := time.Now().Add(-time.Hour)
+	hackathonMockRows := sqlmock.NewRows([]string{"id", "title", "starting_time", "ending_time", "result_time", "organiser_id", "status"}).
+		AddRow(1, "Test Hackathon", ended, ended, ended, 1, "results_published")
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).WithArgs("1").WillReturnRows(hackathonMockRows)
+
+	// no prizes defined, so the top three are ranked
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `prizes` WHERE hackathon_id = ? ORDER BY rank_from = 0, rank_from, id")).WithArgs(1).WillReturnRows(sqlmock.NewRows(nil))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL ORDER BY score desc")).WithArgs(1).WillReturnRows(sqlmock.NewRows(nil))
+
+	PrizeDistribution(ctx)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Awards []struct {
+			Title string `json:"title"`
+		} `json:"awards"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, len(response.Awards))
+	assert.Equal(t, "1st place", response.Awards[0].Title)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestPrizeDistributionAwardeeWithdrew(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	ctx.Params = []gin.Param{{Key: "hackathon_id", Value: "1"}}
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "name",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "name"))
+
+	ended := time.Now().Add(-time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "starting_time", "ending_time", "result_time", "organiser_id", "status"}).
+			AddRow(1, "Test Hackathon", ended, ended, ended, 1, "results_published"))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `prizes` WHERE hackathon_id = ? ORDER BY rank_from = 0, rank_from, id")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "title", "rank_from", "rank_to", "awardee_id"}).AddRow(4, 1, "Best design", 0, 0, 9))
+	// the awardee withdrew from the hackathon
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL")).
+		WithArgs("1", "9").
+		WillReturnRows(sqlmock.NewRows(nil))
+
+	PrizeDistribution(ctx)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"awards":[{"prize_id":4,"title":"Best design","winners":[],"unclaimed":true}],"status":"0 winners notified successfully!!"}`, w.Body.String())
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
 func TestPrizeDistributionBadRequest(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
@@ -1299,7 +1427,7 @@ func TestPrizeDistributionTimeConstraint(t *testing.T) {
 		WillReturnRows(userRows)
 
 	//hackathon from hackathon id
-	end_time := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	end_time := time.Now().Add(24 * time.Hour)
 
 	hackathonMockRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "titles", "starting_time", "ending_time", "res" +
 		"ult_time", "organisation_name", "organisation_id", "description", "admin_approved"}).AddRow("1", created_at, created_at, created_at, "Test Hackathon", created_at, end_time, end_time, "Winathon", "1", "It is a test Hackathon", "1")
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+)
+
+// defaultPrizes are handed out by hackathons that define no prizes of their own,
+// rewarding their top three entries as before prizes could be defined
+var defaultPrizes = []models.Prize{
+	{Title: "1st place", RankFrom: 1, RankTo: 1},
+	{Title: "2nd place", RankFrom: 2, RankTo: 2},
+	{Title: "3rd place", RankFrom: 3, RankTo: 3},
+}
+
+// validatePrize checks the fields of a prize an organiser defines
+func validatePrize(prize models.Prize) error {
+	if strings.TrimSpace(prize.Title) == "" {
+		return errors.New("Title cannot be empty")
+	}
+
+	if !prize.IsSpecial() && (prize.RankFrom < 1 || prize.RankTo < prize.RankFrom) {
+		return errors.New("Ranks should start at 1 and rank_to can't be before rank_from")
+	}
+
+	if prize.IsSpecial() && prize.RankTo != 0 {
+		return errors.New("Special awards have no rank")
+	}
+
+	if prize.PeoplesChoice && (!prize.IsSpecial() || prize.TrackID != nil) {
+		return errors.New("The People's Choice award has no rank or track")
+	}
+
+	if prize.Amount < 0 {
+		return errors.New("Amount can't be negative")
+	}
+
+	if prize.Amount > 0 && len(prize.Currency) != 3 {
+		return errors.New("Currency should be a 3 letter code")
+	}
+	return nil
+}
+
+// prizeMessage is the email telling a winner about their prize
+func prizeMessage(hackathon models.Hackathon, prize models.Prize, track string, team string) string {
+	message := "Congratulations! You have won " + prize.Title + " in hackathon " + hackathon.Title
+	if track != "" {
+		message += ", " + track + " track"
+	}
+	if team != "" {
+		message += ", with your team " + team
+	}
+	message += "."
+	if prize.Amount > 0 {
+		message += fmt.Sprintf(" The prize is %.2f %s.", prize.Amount, prize.Currency)
+	}
+	if prize.Description != "" {
+		message += " " + prize.Description
+	}
+	return message + " The organiser will get in touch with you about collecting it."
+}
+
+func ListPrizes(c *gin.Context) {
+	hackathon, err := repo.HackathonFromHackathonID(c.Params.ByName("hackathon_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This hackathon id doesnt exist"})
+		return
+	}
+
+	prizes := make([]models.Prize, 0)
+	if err := repo.ListPrizes(&prizes, hackathon.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"prizes": prizes})
+}
+
+func CreatePrize(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	if lifecycle.Guard(hackathon, lifecycle.ActionEdit, time.Now()) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Prizes of archived hackathons can't be changed"})
+		return
+	}
+
+	var prize models.Prize
+	if err := c.ShouldBindJSON(&prize); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "field names incorrect"})
+		return
+	}
+	prize.ID = 0
+	prize.HackathonID = hackathon.ID
+	prize.AwardeeID = nil
+	prize.Currency = strings.ToUpper(prize.Currency)
+
+	if err := validatePrize(prize); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if prize.PeoplesChoice && hackathon.VotingClosesAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Set when voting closes before adding a People's Choice award"})
+		return
+	}
+
+	if prize.TrackID != nil {
+		if _, ok := pickTrack(c, *prize.TrackID, hackathon.ID); !ok {
+			return
+		}
+	}
+
+	if err := repo.CreatePrize(&prize); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "prize created successfully", "prize": prize})
+}
+
+func DeletePrize(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	if lifecycle.Guard(hackathon, lifecycle.ActionEdit, time.Now()) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Prizes of archived hackathons can't be changed"})
+		return
+	}
+
+	prize, err := repo.PrizeFromID(c.Params.ByName("prize_id"), hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This prize doesn't exist"})
+		return
+	}
+
+	if err := repo.DeletePrize(&prize); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "prize deleted successfully"})
+}
+
+// AwardPrize lets the organiser pick the participant who wins a special award
+func AwardPrize(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	if lifecycle.Guard(hackathon, lifecycle.ActionEdit, time.Now()) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Prizes of archived hackathons can't be changed"})
+		return
+	}
+
+	prize, err := repo.PrizeFromID(c.Params.ByName("prize_id"), hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This prize doesn't exist"})
+		return
+	}
+	if !prize.IsSpecial() {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Ranked prizes are awarded by the leaderboard"})
+		return
+	}
+	if prize.PeoplesChoice {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "The People's Choice award goes to the entry with the most votes"})
+		return
+	}
+
+	user, err := repo.GetProfileByUsername(c.Params.ByName("username"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Username doesn't exist"})
+		return
+	}
+	participant, err := repo.ParticipantFromUserID(strconv.Itoa(int(hackathon.ID)), strconv.Itoa(int(user.ID)))
+	if err != nil || participant.UserId != int(user.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "participant with username doesn't exist"})
+		return
+	}
+
+	if err := repo.AwardPrize(&prize, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "prize awarded successfully", "prize": prize, "username": user.Username})
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"win-a-thon/database"
+	"win-a-thon/models"
+	"win-a-thon/token"
+)
+
+func TestValidatePrize(t *testing.T) {
+	for _, tc := range []struct {
+		prize    models.Prize
+		expected string
+	}{
+		{models.Prize{Title: " ", RankFrom: 1, RankTo: 1}, "Title cannot be empty"},
+		{models.Prize{Title: "Runners up", RankFrom: 3, RankTo: 2}, "Ranks should start at 1 and rank_to can't be before rank_from"},
+		{models.Prize{Title: "Best design", RankTo: 2}, "Special awards have no rank"},
+		{models.Prize{Title: "First prize", RankFrom: 1, RankTo: 1, Amount: -5}, "Amount can't be negative"},
+		{models.Prize{Title: "First prize", RankFrom: 1, RankTo: 1, Amount: 500, Currency: "DOLLARS"}, "Currency should be a 3 letter code"},
+	} {
+		err := validatePrize(tc.prize)
+		if assert.Error(t, err) {
+			assert.Equal(t, tc.expected, err.Error())
+		}
+	}
+
+	assert.NoError(t, validatePrize(models.Prize{Title: "Best design", Description: "Swag box"}))
+	assert.NoError(t, validatePrize(models.Prize{Title: "First prize", RankFrom: 1, RankTo: 1, Amount: 500, Currency: "USD"}))
+}
+
+// organiserContext prepares a request by the organiser of an upcoming hackathon
+func organiserContext(t *testing.T, method string, path string, body string) (*gin.Context, *httptest.ResponseRecorder, sqlmock.Sqlmock) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "name",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	ctx.Params = []gin.Param{{Key: "hackathon_id", Value: "1"}}
+	ctx.Request, _ = http.NewRequest(method, path, strings.NewReader(body))
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(3, "name"))
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "starting_time", "ending_time", "result_time", "organiser_id", "admin_approved", "status"}).
+			AddRow(1, "Test Hackathon", now.Add(time.Hour), now.Add(2*time.Hour), now.Add(3*time.Hour), 3, true, "registration_open"))
+
+	return ctx, w, mock
+}
+
+func TestCreatePrizeBadRanks(t *testing.T) {
+	ctx, w, mock := organiserContext(t, "POST", "/hackathons/1/prizes", `{"title":"Runners up","rank_from":3,"rank_to":2}`)
+
+	CreatePrize(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Ranks should start at 1 and rank_to can't be before rank_from"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestAwardRankedPrize(t *testing.T) {
+	ctx, w, mock := organiserContext(t, "PATCH", "/hackathons/1/prizes/5/award/winner", "")
+	ctx.Params = append(ctx.Params, gin.Param{Key: "prize_id", Value: "5"}, gin.Param{Key: "username", Value: "winner"})
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `prizes` WHERE id = ? AND hackathon_id = ? ORDER BY `prizes`.`id` LIMIT 1")).
+		WithArgs("5", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "title", "rank_from", "rank_to"}).AddRow(5, 1, "First prize", 1, 1))
+
+	AwardPrize(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Ranked prizes are awarded by the leaderboard"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
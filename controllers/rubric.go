@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"net/http"
+	"strings"
+	"time"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+	"win-a-thon/utils"
+)
+
+// rubricBreakdown checks the points a judge gave against the rubric of the hackathon.
+// Every criterion has to be scored once, within its max points.
+func rubricBreakdown(criteria []models.Criterion, given []utils.CriterionPoints) ([]models.CriterionScore, error) {
+	rubric := make(map[uint]models.Criterion)
+	for _, criterion := range criteria {
+		rubric[criterion.ID] = criterion
+	}
+
+	breakdown := make([]models.CriterionScore, 0, len(given))
+	scored := make(map[uint]bool)
+	for _, points := range given {
+		criterion, ok := rubric[points.CriterionID]
+		if !ok {
+			return nil, fmt.Errorf("Criterion %d isn't part of the rubric", points.CriterionID)
+		}
+		if scored[criterion.ID] {
+			return nil, fmt.Errorf("%s is scored more than once", criterion.Name)
+		}
+		if points.Points < 0 || points.Points > criterion.MaxPoints {
+			return nil, fmt.Errorf("Points for %s should be between 0 and %g", criterion.Name, criterion.MaxPoints)
+		}
+		scored[criterion.ID] = true
+		breakdown = append(breakdown, models.CriterionScore{CriterionID: criterion.ID, Points: points.Points})
+	}
+
+	if len(breakdown) != len(criteria) {
+		return nil, errors.New("Score every criterion of the rubric")
+	}
+	return breakdown, nil
+}
+
+// rubricOpen answers with a bad request, and returns false, once the rubric of a
+// hackathon can no longer change because judging has started
+func rubricOpen(c *gin.Context, hackathon models.Hackathon) bool {
+	if lifecycle.Guard(hackathon, lifecycle.ActionEditRubric, time.Now()) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "The rubric can't be changed once judging has started"})
+		return false
+	}
+	return true
+}
+
+func GetRubric(c *gin.Context) {
+	hackathon, err := repo.HackathonFromHackathonID(c.Params.ByName("hackathon_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This hackathon id doesnt exist"})
+		return
+	}
+
+	criteria := make([]models.Criterion, 0)
+	if err := repo.ListCriteria(&criteria, hackathon.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"criteria": criteria})
+}
+
+func AddCriterion(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok || !rubricOpen(c, hackathon) {
+		return
+	}
+
+	var req utils.CreateCriterionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "field names incorrect"})
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "A criterion name is required"})
+		return
+	}
+	if req.MaxPoints <= 0 || req.Weight <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Max points and weight should be positive"})
+		return
+	}
+
+	criterion := models.Criterion{
+		HackathonID: hackathon.ID,
+		Name:        strings.TrimSpace(req.Name),
+		MaxPoints:   req.MaxPoints,
+		Weight:      req.Weight,
+	}
+	if err := repo.CreateCriterion(&criterion); err != nil {
+		if err, ok := err.(*mysql.MySQLError); ok && err.Number == utils.DuplicateRecordErrorCode {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Criterion already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "criterion added successfully", "criterion": criterion})
+}
+
+func DeleteCriterion(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok || !rubricOpen(c, hackathon) {
+		return
+	}
+
+	criterion, err := repo.CriterionFromID(c.Params.ByName("criterion_id"), hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This criterion doesn't exist"})
+		return
+	}
+
+	if err := repo.DeleteCriterion(&criterion); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "criterion deleted successfully"})
+}
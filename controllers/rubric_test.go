@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"win-a-thon/models"
+	"win-a-thon/utils"
+)
+
+func TestRubricBreakdown(t *testing.T) {
+	criteria := []models.Criterion{
+		{ID: 1, Name: "Innovation", MaxPoints: 10, Weight: 2},
+		{ID: 2, Name: "Execution", MaxPoints: 5, Weight: 1},
+	}
+
+	for _, tc := range []struct {
+		given    []utils.CriterionPoints
+		expected string
+	}{
+		{[]utils.CriterionPoints{{CriterionID: 1, Points: 7}}, "Score every criterion of the rubric"},
+		{[]utils.CriterionPoints{{CriterionID: 1, Points: 7}, {CriterionID: 3, Points: 1}}, "Criterion 3 isn't part of the rubric"},
+		{[]utils.CriterionPoints{{CriterionID: 1, Points: 7}, {CriterionID: 1, Points: 8}}, "Innovation is scored more than once"},
+		{[]utils.CriterionPoints{{CriterionID: 1, Points: 7}, {CriterionID: 2, Points: 6}}, "Points for Execution should be between 0 and 5"},
+	} {
+		_, err := rubricBreakdown(criteria, tc.given)
+		if assert.Error(t, err) {
+			assert.Equal(t, tc.expected, err.Error())
+		}
+	}
+
+	breakdown, err := rubricBreakdown(criteria, []utils.CriterionPoints{{CriterionID: 2, Points: 4.5}, {CriterionID: 1, Points: 7}})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.CriterionScore{{CriterionID: 2, Points: 4.5}, {CriterionID: 1, Points: 7}}, breakdown)
+}
@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+	"win-a-thon/scoring"
+	"win-a-thon/utils"
+)
+
+// criterionPoints lists the points given for each criterion of the rubric. When
+// the scores of several judges are given, the points are averaged over them.
+func criterionPoints(criteria []models.Criterion, scores []models.JudgeScore) []gin.H {
+	sums := make(map[uint]float64)
+	counts := make(map[uint]int)
+	for _, score := range scores {
+		for _, points := range score.Criteria {
+			sums[points.CriterionID] += points.Points
+			counts[points.CriterionID]++
+		}
+	}
+
+	breakdown := make([]gin.H, 0, len(criteria))
+	for _, criterion := range criteria {
+		if counts[criterion.ID] == 0 {
+			continue
+		}
+		breakdown = append(breakdown, gin.H{
+			"criterion":  criterion.Name,
+			"max_points": criterion.MaxPoints,
+			"weight":     criterion.Weight,
+			"points":     sums[criterion.ID] / float64(counts[criterion.ID]),
+		})
+	}
+	return breakdown
+}
+
+// GetScores shows the organiser every score the judges gave, as given and normalized,
+// with the points behind them when the hackathon has a rubric
+func GetScores(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	var criteria []models.Criterion
+	var scores []models.JudgeScore
+	var participants []models.Participant
+	if repo.ListCriteria(&criteria, hackathon.ID) != nil ||
+		repo.HackathonScores(&scores, hackathon.ID) != nil ||
+		repo.GetSubmissions(&participants, c.Params.ByName("hackathon_id")) != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	teams, err := hackathonTeams(participants, hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	type judgeScore struct {
+		Judge      string  `json:"judge"`
+		Score      float64 `json:"score"`
+		Normalized float64 `json:"normalized"`
+		Criteria   []gin.H `json:"criteria,omitempty"`
+		Feedback   string  `json:"feedback,omitempty"`
+		Notes      string  `json:"notes,omitempty"`
+	}
+	type entry struct {
+		Username string       `json:"username"`
+		Team     string       `json:"team,omitempty"`
+		Score    float64      `json:"score"`
+		RawScore float64      `json:"raw_score"`
+		Judges   []judgeScore `json:"judges"`
+	}
+	type entryKey struct{ userID, teamID uint }
+
+	// entries are listed like submissions, a team under the username of its captain
+	entries := make([]entry, 0)
+	index := make(map[entryKey]int)
+	for _, participant := range participants {
+		user, err := repo.UserFromUserID(participant.UserId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return
+		}
+
+		key := entryKey{user.ID, 0}
+		temp := entry{user.Username, "", participant.Score, participant.RawScore, []judgeScore{}}
+		if participant.TeamID != nil {
+			team := teams[*participant.TeamID]
+			key = entryKey{0, team.ID}
+			if i, ok := index[key]; ok {
+				if user.ID == team.CaptainID {
+					entries[i].Username = user.Username
+				}
+				continue
+			}
+			temp.Team = team.Name
+			if user.ID != team.CaptainID {
+				temp.Username = ""
+			}
+		}
+		index[key] = len(entries)
+		entries = append(entries, temp)
+	}
+
+	normalized := scoring.Normalize(hackathon.ScoreNormalization, scores)
+	judges := make(map[uint]string)
+	for n, score := range scores {
+		i, ok := index[entryKey{score.UserID, score.TeamID}]
+		if !ok {
+			continue
+		}
+		if _, ok := judges[score.JudgeID]; !ok {
+			judge, err := repo.UserFromUserID(int(score.JudgeID))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+				return
+			}
+			judges[score.JudgeID] = judge.Username
+		}
+		entries[i].Judges = append(entries[i].Judges, judgeScore{
+			judges[score.JudgeID], score.Score, normalized[n], criterionPoints(criteria, []models.JudgeScore{score}), score.Feedback, score.Notes,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"aggregation":   hackathon.ScoreAggregation,
+		"normalization": hackathon.ScoreNormalization,
+		"criteria":      criteria,
+		"entries":       entries,
+	})
+}
+
+// GetJudgeReport shows the organiser how the scores of each judge are spread, next
+// to the spread of all the scores, to spot harsh and lenient judges
+func GetJudgeReport(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	var scores []models.JudgeScore
+	if err := repo.HackathonScores(&scores, hackathon.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	var judgeIDs []uint
+	all := make([]float64, len(scores))
+	given := make(map[uint][]float64)
+	for i, score := range scores {
+		if _, ok := given[score.JudgeID]; !ok {
+			judgeIDs = append(judgeIDs, score.JudgeID)
+		}
+		given[score.JudgeID] = append(given[score.JudgeID], score.Score)
+		all[i] = score.Score
+	}
+	overall := scoring.Summarize(all)
+
+	judges := make([]gin.H, 0, len(judgeIDs))
+	for _, judgeID := range judgeIDs {
+		judge, err := repo.UserFromUserID(int(judgeID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return
+		}
+		summary := scoring.Summarize(given[judgeID])
+		judges = append(judges, gin.H{
+			"judge":        judge.Username,
+			"distribution": summary,
+			// above 0 for judges more lenient than the rest, below 0 for harsher ones
+			"leniency": summary.Mean - overall.Mean,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"normalization": hackathon.ScoreNormalization,
+		"overall":       overall,
+		"judges":        judges,
+	})
+}
+
+// GetMyScores shows a participant how their entry was scored once results are out,
+// the points for each criterion averaged over the judges
+func GetMyScores(c *gin.Context) {
+	hackathon, _, participant, ok := participantHackathon(c)
+	if !ok {
+		return
+	}
+
+	if lifecycle.Guard(hackathon, lifecycle.ActionViewResults, time.Now()) != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Results aren't declared yet!"})
+		return
+	}
+
+	userID, teamID := uint(participant.UserId), uint(0)
+	if participant.TeamID != nil {
+		userID, teamID = 0, *participant.TeamID
+	}
+
+	var criteria []models.Criterion
+	var scores []models.JudgeScore
+	if repo.ListCriteria(&criteria, hackathon.ID) != nil || repo.EntryScores(&scores, hackathon.ID, userID, teamID) != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"score":    participant.Score,
+		"judges":   participant.JudgeCount,
+		"criteria": criterionPoints(criteria, scores),
+	})
+}
+
+// GetMyFeedback shows a participant the feedback the judges wrote on their entry once
+// results are out, without saying which judge wrote what. With email=true the
+// feedback is also emailed to them.
+func GetMyFeedback(c *gin.Context) {
+	hackathon, user, participant, ok := participantHackathon(c)
+	if !ok {
+		return
+	}
+
+	if lifecycle.Guard(hackathon, lifecycle.ActionViewResults, time.Now()) != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Results aren't declared yet!"})
+		return
+	}
+
+	entry := repo.EntryOf(participant)
+	var scores []models.JudgeScore
+	if err := repo.EntryScores(&scores, hackathon.ID, entry.UserID, entry.TeamID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	feedback := make([]string, 0, len(scores))
+	for _, score := range scores {
+		if score.Feedback != "" {
+			feedback = append(feedback, score.Feedback)
+		}
+	}
+
+	response := gin.H{"score": participant.Score, "feedback": feedback}
+	if c.Query("email") == "true" {
+		if len(feedback) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "The judges left no feedback to email"})
+			return
+		}
+		message := "The judges of hackathon " + hackathon.Title + " left this feedback on your submission:\n\n" + strings.Join(feedback, "\n\n")
+		if err := utils.Notify(user.Email, "Feedback on your submission to "+hackathon.Title, message); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "the feedback couldn't be emailed"})
+			return
+		}
+		response["emailed"] = true
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestGetMyFeedbackBeforeResults(t *testing.T) {
+	ctx, w, mock := teamContext(t, "", 4)
+
+	GetMyFeedback(ctx)
+	assert.EqualValues(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `{"message":"Results aren't declared yet!"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
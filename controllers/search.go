@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+	"win-a-thon/search"
+	"win-a-thon/utils"
+)
+
+var (
+	hackathonIndex       = search.NewIndex()
+	hackathonIndexMu     sync.Mutex
+	hackathonIndexLoaded bool
+)
+
+// loadHackathonIndex fills the search index with every approved hackathon the first time it is needed
+func loadHackathonIndex() error {
+	hackathonIndexMu.Lock()
+	defer hackathonIndexMu.Unlock()
+
+	if hackathonIndexLoaded {
+		return nil
+	}
+
+	var hackathons []models.Hackathon
+	if err := repo.ListSearchableHackathons(&hackathons); err != nil {
+		return err
+	}
+	for _, hackathon := range hackathons {
+		hackathonIndex.Add(hackathon)
+	}
+	hackathonIndexLoaded = true
+	return nil
+}
+
+// indexHackathon keeps the search index in sync after a hackathon is created or changed.
+// Only approved hackathons that have not been cancelled are searchable.
+func indexHackathon(hackathon models.Hackathon) {
+	if hackathon.AdminApproved && hackathon.Status != lifecycle.StatusCancelled {
+		hackathonIndex.Add(hackathon)
+	} else {
+		hackathonIndex.Remove(hackathon.ID)
+	}
+}
+
+// unindexHackathon removes a deleted hackathon from the search index
+func unindexHackathon(id uint) {
+	hackathonIndex.Remove(id)
+}
+
+func SearchHackathons(c *gin.Context) {
+	q := c.Query("q")
+	if len(search.Tokenize(q)) == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "search query cannot be empty"})
+		return
+	}
+
+	limit := utils.DefaultPageSize
+	if value := c.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > utils.MaxPageSize {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "limit should be between 1 and 100"})
+			return
+		}
+	}
+
+	if err := loadHackathonIndex(); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "service unavailable"})
+		return
+	}
+
+	results := hackathonIndex.Search(q, limit)
+
+	type searchResult struct {
+		ID               uint      `json:"id"`
+		Title            string    `json:"title"`
+		OrganisationName string    `json:"organisation_name"`
+		StartingTime     time.Time `json:"starting_time"`
+		EndingTime       time.Time `json:"ending_time"`
+		RegistrationOpen bool      `json:"registration_open"`
+		Score            float64   `json:"score"`
+	}
+	searchResults := make([]searchResult, 0)
+
+	if len(results) > 0 {
+		ids := make([]uint, 0, len(results))
+		for _, result := range results {
+			ids = append(ids, result.ID)
+		}
+
+		var hackathons []models.Hackathon
+		if err := repo.HackathonsFromIDs(&hackathons, ids); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "service unavailable"})
+			return
+		}
+
+		found := make(map[uint]models.Hackathon)
+		for _, hackathon := range hackathons {
+			found[hackathon.ID] = hackathon
+		}
+
+		now := time.Now()
+		for _, result := range results {
+			hackathon, ok := found[result.ID]
+			if !ok {
+				continue
+			}
+			searchResults = append(searchResults, searchResult{
+				hackathon.ID,
+				hackathon.Title,
+				hackathon.OrganisationName,
+				hackathon.StartingTime,
+				hackathon.EndingTime,
+				lifecycle.RegistrationGuard(hackathon, now) == nil,
+				result.Score,
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "successful",
+		"query":   q,
+		"results": searchResults,
+	})
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+	"win-a-thon/database"
+	"win-a-thon/search"
+)
+
+func TestSearchHackathonsEmptyQuery(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons/search?q=+", nil)
+
+	SearchHackathons(ctx)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"search query cannot be empty"}`, w.Body.String())
+}
+
+func TestSearchHackathons(t *testing.T) {
+	hackathonIndex = search.NewIndex()
+	hackathonIndexLoaded = false
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/hackathons/search?q=fintek", nil)
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	date := time.Date(2021, 8, 2, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "title", "starting_time", "ending_time", "organisation_name", "description", "admin_approved"}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(true, "cancelled").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "Open Source Jam", date, date, "Fintech Labs", "Contribute upstream", true).
+			AddRow(2, "Fintech Sprint", date, date, "Razorpay", "Payments", true).
+			AddRow(3, "Climate Hack", date, date, "Green Earth", "Cooler planet", true))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE (id IN (?,?) AND admin_approved = ?) AND status <> ? AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(2, 1, true, "cancelled").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "Open Source Jam", date, date, "Fintech Labs", "Contribute upstream", true).
+			AddRow(2, "Fintech Sprint", date, date, "Razorpay", "Payments", true))
+
+	SearchHackathons(ctx)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	expected := `{"query":"fintek","results":[` +
+		`{"id":2,"title":"Fintech Sprint","organisation_name":"Razorpay","starting_time":"2021-08-02T00:00:00Z","ending_time":"2021-08-02T00:00:00Z","registration_open":false,"score":0.962},` +
+		`{"id":1,"title":"Open Source Jam","organisation_name":"Fintech Labs","starting_time":"2021-08-02T00:00:00Z","ending_time":"2021-08-02T00:00:00Z","registration_open":false,"score":0.641}],` +
+		`"status":"successful"}`
+	assert.Equal(t, expected, w.Body.String())
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
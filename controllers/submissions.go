@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+	"win-a-thon/assignment"
+	"win-a-thon/lifecycle"
+	"win-a-thon/links"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+)
+
+// finalSubmissions loads the frozen submissions of a hackathon that has ended by
+// entry, taking the snapshot first when the lifecycle scheduler hasn't yet
+func finalSubmissions(hackathon *models.Hackathon) (map[assignment.Entry]models.SubmissionSnapshot, error) {
+	if err := repo.FreezeSubmissions(hackathon, time.Now()); err != nil {
+		return nil, err
+	}
+	var snapshots []models.SubmissionSnapshot
+	if err := repo.ListSnapshots(&snapshots, hackathon.ID); err != nil {
+		return nil, err
+	}
+	final := make(map[assignment.Entry]models.SubmissionSnapshot)
+	for _, snapshot := range snapshots {
+		final[assignment.Entry{UserID: snapshot.UserID, TeamID: snapshot.TeamID}] = snapshot
+	}
+	return final, nil
+}
+
+// GetSubmissionTimeline shows the organiser every revision of the submission of each
+// entry, and the final submission judged once the hackathon has ended
+func GetSubmissionTimeline(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, true)
+	if !ok {
+		return
+	}
+
+	var revisions []models.SubmissionRevision
+	if err := repo.ListRevisions(&revisions, hackathon.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	final := make(map[assignment.Entry]models.SubmissionSnapshot)
+	if lifecycle.Guard(hackathon, lifecycle.ActionViewSubmissions, time.Now()) == nil {
+		var err error
+		if final, err = finalSubmissions(&hackathon); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return
+		}
+	}
+
+	var teams []models.Team
+	if err := repo.ListTeams(&teams, hackathon.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	teamNames := make(map[uint]string)
+	for _, team := range teams {
+		teamNames[team.ID] = team.Name
+	}
+	usernames := make(map[uint]string)
+	username := func(userID uint) (string, error) {
+		if name, ok := usernames[userID]; ok {
+			return name, nil
+		}
+		user, err := repo.UserFromUserID(int(userID))
+		usernames[userID] = user.Username
+		return user.Username, err
+	}
+
+	// entries are listed in the order they first submitted, entries submitted before
+	// revisions were kept only have their final submission
+	blind := blinded(hackathon)
+	timelines := make([]gin.H, 0)
+	index := make(map[assignment.Entry]int)
+	timeline := func(entry assignment.Entry) (gin.H, error) {
+		if i, ok := index[entry]; ok {
+			return timelines[i], nil
+		}
+		row := gin.H{"entry_id": entryID(hackathon.ID, entry), "revisions": make([]gin.H, 0)}
+		if snapshot, ok := final[entry]; ok {
+			row["final"] = gin.H{"code_url": snapshot.CodeUrl, "demo_url": snapshot.DemoUrl, "submitted_at": snapshot.SubmittedAt}
+		}
+		if !blind && entry.TeamID != 0 {
+			row["team"] = teamNames[entry.TeamID]
+		} else if !blind {
+			name, err := username(entry.UserID)
+			if err != nil {
+				return nil, err
+			}
+			row["username"] = name
+		}
+		index[entry] = len(timelines)
+		timelines = append(timelines, row)
+		return row, nil
+	}
+
+	for _, revision := range revisions {
+		row, err := timeline(assignment.Entry{UserID: revision.UserID, TeamID: revision.TeamID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return
+		}
+		version := gin.H{"code_url": revision.CodeUrl, "demo_url": revision.DemoUrl, "submitted_at": revision.CreatedAt}
+		if !blind {
+			name, err := username(revision.ActorID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+				return
+			}
+			version["submitted_by"] = name
+		}
+		row["revisions"] = append(row["revisions"].([]gin.H), version)
+	}
+	unrevised := make([]assignment.Entry, 0)
+	for entry := range final {
+		if _, ok := index[entry]; !ok {
+			unrevised = append(unrevised, entry)
+		}
+	}
+	sort.Slice(unrevised, func(i, j int) bool {
+		if unrevised[i].TeamID != unrevised[j].TeamID {
+			return unrevised[i].TeamID < unrevised[j].TeamID
+		}
+		return unrevised[i].UserID < unrevised[j].UserID
+	})
+	for _, entry := range unrevised {
+		if _, err := timeline(entry); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"hackathon_id": hackathon.ID, "frozen_at": hackathon.SubmissionsFrozenAt, "entries": timelines})
+}
+
+// GetLinkReport shows the organiser how the links of each submission did when last
+// probed, so that broken ones can be chased before judging starts. Links changed
+// since are pending, and ?broken=true lists the broken links alone.
+func GetLinkReport(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	var participants []models.Participant
+	if err := repo.GetSubmissions(&participants, strconv.Itoa(int(hackathon.ID))); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	teams, err := hackathonTeams(participants, hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	var checks []models.LinkCheck
+	if err := repo.ListLinkChecks(&checks, hackathon.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	type link struct {
+		entry assignment.Entry
+		field string
+	}
+	checked := make(map[link]models.LinkCheck)
+	for _, check := range checks {
+		checked[link{assignment.Entry{UserID: check.UserID, TeamID: check.TeamID}, check.Field}] = check
+	}
+
+	brokenOnly := c.Query("broken") == "true"
+	blind := blinded(hackathon)
+	rows := make([]gin.H, 0)
+	broken := 0
+	seen := make(map[assignment.Entry]bool)
+	for _, participant := range participants {
+		entry := repo.EntryOf(participant)
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+
+		codeUrl, demoUrl := participant.CodeUrl, participant.DemoUrl
+		if entry.TeamID != 0 {
+			codeUrl, demoUrl = teams[entry.TeamID].CodeUrl, teams[entry.TeamID].DemoUrl
+		}
+		for _, field := range []struct{ name, url string }{{"code_url", codeUrl}, {"demo_url", demoUrl}} {
+			if field.url == "" {
+				continue
+			}
+			row := gin.H{"entry_id": entryID(hackathon.ID, entry), "field": field.name, "url": field.url, "status": "pending"}
+			if check, ok := checked[link{entry, field.name}]; ok && check.URL == field.url {
+				row["status"], row["checked_at"] = check.Status, check.CheckedAt
+				if check.StatusCode != 0 {
+					row["status_code"] = check.StatusCode
+				}
+				if check.Error != "" {
+					row["error"] = check.Error
+				}
+			}
+			isBroken := row["status"] == links.StatusBroken || row["status"] == links.StatusBlocked
+			if isBroken {
+				broken++
+			}
+			if brokenOnly && !isBroken {
+				continue
+			}
+
+			if !blind && entry.TeamID != 0 {
+				row["team"] = teams[entry.TeamID].Name
+			} else if !blind {
+				user, err := repo.UserFromUserID(participant.UserId)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+					return
+				}
+				row["username"] = user.Username
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"hackathon_id": hackathon.ID, "broken": broken, "links": rows})
+}
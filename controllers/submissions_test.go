@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+	"win-a-thon/assignment"
+)
+
+// expectFinalSubmissions expects the final submissions of hackathon 1, frozen
+// already, to be loaded
+func expectFinalSubmissions(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `submission_snapshots` WHERE hackathon_id = ?")).
+		WithArgs(1).
+		WillReturnRows(rows)
+}
+
+func TestGetSubmissionTimeline(t *testing.T) {
+	ctx, w, mock := organiserContext(t, "GET", "/hackathons/1/submissions/timeline", "")
+
+	first := time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `submission_revisions` WHERE hackathon_id = ? ORDER BY id")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "user_id", "actor_id", "code_url", "created_at"}).
+			AddRow(1, 1, 7, 7, "https://code/v1", first).
+			AddRow(2, 1, 7, 7, "https://code/v2", first.Add(time.Hour)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `teams` WHERE hackathon_id = ? ORDER BY name")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE ID = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(7, "player"))
+
+	GetSubmissionTimeline(ctx)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"entries":[{"entry_id":"`+entryID(1, assignment.Entry{UserID: 7})+`","revisions":[`+
+		`{"code_url":"https://code/v1","demo_url":"","submitted_at":"2021-09-01T10:00:00Z","submitted_by":"player"},`+
+		`{"code_url":"https://code/v2","demo_url":"","submitted_at":"2021-09-01T11:00:00Z","submitted_by":"player"}],`+
+		`"username":"player"}],"frozen_at":null,"hackathon_id":1}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestGetLinkReportBrokenOnly(t *testing.T) {
+	ctx, w, mock := organiserContext(t, "GET", "/hackathons/1/submissions/links?broken=true", "")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "code_url", "demo_url"}).
+			AddRow(1, 7, "https://github.com/player/app", "https://app.example.com/v2").
+			AddRow(1, 8, "https://github.com/other/app", ""))
+	// the demo of user 7 changed since it was checked
+	checked := time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `link_checks` WHERE hackathon_id = ?")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "field", "url", "status", "status_code", "error", "checked_at"}).
+			AddRow(1, 7, "code_url", "https://github.com/player/app", "broken", 404, "Not Found", checked).
+			AddRow(1, 7, "demo_url", "https://app.example.com/v1", "broken", 0, "timeout", checked).
+			AddRow(1, 8, "code_url", "https://github.com/other/app", "ok", 200, "", checked))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE ID = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(7, "player"))
+
+	GetLinkReport(ctx)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"broken":1,"hackathon_id":1,"links":[{"checked_at":"2021-09-01T10:00:00Z","entry_id":"`+entryID(1, assignment.Entry{UserID: 7})+
+		`","error":"Not Found","field":"code_url","status":"broken","status_code":404,"url":"https://github.com/player/app","username":"player"}]}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
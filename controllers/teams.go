@@ -0,0 +1,372 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+	"win-a-thon/token"
+	"win-a-thon/utils"
+)
+
+// participantHackathon loads the hackathon of the request, the logged in user and
+// their registration, and aborts the request unless they participate in it.
+func participantHackathon(c *gin.Context) (models.Hackathon, models.User, models.Participant, bool) {
+	var participant models.Participant
+
+	authPayload := c.MustGet(utils.AuthorizationPayloadKey).(*token.Payload)
+	user, err := repo.GetProfileByUsername(authPayload.Username)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "User doesn't exist"})
+		return models.Hackathon{}, user, participant, false
+	}
+
+	hackathon, err := repo.HackathonFromHackathonID(c.Params.ByName("hackathon_id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "This hackathon id doesnt exist"})
+		return hackathon, user, participant, false
+	}
+
+	participant, err = repo.ParticipantFromUserID(strconv.Itoa(int(hackathon.ID)), strconv.Itoa(int(user.ID)))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return hackathon, user, participant, false
+	}
+	if participant.UserId != int(user.ID) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "user has not participated"})
+		return hackathon, user, participant, false
+	}
+	return hackathon, user, participant, true
+}
+
+// teamsOpen aborts the request unless teams of the hackathon can still change. Teams
+// are formed until the hackathon ends, in hackathons that allow more than one member.
+func teamsOpen(c *gin.Context, hackathon models.Hackathon) bool {
+	if hackathon.MaxTeamSize == 1 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "This hackathon doesn't allow teams"})
+		return false
+	}
+	if lifecycle.Guard(hackathon, lifecycle.ActionParticipate, time.Now()) != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Teams can't be changed right now"})
+		return false
+	}
+	return true
+}
+
+// notJudging aborts the request when the user judges the hackathon, since judges
+// can't take part in an entry they would score
+func notJudging(c *gin.Context, hackathon models.Hackathon, userID uint) bool {
+	judge, err := repo.IsJudge(hackathon.ID, userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return false
+	}
+	if judge {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Judges can't take part in the hackathon they judge"})
+		return false
+	}
+	return true
+}
+
+// joinTeam adds the user to the team and answers with the reason when they can't join
+func joinTeam(c *gin.Context, hackathon models.Hackathon, team models.Team, userID uint) bool {
+	if !notJudging(c, hackathon, userID) {
+		return false
+	}
+	err := repo.JoinTeam(team, userID, hackathon.MaxTeamSize)
+	if err == repo.ErrTeamFull {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "This team is full"})
+		return false
+	} else if err == repo.ErrAlreadyInTeam {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Already in a team for this hackathon"})
+		return false
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return false
+	}
+	return true
+}
+
+func CreateTeam(c *gin.Context) {
+	hackathon, user, participant, ok := participantHackathon(c)
+	if !ok || !teamsOpen(c, hackathon) {
+		return
+	}
+
+	if participant.TeamID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Already in a team for this hackathon"})
+		return
+	}
+	if !notJudging(c, hackathon, user.ID) {
+		return
+	}
+
+	var req utils.CreateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "A team name is required"})
+		return
+	}
+
+	inviteCode, err := utils.NewInviteCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	team := models.Team{
+		HackathonID: hackathon.ID,
+		Name:        strings.TrimSpace(req.Name),
+		CaptainID:   user.ID,
+		InviteCode:  inviteCode,
+	}
+	err = repo.CreateTeam(&team)
+	if err != nil {
+		if err, ok := err.(*mysql.MySQLError); ok && err.Number == utils.DuplicateRecordErrorCode {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Team name already taken"})
+			return
+		}
+		if err == repo.ErrAlreadyInTeam {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Already in a team for this hackathon"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "team created successfully",
+		"team":   team,
+	})
+}
+
+func ListTeams(c *gin.Context) {
+	hackathon, err := repo.HackathonFromHackathonID(c.Params.ByName("hackathon_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This hackathon id doesnt exist"})
+		return
+	}
+
+	var teams []models.Team
+	if err := repo.ListTeams(&teams, hackathon.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	type teamConcise struct {
+		ID      uint   `json:"id"`
+		Name    string `json:"name"`
+		Members int64  `json:"members"`
+	}
+	teamList := make([]teamConcise, 0, len(teams))
+	for _, team := range teams {
+		count, err := repo.CountTeamMembers(team.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return
+		}
+		teamList = append(teamList, teamConcise{team.ID, team.Name, count})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"teams":         teamList,
+		"min_team_size": hackathon.MinTeamSize,
+		"max_team_size": hackathon.MaxTeamSize,
+	})
+}
+
+// GetTeam shows the logged in participant their team. The captain also sees the
+// requests to join it.
+func GetTeam(c *gin.Context) {
+	_, user, participant, ok := participantHackathon(c)
+	if !ok {
+		return
+	}
+
+	if participant.TeamID == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Not in a team for this hackathon"})
+		return
+	}
+
+	team, err := repo.TeamFromID(strconv.Itoa(int(*participant.TeamID)), uint(participant.HackathonId))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	var members []models.User
+	if err := repo.TeamMembers(&members, team.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	usernames := make([]string, 0, len(members))
+	for _, member := range members {
+		usernames = append(usernames, member.Username)
+	}
+
+	response := gin.H{
+		"team":    team,
+		"members": usernames,
+	}
+	if team.CaptainID == user.ID {
+		requests := make([]models.TeamJoinRequest, 0)
+		if err := repo.ListTeamJoinRequests(&requests, team.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return
+		}
+		response["join_requests"] = requests
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func JoinTeam(c *gin.Context) {
+	hackathon, user, _, ok := participantHackathon(c)
+	if !ok || !teamsOpen(c, hackathon) {
+		return
+	}
+
+	var req utils.JoinTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.InviteCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "An invite code is required"})
+		return
+	}
+
+	team, err := repo.TeamFromInviteCode(req.InviteCode, hackathon.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Invalid invite code"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	if !joinTeam(c, hackathon, team, user.ID) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "joined team successfully", "team_id": team.ID, "name": team.Name})
+}
+
+// RequestToJoinTeam asks the captain of a team to let the logged in participant in
+func RequestToJoinTeam(c *gin.Context) {
+	hackathon, user, participant, ok := participantHackathon(c)
+	if !ok || !teamsOpen(c, hackathon) {
+		return
+	}
+
+	if participant.TeamID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Already in a team for this hackathon"})
+		return
+	}
+	if !notJudging(c, hackathon, user.ID) {
+		return
+	}
+
+	team, err := repo.TeamFromID(c.Params.ByName("team_id"), hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This team doesn't exist"})
+		return
+	}
+
+	request := models.TeamJoinRequest{TeamID: team.ID, UserID: user.ID}
+	if err := repo.CreateTeamJoinRequest(&request); err != nil {
+		if err, ok := err.(*mysql.MySQLError); ok && err.Number == utils.DuplicateRecordErrorCode {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Already requested to join this team"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	captain, err := repo.UserFromUserID(int(team.CaptainID))
+	if err == nil {
+		err = utils.Notify(captain.Email, "Request to join team "+team.Name,
+			user.Username+" would like to join your team "+team.Name+" in hackathon "+hackathon.Title+".")
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "request sent to the team captain", "request_id": request.ID})
+}
+
+// RespondToJoinRequest lets the captain accept or decline a request to join their team
+func RespondToJoinRequest(c *gin.Context) {
+	hackathon, user, _, ok := participantHackathon(c)
+	if !ok {
+		return
+	}
+
+	value := c.Params.ByName("value")
+	if value != utils.ApprovalApprove && value != utils.ApprovalReject {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "value should be 1 to accept or 0 to decline"})
+		return
+	}
+
+	team, err := repo.TeamFromID(c.Params.ByName("team_id"), hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This team doesn't exist"})
+		return
+	}
+	if team.CaptainID != user.ID {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Only the team captain can answer join requests"})
+		return
+	}
+
+	request, err := repo.TeamJoinRequestFromID(c.Params.ByName("request_id"), team.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This join request doesn't exist"})
+		return
+	}
+	requester, err := repo.UserFromUserID(int(request.UserID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	if value == utils.ApprovalReject {
+		if err := repo.DeleteTeamJoinRequest(&request); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return
+		}
+		if err := utils.Notify(requester.Email, "Request to join team "+team.Name,
+			"Your request to join team "+team.Name+" was declined."); err != nil {
+			fmt.Println(err)
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "join request declined"})
+		return
+	}
+
+	if !teamsOpen(c, hackathon) || !joinTeam(c, hackathon, team, request.UserID) {
+		return
+	}
+	if err := utils.Notify(requester.Email, "Request to join team "+team.Name,
+		"Your request to join team "+team.Name+" was accepted."); err != nil {
+		fmt.Println(err)
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "join request accepted", "username": requester.Username})
+}
+
+func LeaveTeam(c *gin.Context) {
+	hackathon, user, participant, ok := participantHackathon(c)
+	if !ok || !teamsOpen(c, hackathon) {
+		return
+	}
+
+	if participant.TeamID == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Not in a team for this hackathon"})
+		return
+	}
+
+	if err := repo.LeaveTeam(hackathon.ID, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "left team successfully"})
+}
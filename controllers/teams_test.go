@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"win-a-thon/database"
+	"win-a-thon/token"
+)
+
+// teamContext prepares a request by participant "name" (user 3) of hackathon 1,
+// which allows teams of up to maxTeamSize members
+func teamContext(t *testing.T, body string, maxTeamSize int) (*gin.Context, *httptest.ResponseRecorder, sqlmock.Sqlmock) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "name",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	ctx.Params = []gin.Param{{Key: "hackathon_id", Value: "1"}}
+	ctx.Request, _ = http.NewRequest("POST", "/hackathons/1/teams", strings.NewReader(body))
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(3, "name"))
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "starting_time", "ending_time", "result_time", "organiser_id", "admin_approved", "status", "max_team_size"}).
+			AddRow(1, "Test Hackathon", now.Add(time.Hour), now.Add(2*time.Hour), now.Add(3*time.Hour), 2, true, "registration_open", maxTeamSize))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL")).
+		WithArgs("1", "3").
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "team_id"}).AddRow(1, 3, nil))
+
+	return ctx, w, mock
+}
+
+func TestCreateTeamInSoloHackathon(t *testing.T) {
+	ctx, w, mock := teamContext(t, `{"name":"Rockets"}`, 1)
+
+	CreateTeam(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"This hackathon doesn't allow teams"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+// expectJudgeCheck expects whether user 3 judges hackathon 1 to be looked up
+func expectJudgeCheck(mock sqlmock.Sqlmock, judges int) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `judges` WHERE hackathon_id = ? AND user_id = ?")).
+		WithArgs(1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(judges))
+}
+
+func TestCreateTeamAsJudge(t *testing.T) {
+	ctx, w, mock := teamContext(t, `{"name":"Rockets"}`, 4)
+	expectJudgeCheck(mock, 1)
+
+	CreateTeam(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Judges can't take part in the hackathon they judge"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestCreateTeamWithoutName(t *testing.T) {
+	ctx, w, mock := teamContext(t, `{"name":" "}`, 4)
+	expectJudgeCheck(mock, 0)
+
+	CreateTeam(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"A team name is required"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestGetTeamNotInTeam(t *testing.T) {
+	ctx, w, mock := teamContext(t, "", 4)
+
+	GetTeam(ctx)
+	assert.EqualValues(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"message":"Not in a team for this hackathon"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+	"win-a-thon/utils"
+)
+
+// pickTrack checks that the track a participant picked belongs to the hackathon
+// and answers with not found when it doesn't
+func pickTrack(c *gin.Context, trackID uint, hackathonID uint) (models.Track, bool) {
+	track, err := repo.TrackFromID(strconv.Itoa(int(trackID)), hackathonID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "This track doesn't exist"})
+		return track, false
+	}
+	return track, true
+}
+
+func ListTracks(c *gin.Context) {
+	hackathon, err := repo.HackathonFromHackathonID(c.Params.ByName("hackathon_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This hackathon id doesnt exist"})
+		return
+	}
+
+	tracks := make([]models.Track, 0)
+	if err := repo.ListTracks(&tracks, hackathon.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tracks": tracks})
+}
+
+func CreateTrack(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	if lifecycle.Guard(hackathon, lifecycle.ActionEditMaterial, time.Now()) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Tracks can't be changed once the hackathon has started"})
+		return
+	}
+
+	var req utils.CreateTrackRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "A track name is required"})
+		return
+	}
+
+	track := models.Track{
+		HackathonID: hackathon.ID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+	}
+	if err := repo.CreateTrack(&track); err != nil {
+		if err, ok := err.(*mysql.MySQLError); ok && err.Number == utils.DuplicateRecordErrorCode {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Track already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "track created successfully", "track": track})
+}
+
+func DeleteTrack(c *gin.Context) {
+	hackathon, _, ok := authorisedHackathon(c, false)
+	if !ok {
+		return
+	}
+
+	if lifecycle.Guard(hackathon, lifecycle.ActionEditMaterial, time.Now()) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Tracks can't be changed once the hackathon has started"})
+		return
+	}
+
+	track, err := repo.TrackFromID(c.Params.ByName("track_id"), hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This track doesn't exist"})
+		return
+	}
+
+	if err := repo.DeleteTrack(&track); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "track deleted successfully"})
+}
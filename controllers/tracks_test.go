@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+	"win-a-thon/database"
+)
+
+func TestGetLeaderboardUnknownTrack(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	ctx.Params = []gin.Param{{Key: "hackathon_id", Value: "1"}}
+	ctx.Request, _ = http.NewRequest("GET", "/hackathons/1/leaderboard?track_id=9", nil)
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE hackathon_id = ? AND track_id = ? AND `participants`.`deleted_at` IS NULL ORDER BY score desc")).
+		WithArgs("1", "9").
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "score"}))
+
+	past := time.Now().Add(-24 * time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "starting_time", "ending_time", "result_time", "admin_approved", "status"}).
+			AddRow(1, past, past, past, true, "approved"))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `tracks` WHERE id = ? AND hackathon_id = ? ORDER BY `tracks`.`id` LIMIT 1")).
+		WithArgs("9", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "name"}))
+
+	GetLeaderboard(ctx)
+	assert.EqualValues(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"message":"This track doesn't exist"}`, w.Body.String())
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
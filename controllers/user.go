@@ -218,9 +218,17 @@ func DeleteUserProfile(c *gin.Context) {
 	authPayload := c.MustGet(utils.AuthorizationPayloadKey).(*token.Payload)
 	authorisedUsername := authPayload.Username
 
-	err := repo.DeleteUserByUsername(authorisedUsername)
+	promotions, err := repo.DeleteUserByUsername(authorisedUsername)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, err)
+		return
+	}
+	for _, promotion := range promotions {
+		subject := "Seat confirmed in hackathon " + promotion.Hackathon.Title
+		message := "A seat opened up in hackathon " + promotion.Hackathon.Title + " and you have been moved off the waitlist. You are now participating."
+		if err := utils.Notify(promotion.User.Email, subject, message); err != nil {
+			fmt.Println(err)
+		}
 	}
 	c.JSON(http.StatusOK, gin.H{"msg": "Profile deleted successfully"})
 }
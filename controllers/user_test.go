@@ -424,12 +424,25 @@ func TestDeleteUserProfile(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
 	}
 
+	userRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "username", "full_name", "hashed_password", "email", "linked_in", "git_hub", "web_link", "organisation", "is_admin"}).
+		AddRow(1, time.Now(), time.Now(), nil, "admin", "abc def", "password", "email", "abc", "abc", "abc", "abc", true)
+
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `deleted_at`=? WHERE username = ? AND `users`.`deleted_at` IS NULL")).WithArgs(AnyTime{}, "admin").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).WithArgs("admin").WillReturnRows(userRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`,`title`,`max_participants` FROM `hackathons` WHERE id IN (SELECT `hackathon_id` FROM `participants` WHERE user_id = ? AND `participants`.`deleted_at` IS NULL) AND `hackathons`.`deleted_at` IS NULL ORDER BY id FOR UPDATE")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "max_participants"}).AddRow(1, "Test Hackathon", 0))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `participants` SET `deleted_at`=? WHERE user_id = ? AND `participants`.`deleted_at` IS NULL")).WithArgs(AnyTime{}, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `waitlist_entries` WHERE user_id = ?")).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `deleted_at`=? WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL")).WithArgs(AnyTime{}, 1).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
 	DeleteUserProfile(ctx)
 	assert.EqualValues(t, http.StatusOK, w.Code)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
 }
 
 func TestUpdateUserProfileBadRequestCase(t *testing.T) {
@@ -0,0 +1,397 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"win-a-thon/assignment"
+	"win-a-thon/blind"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+	"win-a-thon/scoring"
+	"win-a-thon/token"
+	"win-a-thon/utils"
+)
+
+// ballotEntry is an entry of a hackathon that can be voted for
+type ballotEntry struct {
+	Entry   assignment.Entry
+	ID      string
+	Members []models.Participant
+	Team    models.Team
+	CodeUrl string
+	DemoUrl string
+}
+
+// trustedProxies are the networks of the reverse proxies in front of the application.
+// Anyone can send X-Forwarded-For, so it is only believed from them.
+var trustedProxies []*net.IPNet
+
+// TrustProxies sets the reverse proxies, as addresses or CIDRs, whose forwarded
+// client addresses are believed
+func TrustProxies(proxies []string) error {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("trusted proxy %q: %w", proxy, err)
+		}
+		networks = append(networks, network)
+	}
+	trustedProxies = networks
+	return nil
+}
+
+func trustedProxy(ip net.IP) bool {
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// voterIP is the address a ballot comes from: the address of the connection, or,
+// when that is a trusted proxy, the nearest address in X-Forwarded-For that isn't
+func voterIP(c *gin.Context) string {
+	remote, _ := c.RemoteIP()
+	if remote == nil {
+		return ""
+	}
+	if !trustedProxy(remote) {
+		return remote.String()
+	}
+	hops := strings.Split(c.GetHeader("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		if !trustedProxy(ip) {
+			return ip.String()
+		}
+	}
+	return remote.String()
+}
+
+// votingOpen reports whether the People's Choice vote of a hackathon is open, which
+// it is from the end of the hackathon until voting closes
+func votingOpen(hackathon models.Hackathon, now time.Time) bool {
+	return hackathon.VotingClosesAt != nil && now.Before(*hackathon.VotingClosesAt) &&
+		lifecycle.Guard(hackathon, lifecycle.ActionViewSubmissions, now) == nil
+}
+
+// ballotEntries loads the entries of a hackathon with a final submission, which are
+// the ones on the ballot
+func ballotEntries(hackathon models.Hackathon) ([]ballotEntry, error) {
+	var participants []models.Participant
+	if err := repo.GetSubmissions(&participants, strconv.Itoa(int(hackathon.ID))); err != nil {
+		return nil, err
+	}
+	teams, err := hackathonTeams(participants, hackathon.ID)
+	if err != nil {
+		return nil, err
+	}
+	final, err := finalSubmissions(&hackathon)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]ballotEntry, 0)
+	index := make(map[assignment.Entry]int)
+	for _, participant := range participants {
+		entry := repo.EntryOf(participant)
+		if i, ok := index[entry]; ok {
+			entries[i].Members = append(entries[i].Members, participant)
+			continue
+		}
+
+		snapshot, ok := final[entry]
+		if !ok {
+			continue
+		}
+		candidate := ballotEntry{Entry: entry, ID: entryID(hackathon.ID, entry), Members: []models.Participant{participant},
+			Team: teams[entry.TeamID], CodeUrl: snapshot.CodeUrl, DemoUrl: snapshot.DemoUrl}
+		index[entry] = len(entries)
+		entries = append(entries, candidate)
+	}
+	return entries, nil
+}
+
+// describeEntry adds who is behind an entry to its response, unless the hackathon
+// is still judged blind
+func describeEntry(response gin.H, hackathon models.Hackathon, entry ballotEntry) error {
+	if blinded(hackathon) {
+		return nil
+	}
+	usernames := make([]string, 0, len(entry.Members))
+	for _, member := range entry.Members {
+		user, err := repo.UserFromUserID(member.UserId)
+		if err != nil {
+			return err
+		}
+		usernames = append(usernames, user.Username)
+	}
+	if entry.Entry.TeamID != 0 {
+		response["team"] = entry.Team.Name
+		response["members"] = usernames
+	} else {
+		response["username"] = usernames[0]
+	}
+	return nil
+}
+
+// GetBallot lists the entries of a hackathon that can be voted for the People's
+// Choice award
+func GetBallot(c *gin.Context) {
+	hackathon, err := repo.HackathonFromHackathonID(c.Params.ByName("hackathon_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This hackathon id doesnt exist"})
+		return
+	}
+
+	if hackathon.VotingClosesAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "This hackathon has no People's Choice vote"})
+		return
+	}
+	if lifecycle.Guard(hackathon, lifecycle.ActionViewSubmissions, time.Now()) != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Voting opens once the hackathon ends"})
+		return
+	}
+
+	entries, err := ballotEntries(hackathon)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	ballot := make([]gin.H, 0, len(entries))
+	for _, entry := range entries {
+		response := gin.H{"entry_id": entry.ID, "code_url": entry.CodeUrl, "demo_url": entry.DemoUrl}
+		if err := describeEntry(response, hackathon, entry); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return
+		}
+		ballot = append(ballot, response)
+	}
+
+	votesPerUser := hackathon.VotesPerUser
+	if votesPerUser < 1 {
+		votesPerUser = 1
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"voting_open":      votingOpen(hackathon, time.Now()),
+		"voting_closes_at": hackathon.VotingClosesAt,
+		"votes_per_user":   votesPerUser,
+		"entries":          ballot,
+	})
+}
+
+// CastVotes records the ballot of a user for the People's Choice award, the entries
+// they vote for in order of preference
+func CastVotes(c *gin.Context) {
+	authPayload := c.MustGet(utils.AuthorizationPayloadKey).(*token.Payload)
+	user, err := repo.GetProfileByUsername(authPayload.Username)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "User doesn't exist"})
+		return
+	}
+
+	hackathon, err := repo.HackathonFromHackathonID(c.Params.ByName("hackathon_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This hackathon id doesnt exist"})
+		return
+	}
+
+	now := time.Now()
+	if hackathon.VotingClosesAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "This hackathon has no People's Choice vote"})
+		return
+	}
+	if !votingOpen(hackathon, now) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Voting isn't open right now"})
+		return
+	}
+	if hackathon.OrganiserID == int(user.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Organisers can't vote in their own hackathon"})
+		return
+	}
+
+	// accounts are verified by age, so that votes can't come from accounts made for the occasion
+	if now.Sub(user.CreatedAt) < utils.VoterAccountAge {
+		c.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("Accounts can vote %d hours after signing up", int(utils.VoterAccountAge.Hours()))})
+		return
+	}
+
+	var req utils.CastVotesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "field names incorrect"})
+		return
+	}
+	votesPerUser := hackathon.VotesPerUser
+	if votesPerUser < 1 {
+		votesPerUser = 1
+	}
+	if len(req.EntryIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Pick an entry to vote for"})
+		return
+	}
+	if len(req.EntryIDs) > votesPerUser {
+		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("You can vote for up to %d entries", votesPerUser)})
+		return
+	}
+
+	entries, err := ballotEntries(hackathon)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+	candidates := make([]assignment.Entry, len(entries))
+	var own *assignment.Entry
+	for i, entry := range entries {
+		candidates[i] = entry.Entry
+		for _, member := range entry.Members {
+			if member.UserId == int(user.ID) {
+				own = &candidates[i]
+			}
+		}
+	}
+
+	votes := make([]models.Vote, 0, len(req.EntryIDs))
+	picked := make(map[assignment.Entry]bool)
+	ip := voterIP(c)
+	for i, id := range req.EntryIDs {
+		entry, ok := blind.Find(blind.Secret, hackathon.ID, id, candidates)
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"message": "This entry doesn't exist"})
+			return
+		}
+		if own != nil && entry == *own {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "You can't vote for your own entry"})
+			return
+		}
+		if picked[entry] {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "You can't vote for an entry more than once"})
+			return
+		}
+		picked[entry] = true
+		votes = append(votes, models.Vote{HackathonID: hackathon.ID, VoterID: user.ID, UserID: entry.UserID,
+			TeamID: entry.TeamID, Position: i + 1, IP: ip})
+	}
+
+	if err := repo.CastBallot(votes, utils.MaxBallotsPerIP); err != nil {
+		mysqlErr, ok := err.(*mysql.MySQLError)
+		switch {
+		case err == repo.ErrAlreadyVoted || ok && mysqlErr.Number == utils.DuplicateRecordErrorCode:
+			c.JSON(http.StatusBadRequest, gin.H{"message": "You already voted in this hackathon"})
+		case err == repo.ErrTooManyBallots:
+			c.JSON(http.StatusTooManyRequests, gin.H{"message": "Too many votes in this hackathon come from your network"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "vote cast successfully", "votes": len(votes)})
+}
+
+// GetVoteTally shows the People's Choice tally of a hackathon, which is kept apart
+// from the scores of the judges. The organiser follows it as votes come in, everyone
+// else sees it with the results.
+func GetVoteTally(c *gin.Context) {
+	authPayload := c.MustGet(utils.AuthorizationPayloadKey).(*token.Payload)
+	user, err := repo.GetProfileByUsername(authPayload.Username)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "User doesn't exist"})
+		return
+	}
+
+	hackathon, err := repo.HackathonFromHackathonID(c.Params.ByName("hackathon_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "This hackathon id doesnt exist"})
+		return
+	}
+
+	if hackathon.VotingClosesAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "This hackathon has no People's Choice vote"})
+		return
+	}
+	if hackathon.OrganiserID != int(user.ID) && lifecycle.Guard(hackathon, lifecycle.ActionViewResults, time.Now()) != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Results aren't declared yet!"})
+		return
+	}
+
+	tally, entries, err := voteTally(hackathon)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+		return
+	}
+
+	rows := make([]gin.H, 0, len(tally))
+	for _, count := range tally {
+		entry, ok := entries[assignment.Entry{UserID: count.UserID, TeamID: count.TeamID}]
+		if !ok {
+			// the entry withdrew after it was voted for
+			continue
+		}
+		row := gin.H{"rank": count.Rank, "entry_id": entry.ID, "points": count.Points, "votes": count.Votes}
+		if err := describeEntry(row, hackathon, entry); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "the server encountered an issue"})
+			return
+		}
+		rows = append(rows, row)
+	}
+	c.JSON(http.StatusOK, gin.H{"voting_open": votingOpen(hackathon, time.Now()), "tally": rows})
+}
+
+// voteTally counts the votes of a hackathon, along with the entries on its ballot
+func voteTally(hackathon models.Hackathon) ([]scoring.VoteCount, map[assignment.Entry]ballotEntry, error) {
+	var votes []models.Vote
+	if err := repo.ListVotes(&votes, hackathon.ID); err != nil {
+		return nil, nil, err
+	}
+	list, err := ballotEntries(hackathon)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries := make(map[assignment.Entry]ballotEntry)
+	for _, entry := range list {
+		entries[entry.Entry] = entry
+	}
+	return scoring.TallyVotes(votes, hackathon.VotesPerUser), entries, nil
+}
+
+// peoplesChoiceWinners are the entries with the most votes in a hackathon, all of
+// them when several are tied
+func peoplesChoiceWinners(hackathon models.Hackathon) ([][]models.Participant, error) {
+	tally, entries, err := voteTally(hackathon)
+	if err != nil {
+		return nil, err
+	}
+	var winners [][]models.Participant
+	rank := 0
+	for _, count := range tally {
+		entry, ok := entries[assignment.Entry{UserID: count.UserID, TeamID: count.TeamID}]
+		if !ok {
+			continue
+		}
+		if len(winners) > 0 && count.Rank != rank {
+			break
+		}
+		rank = count.Rank
+		winners = append(winners, entry.Members)
+	}
+	return winners, nil
+}
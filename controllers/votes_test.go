@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"win-a-thon/assignment"
+	"win-a-thon/database"
+	"win-a-thon/token"
+)
+
+// voteContext prepares a ballot cast by user 3, who signed up at signedUp, in
+// hackathon 1 organised by user 2, which ended an hour ago and takes votes for
+// another hour
+func voteContext(t *testing.T, body string, signedUp time.Time) (*gin.Context, *httptest.ResponseRecorder, sqlmock.Sqlmock) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["authorization_payload"] = &token.Payload{
+		Username:  "name",
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	ctx.Params = []gin.Param{{Key: "hackathon_id", Value: "1"}}
+	ctx.Request, _ = http.NewRequest("POST", "/hackathons/1/votes", strings.NewReader(body))
+
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{Conn: driver, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database connection", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "created_at"}).AddRow(3, "name", signedUp))
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE ID = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "starting_time", "ending_time", "result_time", "organiser_id", "admin_approved", "status", "voting_closes_at", "votes_per_user", "submissions_frozen_at"}).
+			AddRow(1, "Test Hackathon", now.Add(-2*time.Hour), now.Add(-time.Hour), now.Add(2*time.Hour), 2, true, "registration_open", now.Add(time.Hour), 2, now.Add(-time.Hour)))
+
+	return ctx, w, mock
+}
+
+func TestCastVotesFromNewAccount(t *testing.T) {
+	ctx, w, mock := voteContext(t, `{"entry_ids":["abc"]}`, time.Now().Add(-time.Hour))
+
+	CastVotes(ctx)
+	assert.EqualValues(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, `{"message":"Accounts can vote 72 hours after signing up"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestCastVotesForOwnEntry(t *testing.T) {
+	own := entryID(1, assignment.Entry{UserID: 3})
+	other := entryID(1, assignment.Entry{UserID: 4})
+	ctx, w, mock := voteContext(t, `{"entry_ids":["`+other+`","`+own+`"]}`, time.Now().Add(-30*24*time.Hour))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "demo_url", "code_url"}).
+			AddRow(1, 3, "", "https://code/3").
+			AddRow(1, 4, "", "https://code/4"))
+	expectFinalSubmissions(mock, sqlmock.NewRows([]string{"hackathon_id", "user_id", "code_url"}).
+		AddRow(1, 3, "https://code/3").
+		AddRow(1, 4, "https://code/4"))
+
+	CastVotes(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"You can't vote for your own entry"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestCastVotesTooMany(t *testing.T) {
+	ctx, w, mock := voteContext(t, `{"entry_ids":["a","b","c"]}`, time.Now().Add(-30*24*time.Hour))
+
+	CastVotes(ctx)
+	assert.EqualValues(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"You can vote for up to 2 entries"}`, w.Body.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+// expectBallotFrom expects the ballot of user 3 for user 4 to be checked against the
+// ballots already cast from ip, five of them so that it is refused
+func expectBallotFrom(mock sqlmock.Sqlmock, ip string) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "demo_url", "code_url"}).
+			AddRow(1, 4, "", "https://code/4"))
+	expectFinalSubmissions(mock, sqlmock.NewRows([]string{"hackathon_id", "user_id", "code_url"}).
+		AddRow(1, 4, "https://code/4"))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id` FROM `hackathons` WHERE id = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1 FOR UPDATE")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `votes` WHERE hackathon_id = ? AND voter_id = ?")).
+		WithArgs(1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(DISTINCT(`voter_id`)) FROM `votes` WHERE hackathon_id = ? AND ip = ?")).
+		WithArgs(1, ip).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectRollback()
+}
+
+func TestCastVotesSpoofedForwardedFor(t *testing.T) {
+	other := entryID(1, assignment.Entry{UserID: 4})
+	ctx, w, mock := voteContext(t, `{"entry_ids":["`+other+`"]}`, time.Now().Add(-30*24*time.Hour))
+	ctx.Request.RemoteAddr = "203.0.113.7:52000"
+	ctx.Request.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	// the header is ignored, the ballot counts against the address it came from
+	expectBallotFrom(mock, "203.0.113.7")
+
+	CastVotes(ctx)
+	assert.EqualValues(t, http.StatusTooManyRequests, w.Code)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestCastVotesThroughTrustedProxy(t *testing.T) {
+	if err := TrustProxies([]string{"10.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
+	defer TrustProxies(nil)
+
+	other := entryID(1, assignment.Entry{UserID: 4})
+	ctx, w, mock := voteContext(t, `{"entry_ids":["`+other+`"]}`, time.Now().Add(-30*24*time.Hour))
+	ctx.Request.RemoteAddr = "10.0.0.2:52000"
+	// the client made up the first address, the proxies appended the rest
+	ctx.Request.Header.Set("X-Forwarded-For", "192.0.2.9, 198.51.100.1, 10.0.0.3")
+
+	expectBallotFrom(mock, "198.51.100.1")
+
+	CastVotes(ctx)
+	assert.EqualValues(t, http.StatusTooManyRequests, w.Code)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
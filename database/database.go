@@ -6,6 +6,7 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"win-a-thon/config"
+	"win-a-thon/lifecycle"
 	"win-a-thon/models"
 )
 
@@ -56,10 +57,41 @@ func GetDatabase() (*gorm.DB, error) {
 		return nil, err
 	}
 
-	err = db.AutoMigrate(&models.User{}, &models.Hackathon{}, &models.Participant{}, &models.Notification{})
+	// raw scores are backfilled once, when the column is added, as normalized scores
+	// can later differ from a raw mean of 0
+	backfillRawScores := db.Migrator().HasTable(&models.Participant{}) && !db.Migrator().HasColumn(&models.Participant{}, "raw_score")
+
+	err = db.AutoMigrate(&models.User{}, &models.Hackathon{}, &models.Participant{}, &models.Notification{}, &models.HackathonTransition{}, &models.WaitlistEntry{}, &models.Team{}, &models.TeamJoinRequest{}, &models.Track{}, &models.Prize{}, &models.Judge{}, &models.JudgeScore{}, &models.Criterion{}, &models.CriterionScore{}, &models.Assignment{}, &models.Conflict{}, &models.Vote{}, &models.SubmissionRevision{}, &models.SubmissionSnapshot{}, &models.LinkCheck{}, &models.Artifact{})
+	if err != nil {
+		return nil, err
+	}
+
+	// hackathons created before the lifecycle existed only carry the approval flag
+	err = db.Model(&models.Hackathon{}).Where("status = ?", "").
+		Update("status", gorm.Expr("CASE WHEN admin_approved THEN ? ELSE ? END", lifecycle.StatusApproved, lifecycle.StatusPendingApproval)).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// scores organisers gave before judges existed become the organiser's own score
+	err = db.Exec("INSERT IGNORE INTO judge_scores (hackathon_id, judge_id, user_id, team_id, score, created_at, updated_at) " +
+		"SELECT p.hackathon_id, h.organiser_id, CASE WHEN p.team_id IS NULL THEN p.user_id ELSE 0 END, COALESCE(p.team_id, 0), p.score, NOW(), NOW() " +
+		"FROM participants p JOIN hackathons h ON h.id = p.hackathon_id WHERE p.score <> 0 AND p.judge_count = 0 AND p.deleted_at IS NULL").Error
 	if err != nil {
 		return nil, err
 	}
+	err = db.Model(&models.Participant{}).Where("score <> 0 AND judge_count = 0").Update("judge_count", 1).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// scores combined before normalization existed are raw ones
+	if backfillRawScores {
+		err = db.Model(&models.Participant{}).Where("raw_score = 0 AND score <> 0").Update("raw_score", gorm.Expr("score")).Error
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return db, nil
 }
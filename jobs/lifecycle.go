@@ -0,0 +1,38 @@
+package jobs
+
+import (
+	"fmt"
+	"time"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+)
+
+// lifecycleInterval is how often hackathon statuses are caught up with their timeline
+const lifecycleInterval = time.Minute
+
+// StartLifecycleScheduler periodically records the status changes that the
+// timelines of approved hackathons have made due.
+func StartLifecycleScheduler() {
+	go func() {
+		ticker := time.NewTicker(lifecycleInterval)
+		defer ticker.Stop()
+		for {
+			AdvanceHackathons(time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+// AdvanceHackathons applies the due transitions of every hackathon still in progress.
+func AdvanceHackathons(now time.Time) {
+	var hackathons []models.Hackathon
+	if err := repo.ListHackathonsInProgress(&hackathons); err != nil {
+		fmt.Println("lifecycle scheduler failed: ", err)
+		return
+	}
+	for i := range hackathons {
+		if err := repo.AdvanceHackathon(&hackathons[i], now); err != nil {
+			fmt.Printf("cannot advance hackathon %d: %v\n", hackathons[i].ID, err)
+		}
+	}
+}
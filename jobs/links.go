@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"time"
+	"win-a-thon/config"
+	"win-a-thon/links"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+)
+
+// StartLinkChecker reads the link check settings and, unless they are disabled,
+// periodically probes the links submitted in running hackathons and hackathons
+// being judged.
+func StartLinkChecker() {
+	appConfig := config.GetConfig()
+	if _, err := toml.DecodeFile("config/env.default.toml", &appConfig); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	settings := appConfig.LinkCheck
+	if !settings.Enabled {
+		fmt.Println("link checker disabled")
+		return
+	}
+
+	interval, err := time.ParseDuration(settings.Interval)
+	if err != nil || interval <= 0 {
+		interval = time.Hour
+	}
+	timeout, err := time.ParseDuration(settings.Timeout)
+	if err != nil || timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	checker := links.NewChecker(timeout)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			CheckLinks(checker)
+			<-ticker.C
+		}
+	}()
+}
+
+// CheckLinks probes every submitted link of the hackathons in progress, one at a
+// time, and records how each one did
+func CheckLinks(checker *links.Checker) {
+	var checks []models.LinkCheck
+	if err := repo.ListLinksToCheck(&checks); err != nil {
+		fmt.Println("link checker failed: ", err)
+		return
+	}
+
+	broken := 0
+	for i := range checks {
+		result := checker.Check(context.Background(), checks[i].URL)
+		checks[i].Status, checks[i].StatusCode, checks[i].Error = result.Status, result.StatusCode, result.Error
+		checks[i].CheckedAt = time.Now()
+		if len(checks[i].Error) > 500 {
+			checks[i].Error = checks[i].Error[:500]
+		}
+		if result.Status != links.StatusOK {
+			broken++
+		}
+		if err := repo.SaveLinkCheck(&checks[i]); err != nil {
+			fmt.Printf("cannot record the check of %s: %v\n", checks[i].URL, err)
+		}
+	}
+	fmt.Printf("link checker probed %d links, %d of them broken\n", len(checks), broken)
+}
@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"time"
+	"win-a-thon/config"
+	"win-a-thon/repo"
+)
+
+// StartRetentionPurge reads the retention policy and, unless it is disabled,
+// periodically purges users and hackathons that have stayed soft-deleted for too long.
+func StartRetentionPurge() {
+	appConfig := config.GetConfig()
+	if _, err := toml.DecodeFile("config/env.default.toml", &appConfig); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	retention := appConfig.Retention
+	if retention.PurgeAfterDays <= 0 {
+		fmt.Println("retention purge disabled")
+		return
+	}
+
+	interval, err := time.ParseDuration(retention.Interval)
+	if err != nil || interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			PurgeExpired(retention.PurgeAfterDays)
+			<-ticker.C
+		}
+	}()
+}
+
+// PurgeExpired permanently removes records soft-deleted more than days ago.
+func PurgeExpired(days int) {
+	cutoff := time.Now().AddDate(0, 0, -days)
+	users, hackathons, err := repo.PurgeDeletedBefore(cutoff)
+	if err != nil {
+		fmt.Println("retention purge failed: ", err)
+		return
+	}
+	fmt.Printf("retention purge removed %d users and %d hackathons\n", users, hackathons)
+}
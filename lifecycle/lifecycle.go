@@ -0,0 +1,214 @@
+package lifecycle
+
+import (
+	"errors"
+	"time"
+	"win-a-thon/models"
+)
+
+// Statuses a hackathon moves through, in order
+const (
+	StatusDraft            = "draft"
+	StatusPendingApproval  = "pending_approval"
+	StatusApproved         = "approved"
+	StatusRegistrationOpen = "registration_open"
+	StatusRunning          = "running"
+	StatusJudging          = "judging"
+	StatusResultsPublished = "results_published"
+	StatusArchived         = "archived"
+)
+
+// StatusRejected is where an admin sends a hackathon back to its organiser, who can
+// revise it and request approval again
+const StatusRejected = "rejected"
+
+// StatusCancelled is where a hackathon ends up when its organiser calls it off. It
+// sits outside the timeline and no status follows it.
+const StatusCancelled = "cancelled"
+
+var order = map[string]int{
+	StatusDraft:            0,
+	StatusPendingApproval:  1,
+	StatusApproved:         2,
+	StatusRegistrationOpen: 3,
+	StatusRunning:          4,
+	StatusJudging:          5,
+	StatusResultsPublished: 6,
+	StatusArchived:         7,
+	StatusRejected:         0,
+	StatusCancelled:        -1,
+}
+
+// transitions lists, for every status, the statuses it may move to. The first one
+// is the next step on the timeline; approved hackathons that have not started yet
+// can also be sent back for approval when organisers make material changes, and
+// hackathons can be cancelled until their results are out.
+var transitions = map[string][]string{
+	StatusDraft:            {StatusPendingApproval, StatusCancelled},
+	StatusPendingApproval:  {StatusApproved, StatusRejected, StatusCancelled},
+	StatusRejected:         {StatusPendingApproval, StatusCancelled},
+	StatusApproved:         {StatusRegistrationOpen, StatusPendingApproval, StatusCancelled},
+	StatusRegistrationOpen: {StatusRunning, StatusPendingApproval, StatusCancelled},
+	StatusRunning:          {StatusJudging, StatusCancelled},
+	StatusJudging:          {StatusResultsPublished, StatusCancelled},
+	StatusResultsPublished: {StatusArchived},
+}
+
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// IsValid reports whether status is one of the lifecycle statuses
+func IsValid(status string) bool {
+	_, ok := order[status]
+	return ok
+}
+
+// IsApproved reports whether a hackathon in status has been approved by an admin
+func IsApproved(status string) bool {
+	return order[status] >= order[StatusApproved]
+}
+
+// CanTransition reports whether a hackathon may move from one status to the other
+func CanTransition(from string, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Stored returns the status saved on the hackathon. Rows created before statuses
+// existed have none and are read from the approval flag instead.
+func Stored(hackathon models.Hackathon) string {
+	if hackathon.Status != "" {
+		return hackathon.Status
+	}
+	if hackathon.AdminApproved {
+		return StatusApproved
+	}
+	return StatusPendingApproval
+}
+
+// Scheduled returns the status the timeline of an approved hackathon puts it in at now
+func Scheduled(hackathon models.Hackathon, now time.Time) string {
+	switch {
+	case now.Before(hackathon.StartingTime):
+		return StatusRegistrationOpen
+	case now.Before(hackathon.EndingTime):
+		return StatusRunning
+	case now.Before(hackathon.ResultTime):
+		return StatusJudging
+	default:
+		return StatusResultsPublished
+	}
+}
+
+// Current returns the status a hackathon is in at now. Statuses between approval
+// and results are driven by the timeline, so a stored status that has not been
+// advanced yet is caught up here; the others only change through transitions.
+func Current(hackathon models.Hackathon, now time.Time) string {
+	stored := Stored(hackathon)
+	if order[stored] < order[StatusApproved] || order[stored] > order[StatusResultsPublished] {
+		return stored
+	}
+	if scheduled := Scheduled(hackathon, now); order[scheduled] > order[stored] {
+		return scheduled
+	}
+	return stored
+}
+
+// Due returns the transitions that have to be applied, in order, to bring the
+// stored status of a hackathon up to its current status
+func Due(hackathon models.Hackathon, now time.Time) []string {
+	var due []string
+	status := Stored(hackathon)
+	current := Current(hackathon, now)
+	for status != current {
+		next := transitions[status][0]
+		due = append(due, next)
+		status = next
+	}
+	return due
+}
+
+// Action is something a user may try to do with a hackathon
+type Action string
+
+const (
+	ActionParticipate      Action = "participate"
+	ActionSubmit           Action = "submit"
+	ActionViewSubmissions  Action = "view_submissions"
+	ActionJudge            Action = "judge"
+	ActionViewResults      Action = "view_results"
+	ActionDistributePrizes Action = "distribute_prizes"
+	ActionArchive          Action = "archive"
+	ActionEdit             Action = "edit"
+	ActionEditMaterial     Action = "edit_material"
+	ActionEditRubric       Action = "edit_rubric"
+)
+
+// allowed lists the statuses in which each action may be performed
+var allowed = map[Action][]string{
+	ActionParticipate:      {StatusRegistrationOpen, StatusRunning},
+	ActionSubmit:           {StatusRunning},
+	ActionViewSubmissions:  {StatusJudging, StatusResultsPublished, StatusArchived},
+	ActionJudge:            {StatusJudging},
+	ActionViewResults:      {StatusResultsPublished, StatusArchived},
+	ActionDistributePrizes: {StatusResultsPublished, StatusArchived},
+	ActionArchive:          {StatusResultsPublished},
+	ActionEdit:             {StatusDraft, StatusRejected, StatusPendingApproval, StatusApproved, StatusRegistrationOpen, StatusRunning, StatusJudging, StatusResultsPublished},
+	ActionEditMaterial:     {StatusDraft, StatusRejected, StatusPendingApproval, StatusApproved, StatusRegistrationOpen},
+	ActionEditRubric:       {StatusDraft, StatusRejected, StatusPendingApproval, StatusApproved, StatusRegistrationOpen, StatusRunning},
+}
+
+// Reasons Guard refuses an action
+var (
+	ErrCancelled   = errors.New("hackathon has been cancelled")
+	ErrNotApproved = errors.New("hackathon is not approved")
+	ErrTooEarly    = errors.New("hackathon has not reached this stage yet")
+	ErrTooLate     = errors.New("hackathon is past this stage")
+)
+
+// Guard decides whether an action is allowed on a hackathon at now. It is the one
+// place controllers ask before acting on the hackathon timeline.
+func Guard(hackathon models.Hackathon, action Action, now time.Time) error {
+	current := Current(hackathon, now)
+	if current == StatusCancelled {
+		return ErrCancelled
+	}
+	statuses := allowed[action]
+	for _, status := range statuses {
+		if status == current {
+			return nil
+		}
+	}
+
+	if order[current] < order[StatusApproved] {
+		return ErrNotApproved
+	}
+	if len(statuses) > 0 && order[current] < order[statuses[0]] {
+		return ErrTooEarly
+	}
+	return ErrTooLate
+}
+
+// Reasons RegistrationGuard refuses a registration on top of those of Guard
+var (
+	ErrRegistrationNotOpen = errors.New("registration has not opened yet")
+	ErrRegistrationClosed  = errors.New("registration is closed")
+)
+
+// RegistrationGuard decides whether users may register for a hackathon at now. On
+// top of Guard, it keeps registrations within the window set by the organiser.
+func RegistrationGuard(hackathon models.Hackathon, now time.Time) error {
+	if err := Guard(hackathon, ActionParticipate, now); err != nil {
+		return err
+	}
+	if hackathon.RegistrationOpensAt != nil && now.Before(*hackathon.RegistrationOpensAt) {
+		return ErrRegistrationNotOpen
+	}
+	if hackathon.RegistrationClosesAt != nil && !now.Before(*hackathon.RegistrationClosesAt) {
+		return ErrRegistrationClosed
+	}
+	return nil
+}
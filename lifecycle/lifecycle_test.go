@@ -0,0 +1,102 @@
+package lifecycle
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"win-a-thon/models"
+)
+
+func newHackathon(status string, now time.Time) models.Hackathon {
+	return models.Hackathon{
+		StartingTime:  now.Add(time.Hour),
+		EndingTime:    now.Add(2 * time.Hour),
+		ResultTime:    now.Add(3 * time.Hour),
+		AdminApproved: IsApproved(status),
+		Status:        status,
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	require.True(t, CanTransition(StatusDraft, StatusPendingApproval))
+	require.True(t, CanTransition(StatusPendingApproval, StatusApproved))
+	require.True(t, CanTransition(StatusResultsPublished, StatusArchived))
+	require.True(t, CanTransition(StatusRegistrationOpen, StatusPendingApproval))
+	require.False(t, CanTransition(StatusRunning, StatusPendingApproval))
+	require.False(t, CanTransition(StatusDraft, StatusApproved))
+	require.False(t, CanTransition(StatusRunning, StatusRegistrationOpen))
+	require.False(t, CanTransition(StatusArchived, StatusDraft))
+	require.True(t, CanTransition(StatusRunning, StatusCancelled))
+	require.False(t, CanTransition(StatusResultsPublished, StatusCancelled))
+	require.False(t, CanTransition(StatusCancelled, StatusRunning))
+	require.True(t, CanTransition(StatusPendingApproval, StatusRejected))
+	require.True(t, CanTransition(StatusRejected, StatusPendingApproval))
+	require.False(t, CanTransition(StatusRejected, StatusApproved))
+}
+
+func TestCurrentFollowsTimeline(t *testing.T) {
+	now := time.Now()
+	hackathon := newHackathon(StatusApproved, now)
+
+	require.Equal(t, StatusRegistrationOpen, Current(hackathon, now))
+	require.Equal(t, StatusRunning, Current(hackathon, now.Add(90*time.Minute)))
+	require.Equal(t, StatusJudging, Current(hackathon, now.Add(150*time.Minute)))
+	require.Equal(t, StatusResultsPublished, Current(hackathon, now.Add(4*time.Hour)))
+
+	require.Equal(t, StatusPendingApproval, Current(newHackathon(StatusPendingApproval, now), now.Add(4*time.Hour)))
+	require.Equal(t, StatusArchived, Current(newHackathon(StatusArchived, now), now))
+}
+
+func TestStoredWithoutStatus(t *testing.T) {
+	require.Equal(t, StatusApproved, Stored(models.Hackathon{AdminApproved: true}))
+	require.Equal(t, StatusPendingApproval, Stored(models.Hackathon{}))
+}
+
+func TestDue(t *testing.T) {
+	now := time.Now()
+	hackathon := newHackathon(StatusRegistrationOpen, now)
+
+	require.Empty(t, Due(hackathon, now))
+	require.Equal(t, []string{StatusRunning, StatusJudging}, Due(hackathon, now.Add(150*time.Minute)))
+}
+
+func TestGuard(t *testing.T) {
+	now := time.Now()
+	hackathon := newHackathon(StatusApproved, now)
+
+	require.NoError(t, Guard(hackathon, ActionParticipate, now))
+	require.Equal(t, ErrTooEarly, Guard(hackathon, ActionSubmit, now))
+	require.NoError(t, Guard(hackathon, ActionSubmit, now.Add(90*time.Minute)))
+	require.Equal(t, ErrTooLate, Guard(hackathon, ActionParticipate, now.Add(150*time.Minute)))
+	require.NoError(t, Guard(hackathon, ActionJudge, now.Add(150*time.Minute)))
+	require.Equal(t, ErrTooLate, Guard(hackathon, ActionJudge, now.Add(4*time.Hour)))
+	require.NoError(t, Guard(hackathon, ActionDistributePrizes, now.Add(4*time.Hour)))
+	require.NoError(t, Guard(hackathon, ActionEditMaterial, now))
+	require.Equal(t, ErrTooLate, Guard(hackathon, ActionEditMaterial, now.Add(90*time.Minute)))
+	require.NoError(t, Guard(hackathon, ActionEdit, now.Add(4*time.Hour)))
+	require.NoError(t, Guard(hackathon, ActionEditRubric, now.Add(90*time.Minute)))
+	require.Equal(t, ErrTooLate, Guard(hackathon, ActionEditRubric, now.Add(150*time.Minute)))
+
+	require.Equal(t, ErrNotApproved, Guard(newHackathon(StatusDraft, now), ActionParticipate, now))
+	require.NoError(t, Guard(newHackathon(StatusArchived, now), ActionViewResults, now))
+	require.Equal(t, ErrTooLate, Guard(newHackathon(StatusArchived, now), ActionEdit, now))
+	require.NoError(t, Guard(newHackathon(StatusDraft, now), ActionEditMaterial, now))
+	require.Equal(t, ErrCancelled, Guard(newHackathon(StatusCancelled, now), ActionParticipate, now))
+	require.Equal(t, ErrCancelled, Guard(newHackathon(StatusCancelled, now), ActionSubmit, now))
+}
+
+func TestRegistrationGuard(t *testing.T) {
+	now := time.Now()
+	hackathon := newHackathon(StatusApproved, now)
+	require.NoError(t, RegistrationGuard(hackathon, now))
+	require.NoError(t, RegistrationGuard(hackathon, now.Add(90*time.Minute)))
+
+	opens, closes := now.Add(10*time.Minute), now.Add(30*time.Minute)
+	hackathon.RegistrationOpensAt = &opens
+	hackathon.RegistrationClosesAt = &closes
+	require.Equal(t, ErrRegistrationNotOpen, RegistrationGuard(hackathon, now))
+	require.NoError(t, RegistrationGuard(hackathon, now.Add(20*time.Minute)))
+	require.Equal(t, ErrRegistrationClosed, RegistrationGuard(hackathon, now.Add(30*time.Minute)))
+	require.Equal(t, ErrTooLate, RegistrationGuard(hackathon, now.Add(150*time.Minute)))
+	require.Equal(t, ErrCancelled, RegistrationGuard(newHackathon(StatusCancelled, now), now))
+}
@@ -0,0 +1,186 @@
+package links
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Statuses of a checked link
+const (
+	StatusOK      = "ok"
+	StatusBroken  = "broken"  // the link answered with an error or not at all
+	StatusBlocked = "blocked" // the link points at a private address, so it isn't probed
+)
+
+// Reasons Validate refuses a submitted link
+var (
+	ErrInvalid    = errors.New("Links should be well-formed URLs")
+	ErrNotHTTPS   = errors.New("Links should be HTTPS URLs")
+	ErrNotPublic  = errors.New("Links should point to a public host, not localhost or a private address")
+	ErrCredential = errors.New("Links can't hold a username or password")
+)
+
+// Address ranges that aren't reachable from the internet, or only reach this
+// server and its network. Links are never probed there.
+var private []*net.IPNet
+
+func init() {
+	for _, cidr := range []string{
+		"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12",
+		"192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "224.0.0.0/4", "240.0.0.0/4",
+		"::/128", "::1/128", "fc00::/7", "fe80::/10", "ff00::/8",
+	} {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		private = append(private, network)
+	}
+}
+
+// IsPublic reports whether an IP address can be probed, which it can unless it is
+// loopback, private, link-local or otherwise reserved
+func IsPublic(ip net.IP) bool {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	for _, network := range private {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks that a submitted link is a well-formed HTTPS URL to a public host.
+// Empty links are left for the participant to fill in later.
+func Validate(link string) error {
+	if link == "" {
+		return nil
+	}
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Host == "" || parsed.Hostname() == "" || strings.ContainsAny(link, " \t\r\n") {
+		return ErrInvalid
+	}
+	if !strings.EqualFold(parsed.Scheme, "https") {
+		return ErrNotHTTPS
+	}
+	if parsed.User != nil {
+		return ErrCredential
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal") {
+		return ErrNotPublic
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublic(ip) {
+		return ErrNotPublic
+	}
+	// hosts without a domain only resolve on a private network
+	if net.ParseIP(host) == nil && !strings.Contains(host, ".") {
+		return ErrNotPublic
+	}
+	return nil
+}
+
+// errBlocked is returned by the dialer of a Checker when a host resolves to a
+// private address
+var errBlocked = errors.New("the host resolves to a private address")
+
+// Result is the outcome of probing a link
+type Result struct {
+	Status     string
+	StatusCode int    // the HTTP status the link answered with, 0 when it didn't
+	Error      string // why the link is broken or blocked
+}
+
+// Checker probes links. It only ever connects to public addresses, whatever the
+// host resolves to when the connection is made, follows a few HTTPS redirects at
+// most and gives up on a link after the timeout it was made with.
+type Checker struct {
+	client *http.Client
+}
+
+// maxRedirects is how many redirects a Checker follows before calling the link broken
+const maxRedirects = 3
+
+// NewChecker returns a Checker giving up on each link after timeout
+func NewChecker(timeout time.Duration) *Checker {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		// the address is checked as the connection is made, after the name is resolved,
+		// so that a host can't resolve to a public address when validated and a
+		// private one when probed
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !IsPublic(ip) {
+				return errBlocked
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	return &Checker{client: &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return Validate(req.URL.String())
+		},
+	}}
+}
+
+// Check probes a link, asking for its headers only unless the server refuses to
+// answer a HEAD request
+func (checker *Checker) Check(ctx context.Context, link string) Result {
+	if err := Validate(link); err != nil {
+		return Result{Status: StatusBlocked, Error: err.Error()}
+	}
+
+	code, err := checker.probe(ctx, http.MethodHead, link)
+	if err == nil && (code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented) {
+		code, err = checker.probe(ctx, http.MethodGet, link)
+	}
+	switch {
+	case errors.Is(err, errBlocked):
+		return Result{Status: StatusBlocked, Error: errBlocked.Error()}
+	case err != nil:
+		return Result{Status: StatusBroken, Error: err.Error()}
+	case code >= 400:
+		return Result{Status: StatusBroken, StatusCode: code, Error: http.StatusText(code)}
+	}
+	return Result{Status: StatusOK, StatusCode: code}
+}
+
+func (checker *Checker) probe(ctx context.Context, method string, link string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "win-a-thon link checker")
+	resp, err := checker.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -1,9 +1,16 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"os"
+	"win-a-thon/blind"
+	"win-a-thon/blobstore"
 	"win-a-thon/database"
+	"win-a-thon/jobs"
 	"win-a-thon/routes"
+	"win-a-thon/scan"
+	"win-a-thon/seed"
 )
 
 var err error
@@ -15,9 +22,35 @@ func main() {
 		log.Fatal("Database creation failed.", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		seedCmd := flag.NewFlagSet("seed", flag.ExitOnError)
+		value := seedCmd.Int64("seed", 1, "seed value the demo data is generated from")
+		seedCmd.Parse(os.Args[2:])
+
+		if err := seed.Run(*value); err != nil {
+			log.Fatal("Seeding demo data failed.", err)
+		}
+		return
+	}
+
+	blobstore.Default, err = blobstore.GetStore()
+	if err != nil {
+		log.Fatal("Artifact store creation failed.", err)
+	}
+	scan.Setup()
+
+	blind.Secret, err = blind.GetSecret()
+	if err != nil {
+		log.Fatal("Blind judging setup failed.", err)
+	}
+
+	jobs.StartRetentionPurge()
+	jobs.StartLifecycleScheduler()
+	jobs.StartLinkChecker()
+
 	r, err := routes.Setup()
 	if err != nil {
-		log.Fatal("Token maker creation failed.", err)
+		log.Fatal("Router setup failed.", err)
 	}
 
 	r.Run()
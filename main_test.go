@@ -42,8 +42,12 @@ func Test(t *testing.T) {
 		" Hackathon", true)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
-			"time desc")).WithArgs(true).WillReturnRows(hackathonMockRow)
+		"SELECT count(*) FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND `hackathons`.`deleted_at` IS NULL")).WithArgs(true, "cancelled").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_" +
+			"time desc")).WithArgs(true, "cancelled").WillReturnRows(hackathonMockRow)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT count(*) FROM `participants` WHERE hackathon_id = ?")).WithArgs("1").WillReturnRows(sqlmock.NewRows(
@@ -65,6 +69,6 @@ func Test(t *testing.T) {
 	}
 	assert.Nil(t, err)
 
-	expected := "{\"hackathons\":[{\"id\":1,\"title\":\"\",\"starting_time\":\"2021-08-02T00:00:00Z\",\"ending_time\":\"2021-08-02T00:00:00Z\",\"participants\":1}],\"status\":\"successful\"}"
+	expected := "{\"hackathons\":[{\"id\":1,\"title\":\"\",\"starting_time\":\"2021-08-02T00:00:00Z\",\"ending_time\":\"2021-08-02T00:00:00Z\",\"participants\":1,\"seats_remaining\":null,\"registration_open\":false}],\"pagination\":{\"total\":1},\"status\":\"successful\"}"
 	assert.Equal(t, w.Body.String(), expected)
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Artifact is a file uploaded with the submission of an entry, like a pitch deck or
+// a build. The file itself is kept in the blob store under Key. Like scores, the
+// entry is a participant competing alone or a whole team.
+type Artifact struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HackathonID uint      `json:"hackathon_id" gorm:"not null;index:idx_artifact_entry"`
+	Hackathon   Hackathon `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID      uint      `json:"user_id" gorm:"index:idx_artifact_entry"` // 0 for teams
+	TeamID      uint      `json:"team_id" gorm:"index:idx_artifact_entry"` // 0 for participants competing alone
+	UploaderID  uint      `json:"uploader_id" gorm:"not null"`
+	Name        string    `json:"name" gorm:"type:varchar(255);not null"` // the name of the file as uploaded
+	ContentType string    `json:"content_type" gorm:"type:varchar(100)"`  // as sniffed from the content, not as claimed
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum" gorm:"type:varchar(64)"` // hex SHA-256 of the content
+	Key         string    `json:"-" gorm:"type:varchar(512);not null;uniqueIndex"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Assignment puts an entry in the review queue of a judge. Like scores, an entry is
+// a participant competing alone or a whole team.
+type Assignment struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HackathonID uint      `json:"hackathon_id" gorm:"not null;uniqueIndex:idx_assignment_entry"`
+	Hackathon   Hackathon `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	JudgeID     uint      `json:"judge_id" gorm:"not null;uniqueIndex:idx_assignment_entry"` // user ID of the judge
+	UserID      uint      `json:"user_id" gorm:"uniqueIndex:idx_assignment_entry"`           // 0 for teams
+	TeamID      uint      `json:"team_id" gorm:"uniqueIndex:idx_assignment_entry"`           // 0 for participants competing alone
+	CreatedAt   time.Time `json:"created_at"`
+}
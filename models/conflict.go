@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Conflict is a conflict of interest a judge declared with a participant of a
+// hackathon. Judges from the same organisation as a participant are conflicted
+// without declaring it, so those conflicts aren't stored.
+type Conflict struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HackathonID uint      `json:"hackathon_id" gorm:"not null;uniqueIndex:idx_conflict_pair"`
+	Hackathon   Hackathon `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	JudgeID     uint      `json:"judge_id" gorm:"not null;uniqueIndex:idx_conflict_pair"` // user ID of the judge
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_conflict_pair"`  // user ID of the participant
+	Reason      string    `json:"reason" gorm:"type:varchar(500)"`
+	CreatedAt   time.Time `json:"created_at"`
+}
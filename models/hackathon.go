@@ -7,13 +7,45 @@ import (
 
 type Hackathon struct {
 	gorm.Model
-	Title            string       `json:"title" gorm:"type:varchar(50);not null;unique"`
-	StartingTime     time.Time    `json:"starting_time"`
-	EndingTime       time.Time    `json:"ending_time"`
-	ResultTime       time.Time    `json:"result_time"`
-	OrganisationName string       `json:"organisation_name" gorm:"type:varchar(50)"`
-	OrganiserID      int          `json:"organiser_id" gorm:"not null"`
-	User             User         `gorm:"foreignKey:OrganiserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	Description      string       `json:"description" gorm:"not null"`
-	AdminApproved    bool `json:"admin_approved"`
+	Title              string    `json:"title" gorm:"type:varchar(50);not null;unique"`
+	StartingTime       time.Time `json:"starting_time"`
+	EndingTime         time.Time `json:"ending_time"`
+	ResultTime         time.Time `json:"result_time"`
+	OrganisationName   string    `json:"organisation_name" gorm:"type:varchar(50)"`
+	OrganiserID        int       `json:"organiser_id" gorm:"not null"`
+	User               User      `gorm:"foreignKey:OrganiserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Description        string    `json:"description" gorm:"not null"`
+	AdminApproved      bool      `json:"admin_approved"`
+	Status             string    `json:"status" gorm:"type:varchar(30);not null;index"`
+	CancellationReason string    `json:"cancellation_reason" gorm:"type:varchar(500)"`
+	MaxParticipants    int       `json:"max_participants"` // 0 means there is no limit
+	MinTeamSize        int       `json:"min_team_size"`    // up to 1 lets participants compete alone
+	MaxTeamSize        int       `json:"max_team_size"`    // 0 means there is no limit
+	// Registration opens on approval and closes when the hackathon ends unless
+	// the organiser sets a window
+	RegistrationOpensAt  *time.Time `json:"registration_opens_at"`
+	RegistrationClosesAt *time.Time `json:"registration_closes_at"`
+	// ScoreAggregation is how the scores of the judges are combined into the
+	// leaderboard score, the mean when empty
+	ScoreAggregation string `json:"score_aggregation" gorm:"type:varchar(20)"`
+	// ScoreNormalization puts the scores of every judge on a common scale before
+	// they are combined, they are taken as given when empty
+	ScoreNormalization string `json:"score_normalization" gorm:"type:varchar(20)"`
+	// ReviewsPerSubmission is how many judges each entry is assigned to, every
+	// judge reviews every entry when it is 0
+	ReviewsPerSubmission int `json:"reviews_per_submission"`
+	// BlindJudging hides who is behind each submission from the judges until the
+	// results are published
+	BlindJudging bool `json:"blind_judging"`
+	// TieBreakers order entries with the same score, separated by commas, as in
+	// "criterion:Innovation,earliest_submission". Tied entries share a rank without.
+	TieBreakers string `json:"tie_breakers" gorm:"type:varchar(200)"`
+	// The public votes for the People's Choice award from the end of the hackathon
+	// until VotingClosesAt, there is no vote when it is nil. Each voter casts one vote,
+	// or ranks up to VotesPerUser entries when it is more than 1.
+	VotingClosesAt *time.Time `json:"voting_closes_at"`
+	VotesPerUser   int        `json:"votes_per_user"`
+	// SubmissionsFrozenAt is when the final snapshot of the submissions was taken,
+	// nil until the hackathon ends
+	SubmissionsFrozenAt *time.Time `json:"submissions_frozen_at"`
 }
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// HackathonTransition records one change of status of a hackathon. ActorID is
+// nil for the transitions the scheduler applies as the timeline passes.
+type HackathonTransition struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HackathonID uint      `json:"hackathon_id" gorm:"not null;index"`
+	Hackathon   Hackathon `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	FromStatus  string    `json:"from_status" gorm:"type:varchar(30)"`
+	ToStatus    string    `json:"to_status" gorm:"type:varchar(30);not null"`
+	ActorID     *uint     `json:"actor_id"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Judge is a user the organiser invited to score the submissions of a hackathon
+type Judge struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HackathonID uint      `json:"hackathon_id" gorm:"not null;uniqueIndex:idx_judge_hackathon_user"`
+	Hackathon   Hackathon `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_judge_hackathon_user"`
+	User        User      `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// JudgeScore is the score one judge gave an entry, which is either a participant
+// competing alone or a whole team
+type JudgeScore struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HackathonID uint      `json:"hackathon_id" gorm:"not null;uniqueIndex:idx_judge_score_entry"`
+	Hackathon   Hackathon `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	JudgeID     uint      `json:"judge_id" gorm:"not null;uniqueIndex:idx_judge_score_entry"` // user ID of the judge
+	UserID      uint      `json:"user_id" gorm:"uniqueIndex:idx_judge_score_entry"`           // 0 for teams
+	TeamID      uint      `json:"team_id" gorm:"uniqueIndex:idx_judge_score_entry"`           // 0 for participants competing alone
+	Score       float64   `json:"score"`
+	Feedback    string    `json:"feedback" gorm:"type:text"` // shared with the participants once results are out
+	Notes       string    `json:"notes" gorm:"type:text"`    // private to the organiser
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// the points behind the score, when the hackathon has a rubric
+	Criteria []CriterionScore `json:"criteria,omitempty" gorm:"foreignKey:JudgeScoreID;constraint:OnDelete:CASCADE;"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// LinkCheck is the outcome of the last probe of a link of a submission, its code or
+// demo URL. Like scores, the entry is a participant competing alone or a whole team.
+type LinkCheck struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HackathonID uint      `json:"hackathon_id" gorm:"not null;uniqueIndex:idx_link_check"`
+	Hackathon   Hackathon `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID      uint      `json:"user_id" gorm:"uniqueIndex:idx_link_check"`                         // 0 for teams
+	TeamID      uint      `json:"team_id" gorm:"uniqueIndex:idx_link_check"`                         // 0 for participants competing alone
+	Field       string    `json:"field" gorm:"type:varchar(20);not null;uniqueIndex:idx_link_check"` // code_url or demo_url
+	URL         string    `json:"url" gorm:"type:varchar(2048)"`                                     // the link as it was when probed
+	Status      string    `json:"status" gorm:"type:varchar(20)"`
+	StatusCode  int       `json:"status_code"`
+	Error       string    `json:"error" gorm:"type:varchar(500)"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
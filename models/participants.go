@@ -1,11 +1,22 @@
 package models
 
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
 type Participant struct {
-	HackathonId int       `json:"hackathon_id" gorm:"primaryKey"`
-	Hackathon   Hackathon `gorm:"foreignKey:HackathonId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	UserId      int       `json:"user_id" gorm:"primaryKey"`
-	User        User      `gorm:"foreignKey:UserId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	DemoUrl     string    `json:"demo_url"`
-	CodeUrl     string    `json:"code_url"`
-	Score       int       `json:"score"`
+	HackathonId int            `json:"hackathon_id" gorm:"primaryKey"`
+	Hackathon   Hackathon      `gorm:"foreignKey:HackathonId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserId      int            `json:"user_id" gorm:"primaryKey"`
+	User        User           `gorm:"foreignKey:UserId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	DemoUrl     string         `json:"demo_url"`
+	CodeUrl     string         `json:"code_url"`
+	Score       float64        `json:"score"`                 // the judges' scores combined, once normalized
+	RawScore    float64        `json:"raw_score"`             // the judges' scores combined as they gave them
+	JudgeCount  int            `json:"judge_count"`           // how many judges scored the entry
+	TeamID      *uint          `json:"team_id" gorm:"index"`  // nil while competing alone
+	TrackID     *uint          `json:"track_id" gorm:"index"` // nil until a track is picked
+	SubmittedAt *time.Time     `json:"submitted_at"`          // when the participant last submitted alone
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 }
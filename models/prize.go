@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Prize is a reward of a hackathon. Ranked prizes go to the entries placed from
+// RankFrom to RankTo, overall or in a track. Special awards have no rank; the
+// organiser picks their winner, the Awardee, except for the People's Choice award
+// which goes to the entry with the most votes.
+type Prize struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HackathonID uint      `json:"hackathon_id" gorm:"not null;index"`
+	Hackathon   Hackathon `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TrackID     *uint     `json:"track_id"` // nil for prizes of the overall ranking
+	Title       string    `json:"title" gorm:"type:varchar(100);not null"`
+	RankFrom    int       `json:"rank_from"`
+	RankTo      int       `json:"rank_to"`
+	Amount      float64   `json:"amount" gorm:"type:decimal(12,2)"`
+	Currency    string    `json:"currency" gorm:"type:varchar(3)"`
+	Description string    `json:"description"`
+	AwardeeID   *uint     `json:"awardee_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	// PeoplesChoice makes a special award the People's Choice award
+	PeoplesChoice bool `json:"peoples_choice"`
+}
+
+// IsSpecial reports whether the prize is a special award rather than a ranked prize
+func (p Prize) IsSpecial() bool {
+	return p.RankFrom == 0
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Criterion is one line of the rubric judges score the submissions of a hackathon by
+type Criterion struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HackathonID uint      `json:"hackathon_id" gorm:"not null;uniqueIndex:idx_criterion_hackathon_name"`
+	Hackathon   Hackathon `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Name        string    `json:"name" gorm:"type:varchar(50);not null;uniqueIndex:idx_criterion_hackathon_name"`
+	MaxPoints   float64   `json:"max_points"`
+	Weight      float64   `json:"weight"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CriterionScore is the points a judge gave an entry for one criterion of the rubric
+type CriterionScore struct {
+	ID           uint      `json:"-" gorm:"primaryKey"`
+	JudgeScoreID uint      `json:"-" gorm:"not null;uniqueIndex:idx_criterion_score"`
+	CriterionID  uint      `json:"criterion_id" gorm:"not null;uniqueIndex:idx_criterion_score"`
+	Criterion    Criterion `json:"-" gorm:"foreignKey:CriterionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Points       float64   `json:"points"`
+}
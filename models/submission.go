@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// SubmissionRevision is a version of the submission of an entry, recorded every time
+// it is submitted. Like scores, an entry is a participant competing alone or a whole
+// team.
+type SubmissionRevision struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HackathonID uint      `json:"hackathon_id" gorm:"not null;index:idx_revision_entry"`
+	Hackathon   Hackathon `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID      uint      `json:"user_id" gorm:"index:idx_revision_entry"` // 0 for teams
+	TeamID      uint      `json:"team_id" gorm:"index:idx_revision_entry"` // 0 for participants competing alone
+	ActorID     uint      `json:"actor_id" gorm:"not null"`                // user ID of who submitted it
+	DemoUrl     string    `json:"demo_url"`
+	CodeUrl     string    `json:"code_url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SubmissionSnapshot is the final submission of an entry, frozen once the hackathon
+// ends. Judging acts on it, and it is never changed after it is taken.
+type SubmissionSnapshot struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	HackathonID uint       `json:"hackathon_id" gorm:"not null;uniqueIndex:idx_snapshot_entry"`
+	Hackathon   Hackathon  `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID      uint       `json:"user_id" gorm:"uniqueIndex:idx_snapshot_entry"` // 0 for teams
+	TeamID      uint       `json:"team_id" gorm:"uniqueIndex:idx_snapshot_entry"` // 0 for participants competing alone
+	DemoUrl     string     `json:"demo_url"`
+	CodeUrl     string     `json:"code_url"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+	FrozenAt    time.Time  `json:"frozen_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Team is a group of participants competing together in a hackathon. A team has a
+// single submission; its score is copied onto every member's Participant row.
+type Team struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HackathonID uint      `json:"hackathon_id" gorm:"not null;uniqueIndex:idx_team_hackathon_name"`
+	Hackathon   Hackathon `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Name        string    `json:"name" gorm:"type:varchar(50);not null;uniqueIndex:idx_team_hackathon_name"`
+	CaptainID   uint      `json:"captain_id" gorm:"not null"`
+	InviteCode  string    `json:"invite_code,omitempty" gorm:"type:varchar(32);not null;unique"`
+	DemoUrl     string    `json:"demo_url"`
+	CodeUrl     string    `json:"code_url"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// SubmittedAt is when the team last submitted, nil until it does
+	SubmittedAt *time.Time `json:"submitted_at"`
+}
+
+// TeamJoinRequest is a participant asking to join a team without an invite code.
+// It stays until the captain accepts or declines it.
+type TeamJoinRequest struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TeamID    uint      `json:"team_id" gorm:"not null;uniqueIndex:idx_join_request_team_user"`
+	Team      Team      `json:"-" gorm:"foreignKey:TeamID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_join_request_team_user"`
+	User      User      `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt time.Time `json:"created_at"`
+}
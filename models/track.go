@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Track is a category of a hackathon, such as "Fintech", that participants compete
+// in and that is ranked on its own
+type Track struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HackathonID uint      `json:"hackathon_id" gorm:"not null;uniqueIndex:idx_track_hackathon_name"`
+	Hackathon   Hackathon `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Name        string    `json:"name" gorm:"type:varchar(50);not null;uniqueIndex:idx_track_hackathon_name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"gorm.io/gorm"
+	"strings"
 )
 
 type User struct {
@@ -16,3 +17,9 @@ type User struct {
 	Organisation   string `json:"organisation"`
 	IsAdmin        bool   `json:"is_admin"`
 }
+
+// SameOrganisation reports whether two users gave the same organisation
+func (u User) SameOrganisation(other User) bool {
+	organisation := strings.TrimSpace(u.Organisation)
+	return organisation != "" && strings.EqualFold(organisation, strings.TrimSpace(other.Organisation))
+}
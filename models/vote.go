@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Vote is a choice of a user for the People's Choice award of a hackathon. Like
+// scores, the entry voted for is a participant competing alone or a whole team. A
+// ballot of ranked votes holds one vote per position, 1 being the first choice.
+type Vote struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HackathonID uint      `json:"hackathon_id" gorm:"not null;uniqueIndex:idx_vote_position;uniqueIndex:idx_vote_entry;index:idx_vote_ip"`
+	Hackathon   Hackathon `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	VoterID     uint      `json:"voter_id" gorm:"not null;uniqueIndex:idx_vote_position;uniqueIndex:idx_vote_entry"` // user ID of the voter
+	UserID      uint      `json:"user_id" gorm:"uniqueIndex:idx_vote_entry"`                                         // 0 for teams
+	TeamID      uint      `json:"team_id" gorm:"uniqueIndex:idx_vote_entry"`                                         // 0 for participants competing alone
+	Position    int       `json:"position" gorm:"uniqueIndex:idx_vote_position"`
+	IP          string    `json:"-" gorm:"type:varchar(45);index:idx_vote_ip"` // where the ballot was cast from
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// WaitlistEntry is a user queueing for a seat in a full hackathon. Entries are
+// promoted in the order of their IDs.
+type WaitlistEntry struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	HackathonID uint      `json:"hackathon_id" gorm:"not null;uniqueIndex:idx_waitlist_hackathon_user"`
+	Hackathon   Hackathon `json:"-" gorm:"foreignKey:HackathonID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_waitlist_hackathon_user"`
+	User        User      `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt   time.Time `json:"created_at"`
+}
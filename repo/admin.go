@@ -2,28 +2,25 @@ package repo
 
 import (
 	"win-a-thon/database"
+	"win-a-thon/lifecycle"
 	"win-a-thon/models"
 )
 
-func AdminAppoved(hackathonID string, value string) (err error) {
+func AdminAppoved(hackathonID string, adminID uint) (err error) {
 	var Hack models.Hackathon
 	err = database.DB.Where("id = ?", hackathonID).First(&Hack).Error
 	if err != nil {
 		return err
 	}
-	if value == "0" {
-		Hack.AdminApproved = false
-	} else {
-		Hack.AdminApproved = true
-	}
-	database.DB.Save(&Hack)
-	return nil
+	return TransitionHackathon(&Hack, lifecycle.StatusApproved, &adminID, "approved by admin")
 }
 
-func DeleteHackathonByAdmin(hackathonID string) (err error) {
-	var hackathon models.Hackathon
-	if err := database.DB.Where("id = ?", hackathonID).Delete(&hackathon).Error; err != nil {
+// RejectHackathon sends a hackathon awaiting approval back to its organiser with the reason given by the admin
+func RejectHackathon(hackathonID string, adminID uint, reason string) (err error) {
+	var Hack models.Hackathon
+	err = database.DB.Where("id = ?", hackathonID).First(&Hack).Error
+	if err != nil {
 		return err
 	}
-	return nil
+	return TransitionHackathon(&Hack, lifecycle.StatusRejected, &adminID, reason)
 }
@@ -33,10 +33,11 @@ func TestAdminAppoved(t *testing.T) {
 		"SELECT * FROM `hackathons` WHERE id = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1")).WithArgs("1").WillReturnRows(hackathonMockRow)
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE `hackathons` SET `created_at`=?,`updated_at`=?,`deleted_at`=?,`title`=?,`starting_time`=?,`ending_time`=?,`result_time`=?,`organisation_name`=?,`organiser_id`=?,`description`=?,`admin_approved`=? WHERE `id` = ?")).WithArgs().WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `hackathons` SET `admin_approved`=?,`status`=?,`updated_at`=? WHERE (id = ? AND status = ?) AND `hackathons`.`deleted_at` IS NULL")).WithArgs(true, "approved", AnyTime{}, 1, "pending_approval").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `hackathon_transitions` (`hackathon_id`,`from_status`,`to_status`,`actor_id`,`reason`,`created_at`) VALUES (?,?,?,?,?,?)")).WithArgs(1, "pending_approval", "approved", 2, "approved by admin", AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
-	err := AdminAppoved("1", "1")
+	err := AdminAppoved("1", 2)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"win-a-thon/assignment"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+// ErrTooManyArtifacts is returned when an entry would keep more files than allowed
+var ErrTooManyArtifacts = errors.New("too many artifacts")
+
+// AddArtifacts records files uploaded by an entry, all of them or, when the entry
+// would then keep more than max, none
+func AddArtifacts(artifacts []models.Artifact, max int) error {
+	if len(artifacts) == 0 {
+		return nil
+	}
+	first := artifacts[0]
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockHackathon(tx, first.HackathonID); err != nil {
+			return err
+		}
+
+		var kept int64
+		err := tx.Model(&models.Artifact{}).
+			Where("hackathon_id = ? AND user_id = ? AND team_id = ?", first.HackathonID, first.UserID, first.TeamID).
+			Count(&kept).Error
+		if err != nil {
+			return err
+		}
+		if int(kept)+len(artifacts) > max {
+			return ErrTooManyArtifacts
+		}
+		return tx.Create(&artifacts).Error
+	})
+}
+
+// ListArtifacts loads the files uploaded in a hackathon, oldest first
+func ListArtifacts(artifacts *[]models.Artifact, hackathonID uint) error {
+	return database.DB.Where("hackathon_id = ?", hackathonID).Order("id").Find(artifacts).Error
+}
+
+// ListEntryArtifacts loads the files uploaded by an entry, oldest first
+func ListEntryArtifacts(artifacts *[]models.Artifact, hackathonID uint, entry assignment.Entry) error {
+	return database.DB.Where("hackathon_id = ? AND user_id = ? AND team_id = ?", hackathonID, entry.UserID, entry.TeamID).
+		Order("id").Find(artifacts).Error
+}
+
+func ArtifactFromID(artifactID string, hackathonID uint) (models.Artifact, error) {
+	var artifact models.Artifact
+	err := database.DB.Where("id = ? AND hackathon_id = ?", artifactID, hackathonID).First(&artifact).Error
+	return artifact, err
+}
+
+func DeleteArtifact(artifact *models.Artifact) error {
+	return database.DB.Delete(artifact).Error
+}
+
+// DeleteArtifacts removes the records of files, as when the submission they were
+// uploaded with couldn't be saved
+func DeleteArtifacts(artifacts []models.Artifact) error {
+	if len(artifacts) == 0 {
+		return nil
+	}
+	return database.DB.Delete(&artifacts).Error
+}
@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+// expectArtifactCount expects the hackathon to be locked and the files kept by team 2
+// to be counted
+func expectArtifactCount(mock sqlmock.Sqlmock, kept int) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id` FROM `hackathons` WHERE id = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1 FOR UPDATE")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `artifacts` WHERE hackathon_id = ? AND user_id = ? AND team_id = ?")).
+		WithArgs(1, 0, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(kept))
+}
+
+func uploaded() []models.Artifact {
+	return []models.Artifact{
+		{HackathonID: 1, TeamID: 2, UploaderID: 9, Name: "deck.pdf", ContentType: "application/pdf", Size: 10, Checksum: "ab", Key: "hackathons/1/team-2/a"},
+		{HackathonID: 1, TeamID: 2, UploaderID: 9, Name: "build.zip", ContentType: "application/zip", Size: 20, Checksum: "cd", Key: "hackathons/1/team-2/b"},
+	}
+}
+
+func TestAddArtifacts(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	expectArtifactCount(mock, 8)
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `artifacts` (`hackathon_id`,`user_id`,`team_id`,`uploader_id`,`name`,`content_type`,`size`,`checksum`,`key`,`created_at`) VALUES (?,?,?,?,?,?,?,?,?,?),(?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs(1, 0, 2, 9, "deck.pdf", "application/pdf", 10, "ab", "hackathons/1/team-2/a", AnyTime{},
+			1, 0, 2, 9, "build.zip", "application/zip", 20, "cd", "hackathons/1/team-2/b", AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+
+	require.NoError(t, AddArtifacts(uploaded(), 10))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddArtifactsTooMany(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	expectArtifactCount(mock, 9)
+	mock.ExpectRollback()
+
+	require.Equal(t, ErrTooManyArtifacts, AddArtifacts(uploaded(), 10))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,145 @@
+package repo
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"win-a-thon/assignment"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+// ErrNotAssigned is returned when a judge scores an entry outside their review queue
+var ErrNotAssigned = errors.New("entry not assigned to judge")
+
+// EntryOf is the entry a participant competes in
+func EntryOf(participant models.Participant) assignment.Entry {
+	if participant.TeamID != nil {
+		return assignment.Entry{TeamID: *participant.TeamID}
+	}
+	return assignment.Entry{UserID: uint(participant.UserId)}
+}
+
+// AssignJudges tops up the review queues of the judges of a hackathon until every
+// entry with a submission is assigned to hackathon.ReviewsPerSubmission judges.
+// Assignments already made stay, so running it again after a judge dropped out
+// reassigns their entries. Judges aren't assigned entries they have a conflict
+// of interest with.
+//
+// It returns how many assignments were made and the entries left short of judges.
+func AssignJudges(hackathon models.Hackathon) (int, []assignment.Entry, error) {
+	var made int
+	var short []assignment.Entry
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockHackathon(tx, hackathon.ID); err != nil {
+			return err
+		}
+
+		var participants []models.Participant
+		var teams []models.Team
+		var judges []models.Judge
+		var existing []models.Assignment
+		var conflicts []models.Conflict
+		if err := tx.Preload("User").Where("hackathon_id = ?", hackathon.ID).Find(&participants).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("hackathon_id = ?", hackathon.ID).Find(&teams).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("User").Where("hackathon_id = ?", hackathon.ID).Order("user_id").Find(&judges).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("hackathon_id = ?", hackathon.ID).Find(&existing).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("hackathon_id = ?", hackathon.ID).Find(&conflicts).Error; err != nil {
+			return err
+		}
+
+		submitted := make(map[uint]bool)
+		for _, team := range teams {
+			submitted[team.ID] = team.CodeUrl != "" || team.DemoUrl != ""
+		}
+
+		// only entries with a submission are reviewed
+		var entries []assignment.Entry
+		members := make(map[assignment.Entry][]models.User)
+		for _, participant := range participants {
+			entry := EntryOf(participant)
+			if _, ok := members[entry]; !ok {
+				if entry.TeamID != 0 && !submitted[entry.TeamID] ||
+					entry.TeamID == 0 && participant.CodeUrl == "" && participant.DemoUrl == "" {
+					continue
+				}
+				entries = append(entries, entry)
+			}
+			members[entry] = append(members[entry], participant.User)
+		}
+
+		judgeIDs := make([]uint, len(judges))
+		judgeUsers := make(map[uint]models.User)
+		for i, judge := range judges {
+			judgeIDs[i] = judge.UserID
+			judgeUsers[judge.UserID] = judge.User
+		}
+
+		assigned := make(map[assignment.Entry][]uint)
+		for _, a := range existing {
+			entry := assignment.Entry{UserID: a.UserID, TeamID: a.TeamID}
+			assigned[entry] = append(assigned[entry], a.JudgeID)
+		}
+
+		type pair struct{ judgeID, userID uint }
+		declared := make(map[pair]bool)
+		for _, conflict := range conflicts {
+			declared[pair{conflict.JudgeID, conflict.UserID}] = true
+		}
+
+		conflicted := func(judgeID uint, entry assignment.Entry) bool {
+			for _, member := range members[entry] {
+				if judgeUsers[judgeID].SameOrganisation(member) || declared[pair{judgeID, member.ID}] {
+					return true
+				}
+			}
+			return false
+		}
+
+		planned, left := assignment.Plan(entries, judgeIDs, hackathon.ReviewsPerSubmission, assigned, conflicted)
+		short = left
+
+		var rows []models.Assignment
+		for _, entry := range entries {
+			for _, judgeID := range planned[entry] {
+				rows = append(rows, models.Assignment{HackathonID: hackathon.ID, JudgeID: judgeID, UserID: entry.UserID, TeamID: entry.TeamID})
+			}
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		made = len(rows)
+		return tx.Create(&rows).Error
+	})
+	return made, short, err
+}
+
+// ListAssignments loads the review queues of all the judges of a hackathon
+func ListAssignments(assignments *[]models.Assignment, hackathonID uint) error {
+	return database.DB.Where("hackathon_id = ?", hackathonID).Order("judge_id, id").Find(assignments).Error
+}
+
+// JudgeQueue loads the review queue of one judge
+func JudgeQueue(assignments *[]models.Assignment, hackathonID uint, judgeID uint) error {
+	return database.DB.Where("hackathon_id = ? AND judge_id = ?", hackathonID, judgeID).Order("id").Find(assignments).Error
+}
+
+// AssignedSubmissions loads the participants whose entries are in the review queue
+// of a judge
+func AssignedSubmissions(participants *[]models.Participant, hackathonID uint, judgeID uint) error {
+	users := database.DB.Model(&models.Assignment{}).Select("user_id").
+		Where("hackathon_id = ? AND judge_id = ? AND team_id = 0", hackathonID, judgeID)
+	teams := database.DB.Model(&models.Assignment{}).Select("team_id").
+		Where("hackathon_id = ? AND judge_id = ? AND team_id <> 0", hackathonID, judgeID)
+	return database.DB.Where("hackathon_id = ?", hackathonID).
+		Where(database.DB.Where("team_id IS NULL AND user_id IN (?)", users).Or("team_id IN (?)", teams)).
+		Find(participants).Error
+}
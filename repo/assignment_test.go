@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"win-a-thon/assignment"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+func TestAssignJudgesSkipsConflicts(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id` FROM `hackathons` WHERE id = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1 FOR UPDATE")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	// two participants submitted, one hasn't
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "code_url"}).
+			AddRow(1, 7, "https://github.com/a/b").
+			AddRow(1, 8, "https://github.com/c/d").
+			AddRow(1, 9, ""))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE `users`.`id` IN (?,?,?) AND `users`.`deleted_at` IS NULL")).
+		WithArgs(7, 8, 9).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "organisation"}).
+			AddRow(7, "ana", "Razorpay").
+			AddRow(8, "ben", "Fintech Labs").
+			AddRow(9, "cal", ""))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `teams` WHERE hackathon_id = ?")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	// the judge from Razorpay can't review ana
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `judges` WHERE hackathon_id = ? ORDER BY user_id")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "user_id"}).
+			AddRow(1, 1, 20).
+			AddRow(2, 1, 21))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE `users`.`id` IN (?,?) AND `users`.`deleted_at` IS NULL")).
+		WithArgs(20, 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "organisation"}).
+			AddRow(20, "judy", "razorpay ").
+			AddRow(21, "jack", "Acme"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `assignments` WHERE hackathon_id = ?")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "judge_id", "user_id", "team_id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `conflicts` WHERE hackathon_id = ?")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "judge_id", "user_id"}))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `assignments` (`hackathon_id`,`judge_id`,`user_id`,`team_id`,`created_at`) VALUES (?,?,?,?,?),(?,?,?,?,?),(?,?,?,?,?)")).
+		WithArgs(1, 21, 7, 0, AnyTime{}, 1, 20, 8, 0, AnyTime{}, 1, 21, 8, 0, AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 3))
+	mock.ExpectCommit()
+
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}, ReviewsPerSubmission: 2}
+	made, short, err := AssignJudges(hackathon)
+	require.NoError(t, err)
+	require.Equal(t, 3, made)
+	require.Equal(t, []assignment.Entry{{UserID: 7}}, short)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJudgeSubmissonOutsideQueue(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(7, "name"))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).
+		WithArgs(1, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "team_id"}).AddRow(1, 7, 4))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `assignments` WHERE hackathon_id = ? AND judge_id = ? AND user_id = ? AND team_id = ?")).
+		WithArgs(1, 5, 0, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}, OrganiserID: 2, ReviewsPerSubmission: 3}
+	judged := models.JudgeScore{JudgeID: 5, Score: 6}
+	err := JudgeSubmisson(&judged, hackathon, "name")
+	require.Equal(t, ErrNotAssigned, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
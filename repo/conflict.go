@@ -0,0 +1,88 @@
+package repo
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"win-a-thon/assignment"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+// ErrConflicted is returned when a judge scores an entry they have a conflict of
+// interest with
+var ErrConflicted = errors.New("judge has a conflict of interest with entry")
+
+// DeclareConflict records a conflict of interest a judge declared with a participant.
+// The participant's entry leaves the judge's review queue, and any score the judge
+// already gave it is discarded.
+func DeclareConflict(conflict *models.Conflict, hackathon models.Hackathon, participant models.Participant) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(conflict).Error; err != nil {
+			return err
+		}
+
+		entry := EntryOf(participant)
+		err := tx.Where("hackathon_id = ? AND judge_id = ? AND user_id = ? AND team_id = ?",
+			hackathon.ID, conflict.JudgeID, entry.UserID, entry.TeamID).Delete(&models.Assignment{}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("hackathon_id = ? AND judge_id = ? AND user_id = ? AND team_id = ?",
+			hackathon.ID, conflict.JudgeID, entry.UserID, entry.TeamID).Delete(&models.JudgeScore{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		// the entry is combined again on its own first, as rescoring the hackathon only
+		// reaches entries with scores left and this may have been its only one
+		if err := aggregateEntry(tx, hackathon, entry.UserID, entry.TeamID); err != nil {
+			return err
+		}
+		if hackathon.ScoreNormalization != "" {
+			return rescoreHackathon(tx, hackathon)
+		}
+		return nil
+	})
+}
+
+// ListConflicts loads the conflicts of interest the judges of a hackathon declared
+func ListConflicts(conflicts *[]models.Conflict, hackathonID uint) error {
+	return database.DB.Where("hackathon_id = ?", hackathonID).Order("judge_id, user_id").Find(conflicts).Error
+}
+
+// entryConflicted reports whether a judge has a conflict of interest with an entry,
+// either declared, because the judge is a member of the entry, or because a member
+// is from the judge's organisation
+func entryConflicted(hackathonID uint, judgeID uint, entry assignment.Entry) (bool, error) {
+	var judge models.User
+	if err := database.DB.First(&judge, judgeID).Error; err != nil {
+		return false, err
+	}
+
+	var members []models.User
+	tx := database.DB.Where("id = ?", entry.UserID)
+	if entry.TeamID != 0 {
+		team := database.DB.Model(&models.Participant{}).Select("user_id").Where("team_id = ?", entry.TeamID)
+		tx = database.DB.Where("id IN (?)", team)
+	}
+	if err := tx.Find(&members).Error; err != nil {
+		return false, err
+	}
+
+	ids := make([]uint, len(members))
+	for i, member := range members {
+		// judges never score an entry they are part of
+		if member.ID == judgeID || judge.SameOrganisation(member) {
+			return true, nil
+		}
+		ids[i] = member.ID
+	}
+	if len(ids) == 0 {
+		return false, nil
+	}
+
+	var count int64
+	err := database.DB.Model(&models.Conflict{}).Where("hackathon_id = ? AND judge_id = ? AND user_id IN ?", hackathonID, judgeID, ids).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,251 @@
+package repo
+
+import (
+	"database/sql/driver"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"strings"
+	"testing"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+// expectNoConflict expects the checks for a conflict of interest between a judge
+// from Acme and an entry, whose members are from no organisation and weren't
+// declared a conflict with
+func expectNoConflict(mock sqlmock.Sqlmock, hackathonID uint, judgeID uint, teamID uint, members ...uint) {
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs(judgeID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "organisation"}).AddRow(judgeID, "judge", "Acme"))
+
+	rows := sqlmock.NewRows([]string{"id", "username"})
+	args := []driver.Value{hackathonID, judgeID}
+	for _, member := range members {
+		rows.AddRow(member, "member")
+		args = append(args, member)
+	}
+	if teamID != 0 {
+		mock.ExpectQuery(regexp.QuoteMeta(
+			"SELECT * FROM `users` WHERE id IN (SELECT `user_id` FROM `participants` WHERE team_id = ? AND `participants`.`deleted_at` IS NULL) AND `users`.`deleted_at` IS NULL")).
+			WithArgs(teamID).
+			WillReturnRows(rows)
+	} else {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE id = ? AND `users`.`deleted_at` IS NULL")).
+			WithArgs(members[0]).
+			WillReturnRows(rows)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `conflicts` WHERE hackathon_id = ? AND judge_id = ? AND user_id IN (" +
+			strings.TrimSuffix(strings.Repeat("?,", len(members)), ",") + ")")).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+}
+
+func TestJudgeSubmissonSameOrganisation(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(7, "name"))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).
+		WithArgs(1, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "team_id"}).AddRow(1, 7, 4))
+
+	// one of the team members works with the judge
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "organisation"}).AddRow(5, "judy", "Razorpay"))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE id IN (SELECT `user_id` FROM `participants` WHERE team_id = ? AND `participants`.`deleted_at` IS NULL) AND `users`.`deleted_at` IS NULL")).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "organisation"}).
+			AddRow(7, "name", "").
+			AddRow(8, "ben", " razorpay"))
+
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}, OrganiserID: 2}
+	judged := models.JudgeScore{JudgeID: 5, Score: 6}
+	err := JudgeSubmisson(&judged, hackathon, "name")
+	require.Equal(t, ErrConflicted, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJudgeSubmissonDeclaredConflict(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(7, "name"))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).
+		WithArgs(1, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id"}).AddRow(1, 7))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "organisation"}).AddRow(5, "judy", "Razorpay"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE id = ? AND `users`.`deleted_at` IS NULL")).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "organisation"}).AddRow(7, "name", "Acme"))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `conflicts` WHERE hackathon_id = ? AND judge_id = ? AND user_id IN (?)")).
+		WithArgs(1, 5, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}, OrganiserID: 2}
+	judged := models.JudgeScore{JudgeID: 5, Score: 6}
+	err := JudgeSubmisson(&judged, hackathon, "name")
+	require.Equal(t, ErrConflicted, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJudgeSubmissonOwnEntry(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(7, "name"))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).
+		WithArgs(1, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "team_id"}).AddRow(1, 7, 4))
+
+	// the judge, from no organisation, is on the team
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "organisation"}).AddRow(5, "judy", ""))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE id IN (SELECT `user_id` FROM `participants` WHERE team_id = ? AND `participants`.`deleted_at` IS NULL) AND `users`.`deleted_at` IS NULL")).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "organisation"}).
+			AddRow(7, "name", "").
+			AddRow(5, "judy", ""))
+
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}, OrganiserID: 2}
+	judged := models.JudgeScore{JudgeID: 5, Score: 6}
+	err := JudgeSubmisson(&judged, hackathon, "name")
+	require.Equal(t, ErrConflicted, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeclareConflict(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `conflicts` (`hackathon_id`,`judge_id`,`user_id`,`reason`,`created_at`) VALUES (?,?,?,?,?)")).
+		WithArgs(1, 5, 7, "My cousin", AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// the team leaves the judge's queue
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `assignments` WHERE hackathon_id = ? AND judge_id = ? AND user_id = ? AND team_id = ?")).
+		WithArgs(1, 5, 0, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// the score the judge gave is discarded and the team is scored by the others
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `judge_scores` WHERE hackathon_id = ? AND judge_id = ? AND user_id = ? AND team_id = ?")).
+		WithArgs(1, 5, 0, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `score` FROM `judge_scores` WHERE hackathon_id = ? AND user_id = ? AND team_id = ?")).
+		WithArgs(1, 0, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"score"}).AddRow(30))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `participants` SET `judge_count`=?,`raw_score`=?,`score`=? WHERE team_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(1, 30.0, 30.0, 4).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	team := uint(4)
+	conflict := models.Conflict{HackathonID: 1, JudgeID: 5, UserID: 7, Reason: "My cousin"}
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}}
+	err := DeclareConflict(&conflict, hackathon, models.Participant{HackathonId: 1, UserId: 7, TeamID: &team})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeclareConflictOnlyScoreNormalized(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `conflicts` (`hackathon_id`,`judge_id`,`user_id`,`reason`,`created_at`) VALUES (?,?,?,?,?)")).
+		WithArgs(1, 5, 7, "My cousin", AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `assignments` WHERE hackathon_id = ? AND judge_id = ? AND user_id = ? AND team_id = ?")).
+		WithArgs(1, 5, 7, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `judge_scores` WHERE hackathon_id = ? AND judge_id = ? AND user_id = ? AND team_id = ?")).
+		WithArgs(1, 5, 7, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// that was the only score of the participant, who is left unscored
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `score` FROM `judge_scores` WHERE hackathon_id = ? AND user_id = ? AND team_id = ?")).
+		WithArgs(1, 7, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"score"}))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `participants` SET `judge_count`=?,`raw_score`=?,`score`=? WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(0, 0.0, 0.0, 1, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// and the others are scored again, on the scale without the discarded score
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `judge_scores` WHERE hackathon_id = ? ORDER BY id")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "judge_id", "user_id", "team_id", "score"}))
+	mock.ExpectCommit()
+
+	conflict := models.Conflict{HackathonID: 1, JudgeID: 5, UserID: 7, Reason: "My cousin"}
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}, ScoreNormalization: "z_score"}
+	err := DeclareConflict(&conflict, hackathon, models.Participant{HackathonId: 1, UserId: 7})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,129 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"time"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+func ListDeletedUsers(users *[]models.User) error {
+	err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at desc").Find(users).Error
+	return err
+}
+
+func ListDeletedHackathons(hackathons *[]models.Hackathon) error {
+	err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at desc").Find(hackathons).Error
+	return err
+}
+
+// RestoreUser un-deletes a soft-deleted user together with the participant rows
+// of every hackathon that is still live.
+func RestoreUser(userID string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&models.User{}).Where("id = ? AND deleted_at IS NOT NULL", userID).Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Unscoped().Model(&models.Participant{}).
+			Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+			Where("hackathon_id IN (?)", tx.Model(&models.Hackathon{}).Select("id")).
+			Update("deleted_at", nil).Error
+	})
+}
+
+// RestoreHackathon un-deletes a soft-deleted hackathon together with the
+// participant rows of every user that is still live.
+func RestoreHackathon(hackathonID string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&models.Hackathon{}).Where("id = ? AND deleted_at IS NOT NULL", hackathonID).Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Unscoped().Model(&models.Participant{}).
+			Where("hackathon_id = ? AND deleted_at IS NOT NULL", hackathonID).
+			Where("user_id IN (?)", tx.Model(&models.User{}).Select("id")).
+			Update("deleted_at", nil).Error
+	})
+}
+
+// purgeUserRows removes the votes, conflicts of interest, judge assignments and
+// judge scores of the users selected by ids, whether they cast, declared or gave
+// them or are the entry they are about. The totals of the entries stay as scored.
+func purgeUserRows(tx *gorm.DB, ids *gorm.DB) error {
+	if err := tx.Where("voter_id IN (?) OR user_id IN (?)", ids, ids).Delete(&models.Vote{}).Error; err != nil {
+		return err
+	}
+	for _, rows := range []interface{}{&models.Conflict{}, &models.Assignment{}, &models.JudgeScore{}} {
+		if err := tx.Where("judge_id IN (?) OR user_id IN (?)", ids, ids).Delete(rows).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgeUser permanently removes a soft-deleted user along with their votes,
+// conflicts, assignments and judge scores. Participant rows, organised hackathons
+// and notification devices go with it through the foreign key cascades.
+func PurgeUser(userID string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		ids := tx.Unscoped().Model(&models.User{}).Select("id").Where("id = ? AND deleted_at IS NOT NULL", userID)
+		if err := purgeUserRows(tx, ids); err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", userID).Delete(&models.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// PurgeHackathon permanently removes a soft-deleted hackathon and its participant rows.
+func PurgeHackathon(hackathonID string) error {
+	result := database.DB.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", hackathonID).Delete(&models.Hackathon{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PurgeDeletedBefore permanently removes every user and hackathon that was
+// soft-deleted before the cutoff, along with the rows purgeUserRows removes for
+// the users, and returns how many of each were removed.
+func PurgeDeletedBefore(cutoff time.Time) (int64, int64, error) {
+	var users, hackathons int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(&models.Hackathon{})
+		if result.Error != nil {
+			return result.Error
+		}
+		hackathons = result.RowsAffected
+
+		ids := tx.Unscoped().Model(&models.User{}).Select("id").Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+		if err := purgeUserRows(tx, ids); err != nil {
+			return err
+		}
+
+		result = tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(&models.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		users = result.RowsAffected
+		return nil
+	})
+	return users, hackathons, err
+}
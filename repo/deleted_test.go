@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+func TestListDeletedUsers(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	userRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "username"}).
+		AddRow(1, time.Now(), time.Now(), time.Now(), "abcdef")
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE deleted_at IS NOT NULL ORDER BY deleted_at desc")).
+		WillReturnRows(userRows)
+
+	var users []models.User
+	err := ListDeletedUsers(&users)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreHackathon(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	t.Run("restores participants of live users", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(
+			"UPDATE `hackathons` SET `deleted_at`=?,`updated_at`=? WHERE id = ? AND deleted_at IS NOT NULL")).
+			WithArgs(nil, AnyTime{}, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(
+			"UPDATE `participants` SET `deleted_at`=? WHERE (hackathon_id = ? AND deleted_at IS NOT NULL) AND user_id IN (SELECT `id` FROM `users` WHERE `users`.`deleted_at` IS NULL)")).
+			WithArgs(nil, "1").WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+
+		err := RestoreHackathon("1")
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not deleted", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(
+			"UPDATE `hackathons` SET `deleted_at`=?,`updated_at`=? WHERE id = ? AND deleted_at IS NOT NULL")).
+			WithArgs(nil, AnyTime{}, "2").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := RestoreHackathon("2")
+		require.Equal(t, gorm.ErrRecordNotFound, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPurgeUser(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	purged := "SELECT `id` FROM `users` WHERE id = ? AND deleted_at IS NOT NULL"
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `votes` WHERE voter_id IN ("+purged+") OR user_id IN ("+purged+")")).
+		WithArgs("1", "1").WillReturnResult(sqlmock.NewResult(0, 3))
+	for _, table := range []string{"conflicts", "assignments", "judge_scores"} {
+		mock.ExpectExec(regexp.QuoteMeta(
+			"DELETE FROM `"+table+"` WHERE judge_id IN ("+purged+") OR user_id IN ("+purged+")")).
+			WithArgs("1", "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `users` WHERE id = ? AND deleted_at IS NOT NULL")).
+		WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := PurgeUser("1")
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeDeletedBefore(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	cutoff := time.Date(2021, 8, 2, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `hackathons` WHERE deleted_at IS NOT NULL AND deleted_at < ?")).
+		WithArgs(cutoff).WillReturnResult(sqlmock.NewResult(0, 2))
+	// the votes, conflicts, assignments and scores of the purged users go in the same transaction
+	purged := "SELECT `id` FROM `users` WHERE deleted_at IS NOT NULL AND deleted_at < ?"
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `votes` WHERE voter_id IN ("+purged+") OR user_id IN ("+purged+")")).
+		WithArgs(cutoff, cutoff).WillReturnResult(sqlmock.NewResult(0, 3))
+	for _, table := range []string{"conflicts", "assignments", "judge_scores"} {
+		mock.ExpectExec(regexp.QuoteMeta(
+			"DELETE FROM `"+table+"` WHERE judge_id IN ("+purged+") OR user_id IN ("+purged+")")).
+			WithArgs(cutoff, cutoff).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `users` WHERE deleted_at IS NOT NULL AND deleted_at < ?")).
+		WithArgs(cutoff).WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectCommit()
+
+	users, hackathons, err := PurgeDeletedBefore(cutoff)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, users)
+	require.EqualValues(t, 2, hackathons)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,14 +1,29 @@
 package repo
 
 import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"time"
 	"win-a-thon/database"
+	"win-a-thon/lifecycle"
 	"win-a-thon/models"
+	"win-a-thon/utils"
 )
 
-func ListHackathons(hackathon *[]models.Hackathon, adminApproved bool) error {
-	err := database.DB.Where("admin_approved = ?", adminApproved).Order("starting_" +
-		"time desc").Find(hackathon).Error
-	return err
+// ErrInvalidCursor is returned for a cursor that doesn't point into the listing,
+// like one from a listing sorted another way
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+func ListHackathons(hackathon *[]models.Hackathon, adminApproved bool, query utils.HackathonListQuery) (int64, string, error) {
+	tx := database.DB.Where("admin_approved = ?", adminApproved)
+	if adminApproved {
+		tx = tx.Where("status <> ?", lifecycle.StatusCancelled)
+	} else {
+		// drafts and rejected hackathons are still with their organisers, not in the review queue
+		tx = tx.Where("status = ?", lifecycle.StatusPendingApproval)
+	}
+	return listHackathonsPage(tx, hackathon, query)
 }
 
 func ViewHackathonDetails(hackathon *models.Hackathon, HackathonID string) (error, int64) {
@@ -23,17 +38,172 @@ func ViewHackathonDetails(hackathon *models.Hackathon, HackathonID string) (erro
 }
 
 func CreateHackathon(hackathon *models.Hackathon) error {
-	err := database.DB.Create(hackathon).Error
-	return err
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(hackathon).Error; err != nil {
+			return err
+		}
+		organiserID := uint(hackathon.OrganiserID)
+		return tx.Create(&models.HackathonTransition{
+			HackathonID: hackathon.ID,
+			ToStatus:    hackathon.Status,
+			ActorID:     &organiserID,
+			Reason:      "created",
+		}).Error
+	})
 }
 
-func ListOrganisedHackathons(hackathon *[]models.Hackathon, id uint) error {
-	err := database.DB.Where("organiser_id = ?", id).Order("starting_" +
-		"time desc").Find(hackathon).Error
-	return err
+// UpdateHackathon saves the editable fields of updated over the hackathon. When
+// reapprove is set the hackathon also goes back to pending approval, in the same
+// transaction, on behalf of actorID.
+func UpdateHackathon(hackathon *models.Hackathon, updated models.Hackathon, reapprove bool, actorID uint) error {
+	from := lifecycle.Stored(*hackathon)
+	if reapprove && !lifecycle.CanTransition(from, lifecycle.StatusPendingApproval) {
+		return lifecycle.ErrInvalidTransition
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Hackathon{}).Where("id = ?", hackathon.ID).
+			Select("title", "description", "organisation_name", "starting_time", "ending_time", "result_time",
+				"registration_opens_at", "registration_closes_at", "score_aggregation", "score_normalization", "reviews_per_submission",
+				"blind_judging", "tie_breakers", "voting_closes_at", "votes_per_user").
+			Updates(updated).Error
+		if err != nil {
+			return err
+		}
+		if updated.ScoreAggregation != hackathon.ScoreAggregation || updated.ScoreNormalization != hackathon.ScoreNormalization {
+			if err := rescoreHackathon(tx, updated); err != nil {
+				return err
+			}
+		}
+		if !reapprove {
+			return nil
+		}
+		return transitionHackathon(tx, hackathon.ID, from, lifecycle.StatusPendingApproval, &actorID, "edited, awaiting re-approval")
+	})
+	if err != nil {
+		return err
+	}
+
+	hackathon.Title = updated.Title
+	hackathon.Description = updated.Description
+	hackathon.OrganisationName = updated.OrganisationName
+	hackathon.StartingTime = updated.StartingTime
+	hackathon.EndingTime = updated.EndingTime
+	hackathon.ResultTime = updated.ResultTime
+	hackathon.RegistrationOpensAt = updated.RegistrationOpensAt
+	hackathon.RegistrationClosesAt = updated.RegistrationClosesAt
+	hackathon.ScoreAggregation = updated.ScoreAggregation
+	hackathon.ScoreNormalization = updated.ScoreNormalization
+	hackathon.ReviewsPerSubmission = updated.ReviewsPerSubmission
+	hackathon.BlindJudging = updated.BlindJudging
+	hackathon.TieBreakers = updated.TieBreakers
+	hackathon.VotingClosesAt = updated.VotingClosesAt
+	hackathon.VotesPerUser = updated.VotesPerUser
+	if reapprove {
+		hackathon.Status = lifecycle.StatusPendingApproval
+		hackathon.AdminApproved = false
+	}
+	return nil
+}
+
+func ListOrganisedHackathons(hackathon *[]models.Hackathon, id uint, query utils.HackathonListQuery) (int64, string, error) {
+	return listHackathonsPage(database.DB.Where("organiser_id = ?", id), hackathon, query)
 }
 
 func CreateParticipant(participant *models.Participant) (err error) {
 	result := database.DB.Create(participant)
 	return result.Error
 }
+
+// listHackathonsPage applies the filters of a validated query on top of tx and loads
+// the page that follows the cursor. It returns the number of hackathons matching the
+// filters and the cursor of the next page, which is empty on the last page.
+func listHackathonsPage(tx *gorm.DB, hackathons *[]models.Hackathon, query utils.HackathonListQuery) (int64, string, error) {
+	tx = tx.Model(&models.Hackathon{})
+
+	now := time.Now()
+	switch query.Status {
+	case utils.StatusUpcoming:
+		tx = tx.Where("starting_time > ?", now)
+	case utils.StatusActive:
+		tx = tx.Where("starting_time <= ? AND ending_time > ?", now, now)
+	case utils.StatusJudging:
+		tx = tx.Where("ending_time <= ? AND result_time > ?", now, now)
+	case utils.StatusCompleted:
+		tx = tx.Where("result_time <= ?", now)
+	}
+
+	if query.OrganisationName != "" {
+		tx = tx.Where("organisation_name = ?", query.OrganisationName)
+	}
+
+	// from and to select the hackathons taking place at some point within the range
+	if !query.From.IsZero() {
+		tx = tx.Where("ending_time >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		tx = tx.Where("starting_time <= ?", query.To)
+	}
+
+	tx = tx.Session(&gorm.Session{})
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return 0, "", err
+	}
+
+	order := utils.HackathonSorts[query.Sort]
+	direction, comparison := "asc", ">"
+	if order.Desc {
+		direction, comparison = "desc", "<"
+	}
+
+	page := tx
+	if query.Cursor != "" {
+		cursor, err := utils.DecodeCursor(query.Cursor)
+		if err != nil {
+			return 0, "", ErrInvalidCursor
+		}
+		var value interface{} = cursor.Value
+		if order.Column != "title" {
+			if value, err = time.Parse(time.RFC3339Nano, cursor.Value); err != nil {
+				return 0, "", ErrInvalidCursor
+			}
+		}
+		page = page.Where(fmt.Sprintf("%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?)", order.Column, comparison), value, value, cursor.ID)
+	}
+
+	err := page.Order(order.Column + " " + direction).Order("id " + direction).Limit(query.Limit + 1).Find(hackathons).Error
+	if err != nil {
+		return 0, "", err
+	}
+
+	if len(*hackathons) <= query.Limit {
+		return total, "", nil
+	}
+
+	*hackathons = (*hackathons)[:query.Limit]
+	last := (*hackathons)[query.Limit-1]
+	cursor := utils.Cursor{ID: last.ID}
+	switch order.Column {
+	case "title":
+		cursor.Value = last.Title
+	case "starting_time":
+		cursor.Value = last.StartingTime.Format(time.RFC3339Nano)
+	case "ending_time":
+		cursor.Value = last.EndingTime.Format(time.RFC3339Nano)
+	case "created_at":
+		cursor.Value = last.CreatedAt.Format(time.RFC3339Nano)
+	}
+	return total, utils.EncodeCursor(cursor), nil
+}
+
+func ListSearchableHackathons(hackathons *[]models.Hackathon) error {
+	err := database.DB.Where("admin_approved = ?", true).Where("status <> ?", lifecycle.StatusCancelled).Find(hackathons).Error
+	return err
+}
+
+func HackathonsFromIDs(hackathons *[]models.Hackathon, ids []uint) error {
+	err := database.DB.Where("id IN ? AND admin_approved = ?", ids, true).Where("status <> ?", lifecycle.StatusCancelled).Find(hackathons).Error
+	return err
+}
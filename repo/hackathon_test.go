@@ -12,6 +12,7 @@ import (
 	"time"
 	"win-a-thon/database"
 	"win-a-thon/models"
+	"win-a-thon/utils"
 )
 
 var mock sqlmock.Sqlmock
@@ -38,14 +39,19 @@ func TestListHackathons(t *testing.T) {
 	hackathonMockRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "titles", "starting_time", "ending_time", "result_time", "organisation_name", "organiser_id", "description", "admin_approved"}).AddRow("1", created_at, created_at, created_at, "Test Hackathon", created_at, created_at, created_at, "Winathon", "1", "It is a test Hackathon", "1")
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_time desc")).WithArgs(true).WillReturnRows(hackathonMockRows)
+		"SELECT count(*) FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND `hackathons`.`deleted_at` IS NULL")).WithArgs(true, "cancelled").WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_time desc,id desc LIMIT 21")).WithArgs(true, "cancelled").WillReturnRows(hackathonMockRows)
 
 	var hackathons []models.Hackathon
 
-	err := ListHackathons(&hackathons, true)
+	query := utils.HackathonListQuery{Sort: utils.DefaultHackathonSort, Limit: utils.DefaultPageSize}
+	total, nextCursor, err := ListHackathons(&hackathons, true, query)
 	if err != nil {
 		log.Fatal(err)
 	}
+	require.EqualValues(t, 1, total)
+	require.Empty(t, nextCursor)
 	err = mock.ExpectationsWereMet()
 	if err != nil {
 		t.Errorf("Failed to meet expectations, got error: %v", err)
@@ -69,12 +75,16 @@ func TestCreateHackathon(t *testing.T) {
 		OrganiserID:      1,
 		Description:      "Lord of the Mysteries",
 		AdminApproved:    false,
+		Status:           "pending_approval",
 	}
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `hackathons` (`created_at`,`updated_at`,`deleted_at`,`title`,`starting_time`,`ending_time`,`result_time`,`organisation_name`,`organiser_id`,`description`,`admin_approved`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `hackathons` (`created_at`,`updated_at`,`deleted_at`,`title`,`starting_time`,`ending_time`,`result_time`,`organisation_name`,`organiser_id`,`description`,`admin_approved`,`status`,`cancellation_reason`,`max_participants`,`min_team_size`,`max_team_size`,`registration_opens_at`,`registration_closes_at`,`score_aggregation`,`score_normalization`,`reviews_per_submission`,`blind_judging`,`tie_breakers`,`voting_closes_at`,`votes_per_user`,`submissions_frozen_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")).
 		WithArgs().
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `hackathon_transitions` (`hackathon_id`,`from_status`,`to_status`,`actor_id`,`reason`,`created_at`) VALUES (?,?,?,?,?,?)")).
+		WithArgs(1, "", "pending_approval", 1, "created", AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
 	err = CreateHackathon(&hackathon)
@@ -92,7 +102,7 @@ func TestCreateParticipant(t *testing.T) {
 	participant.UserId = 1
 	participant.HackathonId = 1
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `participants` (`hackathon_id`,`user_id`,`demo_url`,`code_url`,`score`) VALUES (?,?,?,?,?)")).WithArgs(participant.HackathonId, participant.UserId, participant.DemoUrl, participant.CodeUrl, participant.Score).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `participants` (`hackathon_id`,`user_id`,`demo_url`,`code_url`,`score`,`raw_score`,`judge_count`,`team_id`,`track_id`,`submitted_at`,`deleted_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).WithArgs(participant.HackathonId, participant.UserId, participant.DemoUrl, participant.CodeUrl, participant.Score, 0.0, 0, nil, nil, nil, nil).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 	if err = CreateParticipant(&participant); err != nil {
 		t.Errorf("error was not expected while updating stats: %s", err)
@@ -153,12 +163,18 @@ func TestListOrganisedHackathons(t *testing.T) {
 		" Hackathon", true)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `hackathons` WHERE (organiser_id = ?) AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_time desc")).
+		"SELECT count(*) FROM `hackathons` WHERE (organiser_id = ?) AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE (organiser_id = ?) AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_time desc,id desc LIMIT 21")).
 		WithArgs(1).
 		WillReturnRows(hackathonMockRow)
 
 	var hackathons []models.Hackathon
-	err := ListOrganisedHackathons(&hackathons, 1)
+	query := utils.HackathonListQuery{Sort: utils.DefaultHackathonSort, Limit: utils.DefaultPageSize}
+	_, _, err := ListOrganisedHackathons(&hackathons, 1, query)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -167,3 +183,76 @@ func TestListOrganisedHackathons(t *testing.T) {
 		t.Errorf("Failed to meet expectations, got error: %v", err)
 	}
 }
+
+func TestListHackathonsPage(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	first := time.Date(2021, 8, 4, 0, 0, 0, 0, time.UTC)
+	second := time.Date(2021, 8, 9, 0, 0, 0, 0, time.UTC)
+	hackathonMockRows := sqlmock.NewRows([]string{"id", "title", "starting_time", "organisation_name"}).
+		AddRow(3, "First", first, "Winathon").
+		AddRow(5, "Second", second, "Winathon")
+
+	cursor := utils.EncodeCursor(utils.Cursor{Value: "2021-08-01T00:00:00Z", ID: 2})
+	query := utils.HackathonListQuery{
+		Status:           utils.StatusUpcoming,
+		OrganisationName: "Winathon",
+		Sort:             "starting_time_asc",
+		Limit:            1,
+		Cursor:           cursor,
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND starting_time > ? AND (organisation_name = ?) AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(true, "cancelled", AnyTime{}, "Winathon").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(7))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND starting_time > ? AND (organisation_name = ?) AND (starting_time > ? OR (starting_time = ? AND id > ?)) AND `hackathons`.`deleted_at` IS NULL ORDER BY starting_time asc,id asc LIMIT 2")).
+		WithArgs(true, "cancelled", AnyTime{}, "Winathon", AnyTime{}, AnyTime{}, 2).
+		WillReturnRows(hackathonMockRows)
+
+	var hackathons []models.Hackathon
+	total, nextCursor, err := ListHackathons(&hackathons, true, query)
+	require.NoError(t, err)
+	require.EqualValues(t, 7, total)
+	require.Len(t, hackathons, 1)
+
+	next, err := utils.DecodeCursor(nextCursor)
+	require.NoError(t, err)
+	require.Equal(t, utils.Cursor{Value: "2021-08-04T00:00:00Z", ID: 3}, next)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListHackathonsPageCursorOfOtherSort(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	// a cursor of the listing sorted by title holds no time
+	cursor := utils.EncodeCursor(utils.Cursor{Value: "Second", ID: 5})
+	query := utils.HackathonListQuery{Sort: "starting_time_asc", Limit: 1, Cursor: cursor}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `hackathons` WHERE admin_approved = ? AND status <> ? AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(true, "cancelled").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(7))
+
+	var hackathons []models.Hackathon
+	_, _, err := ListHackathons(&hackathons, true, query)
+	require.Equal(t, ErrInvalidCursor, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,136 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"win-a-thon/database"
+	"win-a-thon/models"
+	"win-a-thon/scoring"
+)
+
+func AddJudge(judge *models.Judge) error {
+	return database.DB.Create(judge).Error
+}
+
+// IsJudge reports whether a user was invited to judge a hackathon
+func IsJudge(hackathonID uint, userID uint) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.Judge{}).Where("hackathon_id = ? AND user_id = ?", hackathonID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+// ListJudges loads the users invited to judge a hackathon
+func ListJudges(users *[]models.User, hackathonID uint) error {
+	judges := database.DB.Model(&models.Judge{}).Select("user_id").Where("hackathon_id = ?", hackathonID)
+	return database.DB.Where("id IN (?)", judges).Order("username").Find(users).Error
+}
+
+// RemoveJudge takes a judge off a hackathon along with their review queue and the
+// scores they gave, and combines the scores left for the entries they had judged
+func RemoveJudge(hackathon models.Hackathon, userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("hackathon_id = ? AND user_id = ?", hackathon.ID, userID).Delete(&models.Judge{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Where("hackathon_id = ? AND judge_id = ?", hackathon.ID, userID).Delete(&models.Assignment{}).Error; err != nil {
+			return err
+		}
+
+		var scores []models.JudgeScore
+		if err := tx.Where("hackathon_id = ? AND judge_id = ?", hackathon.ID, userID).Find(&scores).Error; err != nil {
+			return err
+		}
+		if len(scores) == 0 {
+			return nil
+		}
+		if err := tx.Delete(&scores).Error; err != nil {
+			return err
+		}
+		for _, score := range scores {
+			if err := aggregateEntry(tx, hackathon, score.UserID, score.TeamID); err != nil {
+				return err
+			}
+		}
+		if hackathon.ScoreNormalization != "" {
+			return rescoreHackathon(tx, hackathon)
+		}
+		return nil
+	})
+}
+
+// scoreEntry updates the score of an entry after its judges changed. Normalized
+// scores depend on every score each judge gave, so with normalization the whole
+// hackathon is rescored.
+func scoreEntry(tx *gorm.DB, hackathon models.Hackathon, userID uint, teamID uint) error {
+	if hackathon.ScoreNormalization != "" {
+		return rescoreHackathon(tx, hackathon)
+	}
+	return aggregateEntry(tx, hackathon, userID, teamID)
+}
+
+// aggregateEntry combines the scores the judges gave an entry into the score of its
+// participants, as they were given. An entry is a participant competing alone or,
+// when teamID isn't 0, a whole team.
+func aggregateEntry(tx *gorm.DB, hackathon models.Hackathon, userID uint, teamID uint) error {
+	var scores []float64
+	err := tx.Model(&models.JudgeScore{}).Where("hackathon_id = ? AND user_id = ? AND team_id = ?", hackathon.ID, userID, teamID).
+		Pluck("score", &scores).Error
+	if err != nil {
+		return err
+	}
+
+	score := scoring.Aggregate(hackathon.ScoreAggregation, scores)
+	return updateEntry(tx, hackathon.ID, userID, teamID, score, score, len(scores))
+}
+
+// updateEntry stores the combined scores of an entry on all of its participants
+func updateEntry(tx *gorm.DB, hackathonID uint, userID uint, teamID uint, score float64, rawScore float64, judges int) error {
+	entry := tx.Model(&models.Participant{})
+	if teamID != 0 {
+		entry = entry.Where("team_id = ?", teamID)
+	} else {
+		entry = entry.Where("hackathon_id = ? AND user_id = ?", hackathonID, userID)
+	}
+	return entry.Updates(map[string]interface{}{
+		"score":       score,
+		"raw_score":   rawScore,
+		"judge_count": judges,
+	}).Error
+}
+
+// rescoreHackathon combines the scores of every entry of a hackathon again, after
+// the way they are combined changed or, with normalization, after any of them did
+func rescoreHackathon(tx *gorm.DB, hackathon models.Hackathon) error {
+	var scores []models.JudgeScore
+	if err := tx.Where("hackathon_id = ?", hackathon.ID).Order("id").Find(&scores).Error; err != nil {
+		return err
+	}
+	normalized := scoring.Normalize(hackathon.ScoreNormalization, scores)
+
+	type entry struct{ userID, teamID uint }
+	var entries []entry
+	raw := make(map[entry][]float64)
+	scaled := make(map[entry][]float64)
+	for i, score := range scores {
+		key := entry{score.UserID, score.TeamID}
+		if _, ok := raw[key]; !ok {
+			entries = append(entries, key)
+		}
+		raw[key] = append(raw[key], score.Score)
+		scaled[key] = append(scaled[key], normalized[i])
+	}
+
+	for _, key := range entries {
+		err := updateEntry(tx, hackathon.ID, key.userID, key.teamID,
+			scoring.Aggregate(hackathon.ScoreAggregation, scaled[key]),
+			scoring.Aggregate(hackathon.ScoreAggregation, raw[key]),
+			len(raw[key]))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
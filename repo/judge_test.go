@@ -0,0 +1,161 @@
+package repo
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+func TestRemoveJudgeRescoresEntries(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `judges` WHERE hackathon_id = ? AND user_id = ?")).
+		WithArgs(1, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `assignments` WHERE hackathon_id = ? AND judge_id = ?")).
+		WithArgs(1, 5).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `judge_scores` WHERE hackathon_id = ? AND judge_id = ?")).
+		WithArgs(1, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "judge_id", "user_id", "team_id", "score"}).
+			AddRow(11, 1, 5, 7, 0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `judge_scores` WHERE `judge_scores`.`id` = ?")).
+		WithArgs(11).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `score` FROM `judge_scores` WHERE hackathon_id = ? AND user_id = ? AND team_id = ?")).
+		WithArgs(1, 7, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"score"}).AddRow(8).AddRow(9))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `participants` SET `judge_count`=?,`raw_score`=?,`score`=? WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(2, 8.5, 8.5, 1, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}}
+	err := RemoveJudge(hackathon, 5)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJudgeSubmissonWithRubric(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(7, "name"))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).
+		WithArgs(1, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id"}).AddRow(1, 7))
+	expectNoConflict(mock, 1, 5, 0, 7)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `judge_scores` (`hackathon_id`,`judge_id`,`user_id`,`team_id`,`score`,`feedback`,`notes`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `score`=VALUES(`score`),`feedback`=VALUES(`feedback`),`notes`=VALUES(`notes`),`updated_at`=VALUES(`updated_at`)")).
+		WithArgs(1, 5, 7, 0, 70.0, "", "", AnyTime{}, AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	// the points given before are replaced
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id` FROM `judge_scores` WHERE hackathon_id = ? AND judge_id = ? AND user_id = ? AND team_id = ? ORDER BY `judge_scores`.`id` LIMIT 1")).
+		WithArgs(1, 5, 7, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `criterion_scores` WHERE judge_score_id = ?")).
+		WithArgs(12).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `criterion_scores` (`judge_score_id`,`criterion_id`,`points`) VALUES (?,?,?),(?,?,?)")).
+		WithArgs(12, 1, 8.0, 12, 2, 2.0).
+		WillReturnResult(sqlmock.NewResult(30, 2))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `score` FROM `judge_scores` WHERE hackathon_id = ? AND user_id = ? AND team_id = ?")).
+		WithArgs(1, 7, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"score"}).AddRow(70))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `participants` SET `judge_count`=?,`raw_score`=?,`score`=? WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(1, 70.0, 70.0, 1, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	judged := models.JudgeScore{JudgeID: 5, Score: 70, Criteria: []models.CriterionScore{{CriterionID: 1, Points: 8}, {CriterionID: 2, Points: 2}}}
+	err := JudgeSubmisson(&judged, models.Hackathon{Model: gorm.Model{ID: 1}}, "name")
+	require.NoError(t, err)
+	require.EqualValues(t, 12, judged.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJudgeSubmissonNormalizedRescoresHackathon(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs("name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(7, "name"))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).
+		WithArgs(1, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id"}).AddRow(1, 7))
+	expectNoConflict(mock, 1, 5, 0, 7)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `judge_scores` (`hackathon_id`,`judge_id`,`user_id`,`team_id`,`score`,`feedback`,`notes`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `score`=VALUES(`score`),`feedback`=VALUES(`feedback`),`notes`=VALUES(`notes`),`updated_at`=VALUES(`updated_at`)")).
+		WithArgs(1, 5, 7, 0, 4.0, "", "", AnyTime{}, AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	// judge 5 is harsh, yet the best entry they scored outranks the only one judge 6 scored
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `judge_scores` WHERE hackathon_id = ? ORDER BY id")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "judge_id", "user_id", "team_id", "score"}).
+			AddRow(1, 1, 5, 8, 0, 2).
+			AddRow(2, 1, 6, 9, 0, 10).
+			AddRow(3, 1, 5, 7, 0, 4))
+	for _, entry := range [][]interface{}{{8, 2.0, 0.0}, {9, 10.0, 50.0}, {7, 4.0, 100.0}} {
+		mock.ExpectExec(regexp.QuoteMeta(
+			"UPDATE `participants` SET `judge_count`=?,`raw_score`=?,`score`=? WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL")).
+			WithArgs(1, entry[1], entry[2], 1, entry[0]).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}, ScoreNormalization: "rank"}
+	judged := models.JudgeScore{JudgeID: 5, Score: 4}
+	err := JudgeSubmisson(&judged, hackathon, "name")
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"time"
+	"win-a-thon/database"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+)
+
+// TransitionHackathon moves a hackathon to a new status and records the change in
+// its history. Transitions the lifecycle does not allow are refused with
+// lifecycle.ErrInvalidTransition.
+func TransitionHackathon(hackathon *models.Hackathon, to string, actorID *uint, reason string) error {
+	from := lifecycle.Stored(*hackathon)
+	if !lifecycle.CanTransition(from, to) {
+		return lifecycle.ErrInvalidTransition
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return transitionHackathon(tx, hackathon.ID, from, to, actorID, reason)
+	})
+	if err != nil {
+		return err
+	}
+
+	hackathon.Status = to
+	if to != lifecycle.StatusCancelled {
+		hackathon.AdminApproved = lifecycle.IsApproved(to)
+	}
+	return nil
+}
+
+// CancelHackathon cancels a hackathon on behalf of actorID and stores the reason given
+func CancelHackathon(hackathon *models.Hackathon, reason string, actorID uint) error {
+	from := lifecycle.Stored(*hackathon)
+	if !lifecycle.CanTransition(from, lifecycle.StatusCancelled) {
+		return lifecycle.ErrInvalidTransition
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Hackathon{}).Where("id = ?", hackathon.ID).Update("cancellation_reason", reason).Error; err != nil {
+			return err
+		}
+		return transitionHackathon(tx, hackathon.ID, from, lifecycle.StatusCancelled, &actorID, reason)
+	})
+	if err != nil {
+		return err
+	}
+
+	hackathon.Status = lifecycle.StatusCancelled
+	hackathon.CancellationReason = reason
+	return nil
+}
+
+// transitionHackathon writes an already validated status change and its history entry
+// within tx. It fails with lifecycle.ErrInvalidTransition when the hackathon is no
+// longer in from, having been moved on by someone else in the meantime.
+func transitionHackathon(tx *gorm.DB, hackathonID uint, from string, to string, actorID *uint, reason string) error {
+	// cancelling leaves the approval flag as it was, it is kept for history
+	updates := map[string]interface{}{"status": to}
+	if to != lifecycle.StatusCancelled {
+		updates["admin_approved"] = lifecycle.IsApproved(to)
+	}
+	result := tx.Model(&models.Hackathon{}).Where("id = ? AND status = ?", hackathonID, from).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return lifecycle.ErrInvalidTransition
+	}
+	return tx.Create(&models.HackathonTransition{
+		HackathonID: hackathonID,
+		FromStatus:  from,
+		ToStatus:    to,
+		ActorID:     actorID,
+		Reason:      reason,
+	}).Error
+}
+
+// AdvanceHackathon applies, one by one, the transitions the timeline of a hackathon
+// has made due by now, and freezes its submissions once it has ended
+func AdvanceHackathon(hackathon *models.Hackathon, now time.Time) error {
+	for _, status := range lifecycle.Due(*hackathon, now) {
+		if err := TransitionHackathon(hackathon, status, nil, "scheduled"); err != nil {
+			return err
+		}
+	}
+	if lifecycle.Guard(*hackathon, lifecycle.ActionViewSubmissions, now) == nil {
+		return FreezeSubmissions(hackathon, now)
+	}
+	return nil
+}
+
+// ListHackathonsInProgress loads the approved hackathons whose status still moves with their timeline
+func ListHackathonsInProgress(hackathons *[]models.Hackathon) error {
+	statuses := []string{lifecycle.StatusApproved, lifecycle.StatusRegistrationOpen, lifecycle.StatusRunning, lifecycle.StatusJudging}
+	err := database.DB.Where("status IN ?", statuses).Find(hackathons).Error
+	return err
+}
+
+func ListHackathonTransitions(transitions *[]models.HackathonTransition, hackathonID uint) error {
+	err := database.DB.Where("hackathon_id = ?", hackathonID).Order("id").Find(transitions).Error
+	return err
+}
+
+// ListHackathonReviews loads the approval requests and admin decisions of the given
+// hackathons, oldest first
+func ListHackathonReviews(reviews *[]models.HackathonTransition, hackathonIDs []uint) error {
+	statuses := []string{lifecycle.StatusPendingApproval, lifecycle.StatusApproved, lifecycle.StatusRejected}
+	err := database.DB.Where("hackathon_id IN ? AND to_status IN ?", hackathonIDs, statuses).Order("id").Find(reviews).Error
+	return err
+}
@@ -0,0 +1,101 @@
+package repo
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+	"win-a-thon/database"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+)
+
+func TestTransitionHackathonInvalid(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}, Status: lifecycle.StatusDraft}
+	err := TransitionHackathon(&hackathon, lifecycle.StatusApproved, nil, "")
+	require.Equal(t, lifecycle.ErrInvalidTransition, err)
+	require.Equal(t, lifecycle.StatusDraft, hackathon.Status)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransitionHackathonMovedMeanwhile(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	// the hackathon was cancelled after the scheduler loaded it as registration_open
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `hackathons` SET `admin_approved`=?,`status`=?,`updated_at`=? WHERE (id = ? AND status = ?) AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(true, lifecycle.StatusRunning, AnyTime{}, 1, lifecycle.StatusRegistrationOpen).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}, AdminApproved: true, Status: lifecycle.StatusRegistrationOpen}
+	err := TransitionHackathon(&hackathon, lifecycle.StatusRunning, nil, "scheduled")
+	require.Equal(t, lifecycle.ErrInvalidTransition, err)
+	require.Equal(t, lifecycle.StatusRegistrationOpen, hackathon.Status)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdvanceHackathon(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	now := time.Now()
+	hackathon := models.Hackathon{
+		Model:         gorm.Model{ID: 1},
+		StartingTime:  now.Add(-time.Hour),
+		EndingTime:    now.Add(time.Hour),
+		ResultTime:    now.Add(2 * time.Hour),
+		AdminApproved: true,
+		Status:        lifecycle.StatusApproved,
+	}
+
+	for _, step := range [][2]string{
+		{lifecycle.StatusApproved, lifecycle.StatusRegistrationOpen},
+		{lifecycle.StatusRegistrationOpen, lifecycle.StatusRunning},
+	} {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(
+			"UPDATE `hackathons` SET `admin_approved`=?,`status`=?,`updated_at`=? WHERE (id = ? AND status = ?) AND `hackathons`.`deleted_at` IS NULL")).
+			WithArgs(true, step[1], AnyTime{}, 1, step[0]).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(
+			"INSERT INTO `hackathon_transitions` (`hackathon_id`,`from_status`,`to_status`,`actor_id`,`reason`,`created_at`) VALUES (?,?,?,?,?,?)")).
+			WithArgs(1, step[0], step[1], nil, "scheduled", AnyTime{}).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+	}
+
+	err := AdvanceHackathon(&hackathon, now)
+	require.NoError(t, err)
+	require.Equal(t, lifecycle.StatusRunning, hackathon.Status)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
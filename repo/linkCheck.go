@@ -0,0 +1,62 @@
+package repo
+
+import (
+	"gorm.io/gorm/clause"
+	"win-a-thon/database"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+)
+
+// ListLinksToCheck lists the code and demo links submitted in hackathons that are
+// running or being judged, as unchecked link checks
+func ListLinksToCheck(checks *[]models.LinkCheck) error {
+	var hackathonIDs []uint
+	statuses := []string{lifecycle.StatusRunning, lifecycle.StatusJudging}
+	if err := database.DB.Model(&models.Hackathon{}).Where("status IN ?", statuses).Pluck("id", &hackathonIDs).Error; err != nil {
+		return err
+	}
+	if len(hackathonIDs) == 0 {
+		return nil
+	}
+
+	var participants []models.Participant
+	var teams []models.Team
+	err := database.DB.Where("hackathon_id IN ? AND team_id IS NULL AND (code_url <> '' OR demo_url <> '')", hackathonIDs).
+		Find(&participants).Error
+	if err != nil {
+		return err
+	}
+	if err := database.DB.Where("hackathon_id IN ? AND (code_url <> '' OR demo_url <> '')", hackathonIDs).Find(&teams).Error; err != nil {
+		return err
+	}
+
+	add := func(check models.LinkCheck, codeUrl string, demoUrl string) {
+		if codeUrl != "" {
+			check.Field, check.URL = "code_url", codeUrl
+			*checks = append(*checks, check)
+		}
+		if demoUrl != "" {
+			check.Field, check.URL = "demo_url", demoUrl
+			*checks = append(*checks, check)
+		}
+	}
+	for _, participant := range participants {
+		add(models.LinkCheck{HackathonID: uint(participant.HackathonId), UserID: uint(participant.UserId)}, participant.CodeUrl, participant.DemoUrl)
+	}
+	for _, team := range teams {
+		add(models.LinkCheck{HackathonID: team.HackathonID, TeamID: team.ID}, team.CodeUrl, team.DemoUrl)
+	}
+	return nil
+}
+
+// SaveLinkCheck records the outcome of probing a link, replacing the last one
+func SaveLinkCheck(check *models.LinkCheck) error {
+	return database.DB.Omit(clause.Associations).
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"url", "status", "status_code", "error", "checked_at"})}).
+		Create(check).Error
+}
+
+// ListLinkChecks loads the outcome of the last probe of every link submitted in a hackathon
+func ListLinkChecks(checks *[]models.LinkCheck, hackathonID uint) error {
+	return database.DB.Where("hackathon_id = ?", hackathonID).Find(checks).Error
+}
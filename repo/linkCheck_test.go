@@ -0,0 +1,46 @@
+package repo
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+func TestListLinksToCheck(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id` FROM `hackathons` WHERE status IN (?,?) AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs("running", "judging").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id IN (?) AND team_id IS NULL AND (code_url <> '' OR demo_url <> '')) AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "code_url", "demo_url"}).AddRow(1, 6, "https://github.com/six/app", ""))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `teams` WHERE hackathon_id IN (?) AND (code_url <> '' OR demo_url <> '')")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "code_url", "demo_url"}).AddRow(2, 1, "https://github.com/team/app", "https://team.example.com"))
+
+	var checks []models.LinkCheck
+	require.NoError(t, ListLinksToCheck(&checks))
+	require.Equal(t, []models.LinkCheck{
+		{HackathonID: 1, UserID: 6, Field: "code_url", URL: "https://github.com/six/app"},
+		{HackathonID: 1, TeamID: 2, Field: "code_url", URL: "https://github.com/team/app"},
+		{HackathonID: 1, TeamID: 2, Field: "demo_url", URL: "https://team.example.com"},
+	}, checks)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -13,6 +13,13 @@ func CountParticipants(hackathonID string) (int64, error) {
 	return count, err
 }
 
+// ParticipantUsers loads the users registered for a hackathon
+func ParticipantUsers(users *[]models.User, hackathonID uint) error {
+	participants := database.DB.Model(&models.Participant{}).Select("user_id").Where("hackathon_id = ?", hackathonID)
+	err := database.DB.Where("id IN (?)", participants).Find(users).Error
+	return err
+}
+
 func ParticipantFromUserID(hackathon_id string, user_id string) (models.Participant, error) {
 	var participant models.Participant
 	if err := database.DB.Where("hackathon_id = ? AND user_id = ?", hackathon_id, user_id).Find(&participant).Error; err != nil {
@@ -46,7 +46,7 @@ func TestIsParticipant(t *testing.T) {
 	}), &gorm.Config{})
 	participantMockRows := sqlmock.NewRows([]string{"hackathon_id", "user_id", "demo_url", "code_url", "score"}).AddRow(1, "1", "", "", "70")
 	t.Run("no errors", func(t *testing.T) {
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ? AND user_id = ? ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs("1", 1).WillReturnRows(participantMockRows)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs("1", 1).WillReturnRows(participantMockRows)
 		_, err = IsParticipant(1, "1")
 		err = mock.ExpectationsWereMet()
 		if err != nil {
@@ -54,7 +54,7 @@ func TestIsParticipant(t *testing.T) {
 		}
 	})
 	t.Run("errors", func(t *testing.T) {
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ? AND user_id = ? ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs("1", 2).WillReturnError(gorm.ErrRecordNotFound)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs("1", 2).WillReturnError(gorm.ErrRecordNotFound)
 		_, err = IsParticipant(2, "1")
 		err = mock.ExpectationsWereMet()
 	})
@@ -102,7 +102,7 @@ func TestGetSubmission(t *testing.T) {
 	}), &gorm.Config{})
 	var p models.Participant
 	participantMockRows := sqlmock.NewRows([]string{"hackathon_id", "user_id", "demo_url", "code_url", "score"}).AddRow(1, "1", "", "", "70")
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ? AND user_id = ? ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs(1, 1).WillReturnRows(participantMockRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `participants` WHERE hackathon_id = ? AND user_id = ? AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs(1, 1).WillReturnRows(participantMockRows)
 	err = GetSubmission(&p, 1, 1)
 	if err != nil {
 		log.Fatal(err)
@@ -1,8 +1,12 @@
 package repo
 
 import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"win-a-thon/assignment"
 	"win-a-thon/database"
 	"win-a-thon/models"
+	"win-a-thon/scoring"
 )
 
 func UserFromUserID(userID int) (models.User, error) {
@@ -17,7 +21,12 @@ func HackathonFromHackathonID(hackathon_id string) (models.Hackathon, error) {
 	return hackathon, err
 }
 
-func JudgeSubmisson(participantInput *models.Participant, hackathon_id string, username string) error {
+// JudgeSubmisson records the score a judge gives the entry of a participant, their
+// whole team when they are in one, along with the points behind it when the
+// hackathon has a rubric. The score is then combined with the scores of the other
+// judges into the score of the entry. Judges assigned a review queue get
+// ErrNotAssigned for entries outside it.
+func JudgeSubmisson(judged *models.JudgeScore, hackathon models.Hackathon, username string) error {
 	var user models.User
 	var participant models.Participant
 
@@ -25,21 +34,90 @@ func JudgeSubmisson(participantInput *models.Participant, hackathon_id string, u
 		return err
 	}
 
-	user_id := int(user.ID)
+	if err := database.DB.Where("hackathon_id = ? AND user_id = ?", hackathon.ID, user.ID).First(&participant).Error; err != nil {
+		return err
+	}
+	return JudgeEntry(judged, hackathon, EntryOf(participant))
+}
+
+// JudgeEntry records the score a judge gives an entry, like JudgeSubmisson does
+// for the entry of a participant
+func JudgeEntry(judged *models.JudgeScore, hackathon models.Hackathon, entry assignment.Entry) error {
+	judged.HackathonID = hackathon.ID
+	judged.UserID = entry.UserID
+	judged.TeamID = entry.TeamID
+
+	// once judges are assigned, they only score the entries in their queue
+	if hackathon.ReviewsPerSubmission > 0 && judged.JudgeID != uint(hackathon.OrganiserID) {
+		var count int64
+		err := database.DB.Model(&models.Assignment{}).Where("hackathon_id = ? AND judge_id = ? AND user_id = ? AND team_id = ?",
+			hackathon.ID, judged.JudgeID, entry.UserID, entry.TeamID).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrNotAssigned
+		}
+	}
 
-	if err := database.DB.Where("hackathon_id = ? AND user_id = ?", hackathon_id, user_id).First(&participant).Error; err != nil {
+	// judges can't score colleagues or anyone they declared a conflict with
+	if judged.JudgeID != uint(hackathon.OrganiserID) {
+		conflicted, err := entryConflicted(hackathon.ID, judged.JudgeID, entry)
+		if err != nil {
+			return err
+		}
+		if conflicted {
+			return ErrConflicted
+		}
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		// judges may change their mind until judging is over
+		err := tx.Omit(clause.Associations).
+			Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"score", "feedback", "notes", "updated_at"})}).
+			Create(judged).Error
+		if err != nil {
+			return err
+		}
+
+		if len(judged.Criteria) > 0 {
+			if err := replaceCriterionScores(tx, judged); err != nil {
+				return err
+			}
+		}
+		return scoreEntry(tx, hackathon, judged.UserID, judged.TeamID)
+	})
+}
+
+// replaceCriterionScores stores the points behind a judge's score in place of the
+// ones they gave before
+func replaceCriterionScores(tx *gorm.DB, judged *models.JudgeScore) error {
+	var stored models.JudgeScore
+	err := tx.Select("id").Where("hackathon_id = ? AND judge_id = ? AND user_id = ? AND team_id = ?",
+		judged.HackathonID, judged.JudgeID, judged.UserID, judged.TeamID).First(&stored).Error
+	if err != nil {
 		return err
 	}
+	judged.ID = stored.ID
 
-	participant.Score = participantInput.Score
-	if err := database.DB.Save(&participant).Error; err != nil {
+	if err := tx.Where("judge_score_id = ?", stored.ID).Delete(&models.CriterionScore{}).Error; err != nil {
 		return err
 	}
-	return nil
+	for i := range judged.Criteria {
+		judged.Criteria[i].ID = 0
+		judged.Criteria[i].JudgeScoreID = stored.ID
+	}
+	return tx.Omit(clause.Associations).Create(&judged.Criteria).Error
 }
 
-func GetLeaderboard(participants *[]models.Participant, hackathon_id string) (err error) {
-	err = database.DB.Order("score desc").Where("hackathon_id = ?", hackathon_id).Find(participants).Error
+// GetLeaderboard loads the participants of a hackathon by score. When trackID isn't
+// empty, only those competing in that track are loaded.
+func GetLeaderboard(participants *[]models.Participant, hackathon_id string, trackID string) (err error) {
+	tx := database.DB.Order("score desc").Where("hackathon_id = ?", hackathon_id)
+	if trackID != "" {
+		tx = tx.Where("track_id = ?", trackID)
+	}
+	err = tx.Find(participants).Error
 	return err
 }
 
@@ -48,7 +126,29 @@ func GetSubmissions(participants *[]models.Participant, hackathon_id string) (er
 	return err
 }
 
-func GetWinners(winners *[]models.Participant, hackathon_id string) (err error) {
-	err = database.DB.Where("hackathon_id = ?", hackathon_id).Order("score desc").Limit(3).Find(winners).Error
-	return err
+// GetWinners loads the entries placed in the top places of a hackathon, or of one
+// of its tracks when trackID isn't empty, in rank order. An entry is a participant
+// competing alone or the members of a team, as a team takes a single place.
+// Entries tied for the last place all make it.
+func GetWinners(winners *[]scoring.Standing, hackathon models.Hackathon, places int, trackID string) error {
+	var participants []models.Participant
+	tx := database.DB.Where("hackathon_id = ?", hackathon.ID)
+	if trackID != "" {
+		tx = tx.Where("track_id = ?", trackID)
+	}
+	if err := tx.Order("score desc").Find(&participants).Error; err != nil {
+		return err
+	}
+
+	standings, err := RankParticipants(hackathon, participants)
+	if err != nil {
+		return err
+	}
+	for _, standing := range standings {
+		if standing.Rank > places {
+			break
+		}
+		*winners = append(*winners, standing)
+	}
+	return nil
 }
@@ -3,6 +3,7 @@ package repo
 import (
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"log"
@@ -11,6 +12,7 @@ import (
 	"time"
 	"win-a-thon/database"
 	"win-a-thon/models"
+	"win-a-thon/scoring"
 )
 
 func TestGetLeaderboard(t *testing.T) {
@@ -33,9 +35,9 @@ func TestGetLeaderboard(t *testing.T) {
 
 	fmt.Println(hackathonMockRows, "test line")
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `participants` WHERE hackathon_id = ?  ORDER BY score desc")).WithArgs("1").WillReturnRows(hackathonMockRows)
+		"SELECT * FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL ORDER BY score desc")).WithArgs("1").WillReturnRows(hackathonMockRows)
 
-	err = GetLeaderboard(&participants, "1")
+	err = GetLeaderboard(&participants, "1", "")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -90,7 +92,7 @@ func TestGetWinners(t *testing.T) {
 		SkipInitializeWithVersion: true,
 	}), &gorm.Config{})
 
-	var winners []models.Participant
+	var winners []scoring.Standing
 
 	participantMockRows := sqlmock.NewRows([]string{"hackathon_id", "user_id", "demo_url", "code_url", "score"}).
 		AddRow(1, 1, "abc", "abc", 10).
@@ -99,9 +101,9 @@ func TestGetWinners(t *testing.T) {
 
 	fmt.Println(participantMockRows, "test line")
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `participants` WHERE hackathon_id = ?  ORDER BY score desc LIMIT 3")).WithArgs("1").WillReturnRows(participantMockRows)
+		"SELECT * FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL ORDER BY score desc")).WithArgs(1).WillReturnRows(participantMockRows)
 
-	err = GetWinners(&winners, "1")
+	err = GetWinners(&winners, models.Hackathon{Model: gorm.Model{ID: 1}}, 3, "")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -112,6 +114,83 @@ func TestGetWinners(t *testing.T) {
 	}
 }
 
+func TestGetWinnersCountsTeamsOnce(t *testing.T) {
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      driver,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	participantMockRows := sqlmock.NewRows([]string{"hackathon_id", "user_id", "score", "team_id"}).
+		AddRow(1, 1, 30, 5).
+		AddRow(1, 3, 20, nil).
+		AddRow(1, 2, 30, 5).
+		AddRow(1, 4, 10, nil).
+		AddRow(1, 6, 5, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL ORDER BY score desc")).
+		WithArgs(1).
+		WillReturnRows(participantMockRows)
+
+	var winners []scoring.Standing
+	err = GetWinners(&winners, models.Hackathon{Model: gorm.Model{ID: 1}}, 3, "")
+	require.NoError(t, err)
+
+	var userIDs [][]int
+	for _, entry := range winners {
+		var ids []int
+		for _, participant := range entry.Members {
+			ids = append(ids, participant.UserId)
+		}
+		userIDs = append(userIDs, ids)
+	}
+	require.Equal(t, [][]int{{1, 2}, {3}, {4}}, userIDs)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetWinnersSharesRanks(t *testing.T) {
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      driver,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	first := time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC)
+	participantMockRows := sqlmock.NewRows([]string{"hackathon_id", "user_id", "score", "submitted_at"}).
+		AddRow(1, 1, 30, first).
+		AddRow(1, 2, 20, first.Add(time.Hour)).
+		AddRow(1, 3, 20, nil).
+		AddRow(1, 4, 20, first.Add(time.Hour)).
+		AddRow(1, 5, 10, first)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL ORDER BY score desc")).
+		WithArgs(1).
+		WillReturnRows(participantMockRows)
+
+	// 3 never submitted, while 2 and 4 submitted at the same time and stay tied
+	var winners []scoring.Standing
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}, TieBreakers: "earliest_submission"}
+	err = GetWinners(&winners, hackathon, 3, "")
+	require.NoError(t, err)
+
+	var userIDs, ranks []int
+	for _, entry := range winners {
+		userIDs = append(userIDs, entry.Members[0].UserId)
+		ranks = append(ranks, entry.Rank)
+	}
+	require.Equal(t, []int{1, 2, 4}, userIDs)
+	require.Equal(t, []int{1, 2, 2}, ranks)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestHackathonFromHackathonID(t *testing.T) {
 	driver, mock, err := sqlmock.New()
 	if err != nil {
@@ -187,8 +266,8 @@ func TestJudgeSubmisson(t *testing.T) {
 	}), &gorm.Config{})
 
 	// First Query
-	userRows := sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "username", "full_name", "hashed_password", "email", "linked_in", "git_hub", "web_link", "organisation", "is_admin"}).
-		AddRow(time.Now(), time.Now(), nil, "name", "fullname", "password", "email", "abc", "abc", "abc", "abc", false)
+	userRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "username", "full_name", "hashed_password", "email", "linked_in", "git_hub", "web_link", "organisation", "is_admin"}).
+		AddRow(1, time.Now(), time.Now(), nil, "name", "fullname", "password", "email", "abc", "abc", "abc", "abc", false)
 
 	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).WithArgs("name").WillReturnRows(userRows)
 
@@ -196,16 +275,25 @@ func TestJudgeSubmisson(t *testing.T) {
 	participantMockRows := sqlmock.NewRows([]string{"hackathon_id", "user_id", "demo_url", "code_url", "score"}).
 		AddRow(1, 1, "abc", "abc", 10)
 	mock.ExpectQuery(regexp.QuoteMeta(
-		"SELECT * FROM `participants` WHERE hackathon_id = ? AND user_id = ? ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs().WillReturnRows(participantMockRows)
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs(1, 1).WillReturnRows(participantMockRows)
+	expectNoConflict(mock, 1, 5, 0, 1)
 
-	// Third Query
+	// the judge's own score, then the entry's score combined with the other judges'
 	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta(
-		"UPDATE `participants` SET `demo_url`=?,`code_url`=?,`score`=? WHERE `hackathon_id` = ? AND `user_id` = ?")).WithArgs().WillReturnResult(sqlmock.NewResult(1, 1))
+		"INSERT INTO `judge_scores` (`hackathon_id`,`judge_id`,`user_id`,`team_id`,`score`,`feedback`,`notes`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `score`=VALUES(`score`),`feedback`=VALUES(`feedback`),`notes`=VALUES(`notes`),`updated_at`=VALUES(`updated_at`)")).
+		WithArgs(1, 5, 1, 0, 8.0, "", "", AnyTime{}, AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `score` FROM `judge_scores` WHERE hackathon_id = ? AND user_id = ? AND team_id = ?")).WithArgs(1, 1, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"score"}).AddRow(8).AddRow(4).AddRow(9))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `participants` SET `judge_count`=?,`raw_score`=?,`score`=? WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(3, 8.0, 8.0, 1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
-	var participant models.Participant
-	err = JudgeSubmisson(&participant, "1", "name")
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}, ScoreAggregation: "median"}
+	judged := models.JudgeScore{JudgeID: 5, Score: 8}
+	err = JudgeSubmisson(&judged, hackathon, "name")
 
 	if err != nil {
 		log.Fatal(err)
@@ -216,3 +304,43 @@ func TestJudgeSubmisson(t *testing.T) {
 		t.Errorf("Failed to meet expectations, got error: %v", err)
 	}
 }
+
+func TestJudgeSubmissonTeam(t *testing.T) {
+	driver, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      driver,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	userRows := sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "name")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).WithArgs("name").WillReturnRows(userRows)
+
+	participantMockRows := sqlmock.NewRows([]string{"hackathon_id", "user_id", "score", "team_id"}).
+		AddRow(1, 1, 0, 4)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).WithArgs(1, 1).WillReturnRows(participantMockRows)
+	expectNoConflict(mock, 1, 5, 4, 1, 2, 3)
+
+	// the team is scored as a whole and every member gets its score
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `judge_scores` (`hackathon_id`,`judge_id`,`user_id`,`team_id`,`score`,`feedback`,`notes`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `score`=VALUES(`score`),`feedback`=VALUES(`feedback`),`notes`=VALUES(`notes`),`updated_at`=VALUES(`updated_at`)")).
+		WithArgs(1, 5, 0, 4, 42.0, "Clear demo, thin tests", "Close call with team 6", AnyTime{}, AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `score` FROM `judge_scores` WHERE hackathon_id = ? AND user_id = ? AND team_id = ?")).WithArgs(1, 0, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"score"}).AddRow(42).AddRow(30))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `participants` SET `judge_count`=?,`raw_score`=?,`score`=? WHERE team_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(2, 36.0, 36.0, 4).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}}
+	judged := models.JudgeScore{JudgeID: 5, Score: 42, Feedback: "Clear demo, thin tests", Notes: "Close call with team 6"}
+	err = JudgeSubmisson(&judged, hackathon, "name")
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
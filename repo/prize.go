@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+func CreatePrize(prize *models.Prize) error {
+	return database.DB.Create(prize).Error
+}
+
+// ListPrizes loads the prizes of a hackathon, ranked prizes by rank and special
+// awards last
+func ListPrizes(prizes *[]models.Prize, hackathonID uint) error {
+	return database.DB.Where("hackathon_id = ?", hackathonID).Order("rank_from = 0, rank_from, id").Find(prizes).Error
+}
+
+func PrizeFromID(prizeID string, hackathonID uint) (models.Prize, error) {
+	var prize models.Prize
+	err := database.DB.Where("id = ? AND hackathon_id = ?", prizeID, hackathonID).First(&prize).Error
+	return prize, err
+}
+
+func DeletePrize(prize *models.Prize) error {
+	return database.DB.Delete(prize).Error
+}
+
+// AwardPrize gives a special award to a participant
+func AwardPrize(prize *models.Prize, userID uint) error {
+	if err := database.DB.Model(prize).Update("awardee_id", userID).Error; err != nil {
+		return err
+	}
+	prize.AwardeeID = &userID
+	return nil
+}
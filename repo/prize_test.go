@@ -0,0 +1,64 @@
+package repo
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+func TestListPrizesSpecialAwardsLast(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `prizes` WHERE hackathon_id = ? ORDER BY rank_from = 0, rank_from, id")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "title", "rank_from", "rank_to"}).
+			AddRow(2, 1, "First prize", 1, 1).
+			AddRow(1, 1, "Best design", 0, 0))
+
+	var prizes []models.Prize
+	err := ListPrizes(&prizes, 1)
+	require.NoError(t, err)
+	require.Len(t, prizes, 2)
+	require.False(t, prizes[0].IsSpecial())
+	require.True(t, prizes[1].IsSpecial())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAwardPrize(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `prizes` SET `awardee_id`=? WHERE `id` = ?")).
+		WithArgs(7, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	prize := models.Prize{ID: 1, HackathonID: 1, Title: "Best design"}
+	err := AwardPrize(&prize, 7)
+	require.NoError(t, err)
+	require.EqualValues(t, 7, *prize.AwardeeID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"win-a-thon/assignment"
+	"win-a-thon/database"
+	"win-a-thon/models"
+	"win-a-thon/scoring"
+)
+
+// RankParticipants ranks the entries of the participants of a hackathon by score,
+// breaking ties with the tie-breakers of the hackathon. Members of a team are
+// ranked together as one entry.
+func RankParticipants(hackathon models.Hackathon, participants []models.Participant) ([]scoring.Standing, error) {
+	var standings []scoring.Standing
+	index := make(map[assignment.Entry]int)
+	inTeams := false
+	for _, participant := range participants {
+		entry := EntryOf(participant)
+		if i, ok := index[entry]; ok {
+			standings[i].Members = append(standings[i].Members, participant)
+			continue
+		}
+		index[entry] = len(standings)
+		inTeams = inTeams || entry.TeamID != 0
+		standings = append(standings, scoring.Standing{
+			Members:     []models.Participant{participant},
+			Score:       participant.Score,
+			SubmittedAt: participant.SubmittedAt,
+		})
+	}
+
+	// tie-breakers are checked when they are set
+	tieBreakers, _ := scoring.ParseTieBreakers(hackathon.TieBreakers)
+	bySubmission, byCriteria := false, false
+	for _, tieBreaker := range tieBreakers {
+		_, ok := scoring.TieBreakerCriterion(tieBreaker)
+		byCriteria = byCriteria || ok
+		bySubmission = bySubmission || tieBreaker == scoring.EarliestSubmission
+	}
+
+	// a team submits together, so its submission is what counts
+	if bySubmission && inTeams {
+		var teams []models.Team
+		if err := database.DB.Where("hackathon_id = ?", hackathon.ID).Find(&teams).Error; err != nil {
+			return nil, err
+		}
+		for _, team := range teams {
+			if i, ok := index[assignment.Entry{TeamID: team.ID}]; ok {
+				standings[i].SubmittedAt = team.SubmittedAt
+			}
+		}
+	}
+
+	if byCriteria {
+		var criteria []models.Criterion
+		var scores []models.JudgeScore
+		if err := ListCriteria(&criteria, hackathon.ID); err != nil {
+			return nil, err
+		}
+		if err := HackathonScores(&scores, hackathon.ID); err != nil {
+			return nil, err
+		}
+
+		names := make(map[uint]string)
+		for _, criterion := range criteria {
+			names[criterion.ID] = criterion.Name
+		}
+		counts := make(map[int]map[string]int)
+		for _, score := range scores {
+			i, ok := index[assignment.Entry{UserID: score.UserID, TeamID: score.TeamID}]
+			if !ok {
+				continue
+			}
+			if standings[i].Criteria == nil {
+				standings[i].Criteria = make(map[string]float64)
+				counts[i] = make(map[string]int)
+			}
+			for _, points := range score.Criteria {
+				name := names[points.CriterionID]
+				standings[i].Criteria[name] += points.Points
+				counts[i][name]++
+			}
+		}
+		for i, entryCounts := range counts {
+			for name, count := range entryCounts {
+				standings[i].Criteria[name] /= float64(count)
+			}
+		}
+	}
+
+	scoring.RankStandings(standings, tieBreakers)
+	return standings, nil
+}
@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+func CreateCriterion(criterion *models.Criterion) error {
+	return database.DB.Create(criterion).Error
+}
+
+// ListCriteria loads the rubric of a hackathon in the order it was written
+func ListCriteria(criteria *[]models.Criterion, hackathonID uint) error {
+	return database.DB.Where("hackathon_id = ?", hackathonID).Order("id").Find(criteria).Error
+}
+
+func CriterionFromID(criterionID string, hackathonID uint) (models.Criterion, error) {
+	var criterion models.Criterion
+	err := database.DB.Where("id = ? AND hackathon_id = ?", criterionID, hackathonID).First(&criterion).Error
+	return criterion, err
+}
+
+func DeleteCriterion(criterion *models.Criterion) error {
+	return database.DB.Delete(criterion).Error
+}
+
+// HackathonScores loads every score the judges gave in a hackathon, with the
+// points behind them
+func HackathonScores(scores *[]models.JudgeScore, hackathonID uint) error {
+	return database.DB.Preload("Criteria").Where("hackathon_id = ?", hackathonID).Order("id").Find(scores).Error
+}
+
+// EntryScores loads the scores the judges gave one entry, with the points behind
+// them. teamID is 0 for participants competing alone and userID is 0 for teams.
+func EntryScores(scores *[]models.JudgeScore, hackathonID uint, userID uint, teamID uint) error {
+	return database.DB.Preload("Criteria").Where("hackathon_id = ? AND user_id = ? AND team_id = ?", hackathonID, userID, teamID).
+		Order("id").Find(scores).Error
+}
@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+// SubmitTeam saves the submission of a team, moves it to a track unless trackID is
+// nil and records the revision actorID submitted, all at once
+func SubmitTeam(team *models.Team, participant *models.Participant, demoUrl string, codeUrl string, trackID *uint, actorID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := updateTeamSubmission(tx, team, demoUrl, codeUrl); err != nil {
+			return err
+		}
+		if trackID != nil {
+			if err := setParticipantTrack(tx, participant, *trackID); err != nil {
+				return err
+			}
+		}
+		revision := models.SubmissionRevision{HackathonID: team.HackathonID, TeamID: team.ID, ActorID: actorID, DemoUrl: team.DemoUrl, CodeUrl: team.CodeUrl}
+		return tx.Create(&revision).Error
+	})
+}
+
+// SubmitParticipant saves the submission of a participant competing alone, moves
+// them to a track unless trackID is nil and records the revision, all at once
+func SubmitParticipant(participant *models.Participant, trackID *uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(participant).Error; err != nil {
+			return err
+		}
+		if trackID != nil {
+			if err := setParticipantTrack(tx, participant, *trackID); err != nil {
+				return err
+			}
+		}
+		revision := models.SubmissionRevision{HackathonID: uint(participant.HackathonId), UserID: uint(participant.UserId), ActorID: uint(participant.UserId), DemoUrl: participant.DemoUrl, CodeUrl: participant.CodeUrl}
+		return tx.Create(&revision).Error
+	})
+}
+
+// ListRevisions loads every revision submitted in a hackathon, oldest first
+func ListRevisions(revisions *[]models.SubmissionRevision, hackathonID uint) error {
+	return database.DB.Where("hackathon_id = ?", hackathonID).Order("id").Find(revisions).Error
+}
+
+// ListSnapshots loads the final submissions of a hackathon
+func ListSnapshots(snapshots *[]models.SubmissionSnapshot, hackathonID uint) error {
+	return database.DB.Where("hackathon_id = ?", hackathonID).Find(snapshots).Error
+}
+
+// FreezeSubmissions takes the final snapshot of the submissions of a hackathon that
+// has ended, as they stand at now. The snapshot is taken once, later calls leave it
+// as it is.
+func FreezeSubmissions(hackathon *models.Hackathon, now time.Time) error {
+	if hackathon.SubmissionsFrozenAt != nil {
+		return nil
+	}
+
+	frozenAt := now
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var locked models.Hackathon
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "submissions_frozen_at").
+			Where("id = ?", hackathon.ID).First(&locked).Error
+		if err != nil {
+			return err
+		}
+		if locked.SubmissionsFrozenAt != nil {
+			frozenAt = *locked.SubmissionsFrozenAt
+			return nil
+		}
+
+		var participants []models.Participant
+		var teams []models.Team
+		if err := tx.Where("hackathon_id = ?", hackathon.ID).Find(&participants).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("hackathon_id = ?", hackathon.ID).Find(&teams).Error; err != nil {
+			return err
+		}
+
+		// entries that never submitted have no final submission
+		var snapshots []models.SubmissionSnapshot
+		for _, participant := range participants {
+			if participant.TeamID == nil && (participant.CodeUrl != "" || participant.DemoUrl != "") {
+				snapshots = append(snapshots, models.SubmissionSnapshot{HackathonID: hackathon.ID, UserID: uint(participant.UserId),
+					DemoUrl: participant.DemoUrl, CodeUrl: participant.CodeUrl, SubmittedAt: participant.SubmittedAt, FrozenAt: now})
+			}
+		}
+		for _, team := range teams {
+			if team.CodeUrl != "" || team.DemoUrl != "" {
+				snapshots = append(snapshots, models.SubmissionSnapshot{HackathonID: hackathon.ID, TeamID: team.ID,
+					DemoUrl: team.DemoUrl, CodeUrl: team.CodeUrl, SubmittedAt: team.SubmittedAt, FrozenAt: now})
+			}
+		}
+		if len(snapshots) > 0 {
+			if err := tx.Create(&snapshots).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Hackathon{}).Where("id = ?", hackathon.ID).Update("submissions_frozen_at", now).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	hackathon.SubmissionsFrozenAt = &frozenAt
+	return nil
+}
@@ -0,0 +1,96 @@
+package repo
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+func TestFreezeSubmissions(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id`,`submissions_frozen_at` FROM `hackathons` WHERE id = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1 FOR UPDATE")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "submissions_frozen_at"}).AddRow(1, nil))
+	// user 5 never submitted, user 6 submitted alone and user 7 with team 2
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "code_url", "team_id"}).
+			AddRow(1, 5, "", nil).
+			AddRow(1, 6, "https://code/6", nil).
+			AddRow(1, 7, "", 2))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `teams` WHERE hackathon_id = ?")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "code_url", "demo_url"}).AddRow(2, 1, "https://code/team", "https://demo/team"))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `submission_snapshots` (`hackathon_id`,`user_id`,`team_id`,`demo_url`,`code_url`,`submitted_at`,`frozen_at`) VALUES (?,?,?,?,?,?,?),(?,?,?,?,?,?,?)")).
+		WithArgs(1, 6, 0, "", "https://code/6", nil, now, 1, 0, 2, "https://demo/team", "https://code/team", nil, now).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `hackathons` SET `submissions_frozen_at`=?,`updated_at`=? WHERE id = ? AND `hackathons`.`deleted_at` IS NULL")).
+		WithArgs(now, AnyTime{}, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	hackathon := models.Hackathon{Model: gorm.Model{ID: 1}}
+	require.NoError(t, FreezeSubmissions(&hackathon, now))
+	require.Equal(t, now, *hackathon.SubmissionsFrozenAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// the snapshot is only taken once
+	require.NoError(t, FreezeSubmissions(&hackathon, now.Add(time.Hour)))
+	require.Equal(t, now, *hackathon.SubmissionsFrozenAt)
+}
+
+func TestSubmitTeamIsUndoneWhenTheRevisionFails(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `teams` SET `demo_url`=?,`code_url`=?,`updated_at`=?,`submitted_at`=? WHERE `id` = ?")).
+		WithArgs("https://demo", "https://code", AnyTime{}, AnyTime{}, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `participants` SET `track_id`=? WHERE team_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(6, 4).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `submission_revisions` (`hackathon_id`,`user_id`,`team_id`,`actor_id`,`demo_url`,`code_url`,`created_at`) VALUES (?,?,?,?,?,?,?)")).
+		WithArgs(1, 0, 4, 7, "https://demo", "https://code", AnyTime{}).
+		WillReturnError(errors.New("connection lost"))
+	mock.ExpectRollback()
+
+	teamID, trackID := uint(4), uint(6)
+	team := models.Team{ID: 4, HackathonID: 1}
+	participant := models.Participant{HackathonId: 1, UserId: 7, TeamID: &teamID}
+	require.Error(t, SubmitTeam(&team, &participant, "https://demo", "https://code", &trackID, 7))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
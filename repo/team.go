@@ -0,0 +1,170 @@
+package repo
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+var (
+	ErrAlreadyInTeam = errors.New("participant already belongs to a team in this hackathon")
+	ErrTeamFull      = errors.New("team is full")
+)
+
+// CreateTeam saves a new team and makes its captain the first member
+func CreateTeam(team *models.Team) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(team).Error; err != nil {
+			return err
+		}
+		return addTeamMember(tx, *team, team.CaptainID)
+	})
+}
+
+// addTeamMember puts a participant of the hackathon in the team, unless they are
+// already in one, and drops the requests they made to join other teams. Members
+// compete in the track of their captain.
+func addTeamMember(tx *gorm.DB, team models.Team, userID uint) error {
+	var captain models.Participant
+	if err := tx.Where("hackathon_id = ? AND user_id = ?", team.HackathonID, team.CaptainID).First(&captain).Error; err != nil {
+		return err
+	}
+
+	result := tx.Model(&models.Participant{}).
+		Where("hackathon_id = ? AND user_id = ? AND team_id IS NULL", team.HackathonID, userID).
+		Updates(map[string]interface{}{"team_id": team.ID, "track_id": captain.TrackID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAlreadyInTeam
+	}
+	teams := tx.Model(&models.Team{}).Select("id").Where("hackathon_id = ?", team.HackathonID)
+	return tx.Where("user_id = ? AND team_id IN (?)", userID, teams).Delete(&models.TeamJoinRequest{}).Error
+}
+
+// JoinTeam adds the user to a team that has fewer than maxSize members. A maxSize
+// of 0 means the team can grow without limit.
+func JoinTeam(team models.Team, userID uint, maxSize int) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var locked models.Team
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", team.ID).First(&locked).Error; err != nil {
+			return err
+		}
+		if maxSize > 0 {
+			var count int64
+			if err := tx.Model(&models.Participant{}).Where("team_id = ?", team.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count >= int64(maxSize) {
+				return ErrTeamFull
+			}
+		}
+		return addTeamMember(tx, locked, userID)
+	})
+}
+
+// leaveTeam takes the participant out of their team, if they are in one. A leaving
+// captain hands the team over to another member, and the last member to leave
+// breaks the team up.
+func leaveTeam(tx *gorm.DB, hackathonID uint, userID uint) error {
+	var participant models.Participant
+	result := tx.Where("hackathon_id = ? AND user_id = ?", hackathonID, userID).Limit(1).Find(&participant)
+	if result.Error != nil || participant.TeamID == nil {
+		return result.Error
+	}
+	teamID := *participant.TeamID
+
+	if err := tx.Model(&models.Participant{}).
+		Where("hackathon_id = ? AND user_id = ?", hackathonID, userID).
+		Update("team_id", nil).Error; err != nil {
+		return err
+	}
+
+	var team models.Team
+	if err := tx.Where("id = ?", teamID).First(&team).Error; err != nil {
+		return err
+	}
+	if team.CaptainID != userID {
+		return nil
+	}
+
+	var members []models.Participant
+	if err := tx.Where("team_id = ?", teamID).Order("user_id").Limit(1).Find(&members).Error; err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return tx.Delete(&team).Error
+	}
+	return tx.Model(&team).Update("captain_id", members[0].UserId).Error
+}
+
+// LeaveTeam takes the participant out of their team in the hackathon
+func LeaveTeam(hackathonID uint, userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		return leaveTeam(tx, hackathonID, userID)
+	})
+}
+
+func TeamFromID(teamID string, hackathonID uint) (models.Team, error) {
+	var team models.Team
+	err := database.DB.Where("id = ? AND hackathon_id = ?", teamID, hackathonID).First(&team).Error
+	return team, err
+}
+
+func TeamFromInviteCode(inviteCode string, hackathonID uint) (models.Team, error) {
+	var team models.Team
+	err := database.DB.Where("invite_code = ? AND hackathon_id = ?", inviteCode, hackathonID).First(&team).Error
+	return team, err
+}
+
+func ListTeams(teams *[]models.Team, hackathonID uint) error {
+	return database.DB.Where("hackathon_id = ?", hackathonID).Order("name").Find(teams).Error
+}
+
+// TeamMembers loads the users in a team
+func TeamMembers(users *[]models.User, teamID uint) error {
+	members := database.DB.Model(&models.Participant{}).Select("user_id").Where("team_id = ?", teamID)
+	return database.DB.Where("id IN (?)", members).Find(users).Error
+}
+
+func CountTeamMembers(teamID uint) (int64, error) {
+	var count int64
+	err := database.DB.Model(&models.Participant{}).Where("team_id = ?", teamID).Count(&count).Error
+	return count, err
+}
+
+func CreateTeamJoinRequest(request *models.TeamJoinRequest) error {
+	return database.DB.Create(request).Error
+}
+
+func TeamJoinRequestFromID(requestID string, teamID uint) (models.TeamJoinRequest, error) {
+	var request models.TeamJoinRequest
+	err := database.DB.Where("id = ? AND team_id = ?", requestID, teamID).First(&request).Error
+	return request, err
+}
+
+func ListTeamJoinRequests(requests *[]models.TeamJoinRequest, teamID uint) error {
+	return database.DB.Where("team_id = ?", teamID).Order("id").Find(requests).Error
+}
+
+func DeleteTeamJoinRequest(request *models.TeamJoinRequest) error {
+	return database.DB.Delete(request).Error
+}
+
+// updateTeamSubmission saves the one submission the team makes for its hackathon
+func updateTeamSubmission(tx *gorm.DB, team *models.Team, demoUrl string, codeUrl string) error {
+	now := time.Now()
+	team.DemoUrl = demoUrl
+	team.CodeUrl = codeUrl
+	team.SubmittedAt = &now
+	return tx.Model(team).Select("demo_url", "code_url", "submitted_at").Updates(team).Error
+}
+
+// TeamParticipants loads the registrations of the members of a team
+func TeamParticipants(participants *[]models.Participant, teamID uint) error {
+	return database.DB.Where("team_id = ?", teamID).Find(participants).Error
+}
@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+func TestJoinTeamFull(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `teams` WHERE id = ? ORDER BY `teams`.`id` LIMIT 1 FOR UPDATE")).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "name"}).AddRow(4, 1, "Rockets"))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `participants` WHERE team_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(3))
+	mock.ExpectRollback()
+
+	err := JoinTeam(models.Team{ID: 4, HackathonID: 1}, 7, 3)
+	require.Equal(t, ErrTeamFull, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJoinTeam(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `teams` WHERE id = ? ORDER BY `teams`.`id` LIMIT 1 FOR UPDATE")).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "name", "captain_id"}).AddRow(4, 1, "Rockets", 2))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL ORDER BY `participants`.`hackathon_id` LIMIT 1")).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "team_id", "track_id"}).AddRow(1, 2, 4, 6))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `participants` SET `team_id`=?,`track_id`=? WHERE (hackathon_id = ? AND user_id = ? AND team_id IS NULL) AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(4, 6, 1, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `team_join_requests` WHERE user_id = ? AND team_id IN (SELECT `id` FROM `teams` WHERE hackathon_id = ?)")).
+		WithArgs(7, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := JoinTeam(models.Team{ID: 4, HackathonID: 1}, 7, 0)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLeaveTeamHandsOverCaptaincy(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL LIMIT 1")).
+		WithArgs(1, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "team_id"}).AddRow(1, 7, 4))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `participants` SET `team_id`=? WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(nil, 1, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `teams` WHERE id = ? ORDER BY `teams`.`id` LIMIT 1")).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "name", "captain_id"}).AddRow(4, 1, "Rockets", 7))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE team_id = ? AND `participants`.`deleted_at` IS NULL ORDER BY user_id LIMIT 1")).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "team_id"}).AddRow(1, 9, 4))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `teams` SET `captain_id`=?,`updated_at`=? WHERE `id` = ?")).
+		WithArgs(9, AnyTime{}, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := LeaveTeam(1, 7)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,51 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+func CreateTrack(track *models.Track) error {
+	return database.DB.Create(track).Error
+}
+
+func ListTracks(tracks *[]models.Track, hackathonID uint) error {
+	return database.DB.Where("hackathon_id = ?", hackathonID).Order("name").Find(tracks).Error
+}
+
+func TrackFromID(trackID string, hackathonID uint) (models.Track, error) {
+	var track models.Track
+	err := database.DB.Where("id = ? AND hackathon_id = ?", trackID, hackathonID).First(&track).Error
+	return track, err
+}
+
+// DeleteTrack removes a track along with its prizes, leaving the participants who
+// picked it without one
+func DeleteTrack(track *models.Track) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Participant{}).Where("track_id = ?", track.ID).Update("track_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("track_id = ?", track.ID).Delete(&models.Prize{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(track).Error
+	})
+}
+
+// setParticipantTrack moves a participant to a track. Teams compete in a single
+// track, so the whole team of a participant in one is moved.
+func setParticipantTrack(tx *gorm.DB, participant *models.Participant, trackID uint) error {
+	tx = tx.Model(&models.Participant{})
+	if participant.TeamID != nil {
+		tx = tx.Where("team_id = ?", *participant.TeamID)
+	} else {
+		tx = tx.Where("hackathon_id = ? AND user_id = ?", participant.HackathonId, participant.UserId)
+	}
+	if err := tx.Update("track_id", trackID).Error; err != nil {
+		return err
+	}
+	participant.TrackID = &trackID
+	return nil
+}
@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+func TestSetParticipantTrackMovesTeam(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `participants` SET `track_id`=? WHERE team_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(6, 4).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	teamID := uint(4)
+	participant := models.Participant{HackathonId: 1, UserId: 7, TeamID: &teamID}
+	err := setParticipantTrack(database.DB, &participant, 6)
+	require.NoError(t, err)
+	require.EqualValues(t, 6, *participant.TrackID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetLeaderboardOfTrack(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE hackathon_id = ? AND track_id = ? AND `participants`.`deleted_at` IS NULL ORDER BY score desc")).
+		WithArgs("1", "6").
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "score", "track_id"}).AddRow(1, 7, 30, 6))
+
+	var participants []models.Participant
+	err := GetLeaderboard(&participants, "1", "6")
+	require.NoError(t, err)
+	require.Len(t, participants, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteTrackDeletesItsPrizes(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `participants` SET `track_id`=? WHERE track_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(nil, 6).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `prizes` WHERE track_id = ?")).
+		WithArgs(6).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `tracks` WHERE `tracks`.`id` = ?")).
+		WithArgs(6).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := DeleteTrack(&models.Track{ID: 6, HackathonID: 1})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,6 +1,8 @@
 package repo
 
 import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"win-a-thon/database"
 	"win-a-thon/models"
 	"win-a-thon/utils"
@@ -24,10 +26,47 @@ func GetProfileByUsername(username string) (models.User, error) {
 	return user, result.Error
 }
 
-func DeleteUserByUsername(username string) error {
-	var user models.User
-	result := database.DB.Where("username = ?", username).Delete(&user)
-	return result.Error
+// DeleteUserByUsername soft-deletes a user along with their registrations and takes
+// them off every waitlist. Each seat they free goes to the first user on the
+// waitlist of its hackathon, and those promoted are returned so they can be told.
+func DeleteUserByUsername(username string) ([]Promotion, error) {
+	var promotions []Promotion
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Where("username = ?", username).First(&user).Error; err != nil {
+			return err
+		}
+
+		// the hackathons they hold a seat in are locked until their seats are handed out
+		var hackathons []models.Hackathon
+		seats := tx.Model(&models.Participant{}).Select("hackathon_id").Where("user_id = ?", user.ID)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "title", "max_participants").
+			Where("id IN (?)", seats).Order("id").Find(&hackathons).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.Participant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.WaitlistEntry{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+
+		for _, hackathon := range hackathons {
+			promoted, err := promoteWaitlisted(tx, hackathon.ID, hackathon.MaxParticipants)
+			if err != nil {
+				return err
+			}
+			if promoted != nil {
+				promotions = append(promotions, Promotion{Hackathon: hackathon, User: *promoted})
+			}
+		}
+		return nil
+	})
+	return promotions, err
 }
 
 func UpdateUserProfile(username string, req utils.UpdateProfileRequest) (models.User, error) {
@@ -166,16 +166,40 @@ func TestDeleteUserByUsername(t *testing.T) {
 		IsAdmin:        false,
 	}
 
-	userRows := sqlmock.NewRows([]string{"created_at", "updated_at", "deleted_at", "username", "full_name", "hashed_password", "email", "linked_in", "git_hub", "web_link", "organisation", "is_admin"}).
-		AddRow(time.Now(), time.Now(), nil, user.Username, user.FullName, user.HashedPassword, user.Email, user.LinkedIn, user.GitHub, user.WebLink, user.Organisation, user.IsAdmin)
+	userRows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "deleted_at", "username", "full_name", "hashed_password", "email", "linked_in", "git_hub", "web_link", "organisation", "is_admin"}).
+		AddRow(1, time.Now(), time.Now(), nil, user.Username, user.FullName, user.HashedPassword, user.Email, user.LinkedIn, user.GitHub, user.WebLink, user.Organisation, user.IsAdmin)
 
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `deleted_at`=? WHERE username = ? AND `users`.`deleted_at` IS NULL")).WithArgs(AnyTime{}, "abcdef").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).WithArgs("abcdef").WillReturnRows(userRows)
+	// the user holds a seat in hackathon 4, which is full, and in hackathon 6, which has no limit
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`,`title`,`max_participants` FROM `hackathons` WHERE id IN (SELECT `hackathon_id` FROM `participants` WHERE user_id = ? AND `participants`.`deleted_at` IS NULL) AND `hackathons`.`deleted_at` IS NULL ORDER BY id FOR UPDATE")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "max_participants"}).AddRow(4, "Full Hackathon", 10).AddRow(6, "Open Hackathon", 0))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `participants` SET `deleted_at`=? WHERE user_id = ? AND `participants`.`deleted_at` IS NULL")).WithArgs(AnyTime{}, 1).WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `waitlist_entries` WHERE user_id = ?")).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `deleted_at`=? WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL")).WithArgs(AnyTime{}, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	// the seat freed in hackathon 4 goes to the first user on its waitlist
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(9))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `waitlist_entries`.`id`,`waitlist_entries`.`hackathon_id`,`waitlist_entries`.`user_id`,`waitlist_entries`.`created_at` FROM `waitlist_entries` JOIN users ON users.id = waitlist_entries.user_id AND users.deleted_at IS NULL WHERE waitlist_entries.hackathon_id = ? ORDER BY waitlist_entries.id LIMIT 1")).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "user_id"}).AddRow(7, 4, 5))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `participants` (`hackathon_id`,`user_id`,`demo_url`,`code_url`,`score`,`raw_score`,`judge_count`,`team_id`,`track_id`,`submitted_at`,`deleted_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs(4, 5, "", "", 0.0, 0.0, 0, nil, nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `waitlist_entries` WHERE `waitlist_entries`.`id` = ?")).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE id = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email"}).AddRow(5, "next", "next@example.com"))
 	mock.ExpectCommit()
 
-	fmt.Println(userRows)
-	err := DeleteUserByUsername("abcdef")
+	promotions, err := DeleteUserByUsername("abcdef")
 	require.NoError(t, err)
+	require.Len(t, promotions, 1)
+	require.Equal(t, "Full Hackathon", promotions[0].Hackathon.Title)
+	require.Equal(t, "next@example.com", promotions[0].User.Email)
+	require.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestShowUserHackathons(t *testing.T) {
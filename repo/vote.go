@@ -0,0 +1,51 @@
+package repo
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+var (
+	// ErrAlreadyVoted is returned when a voter casts a second ballot in a hackathon
+	ErrAlreadyVoted = errors.New("already voted in this hackathon")
+	// ErrTooManyBallots is returned when an IP address has cast as many ballots as allowed
+	ErrTooManyBallots = errors.New("too many ballots from this IP address")
+)
+
+// CastBallot records the votes of a voter in a hackathon, all cast at once from the
+// same IP address. Each voter casts a single ballot and at most maxPerIP voters
+// cast one from any IP address.
+func CastBallot(votes []models.Vote, maxPerIP int) error {
+	ballot := votes[0]
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockHackathon(tx, ballot.HackathonID); err != nil {
+			return err
+		}
+
+		var cast int64
+		if err := tx.Model(&models.Vote{}).Where("hackathon_id = ? AND voter_id = ?", ballot.HackathonID, ballot.VoterID).Count(&cast).Error; err != nil {
+			return err
+		}
+		if cast > 0 {
+			return ErrAlreadyVoted
+		}
+
+		var voters int64
+		err := tx.Model(&models.Vote{}).Where("hackathon_id = ? AND ip = ?", ballot.HackathonID, ballot.IP).
+			Distinct("voter_id").Count(&voters).Error
+		if err != nil {
+			return err
+		}
+		if voters >= int64(maxPerIP) {
+			return ErrTooManyBallots
+		}
+		return tx.Create(&votes).Error
+	})
+}
+
+// ListVotes loads every vote cast in a hackathon
+func ListVotes(votes *[]models.Vote, hackathonID uint) error {
+	return database.DB.Where("hackathon_id = ?", hackathonID).Find(votes).Error
+}
@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+// expectBallotChecks expects the hackathon to be locked and the ballots cast by the
+// voter and from their IP address to be counted
+func expectBallotChecks(mock sqlmock.Sqlmock, cast int, fromIP int) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id` FROM `hackathons` WHERE id = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1 FOR UPDATE")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `votes` WHERE hackathon_id = ? AND voter_id = ?")).
+		WithArgs(1, 9).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(cast))
+	if cast > 0 {
+		return
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT COUNT(DISTINCT(`voter_id`)) FROM `votes` WHERE hackathon_id = ? AND ip = ?")).
+		WithArgs(1, "10.0.0.1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(fromIP))
+}
+
+func ballot() []models.Vote {
+	return []models.Vote{
+		{HackathonID: 1, VoterID: 9, UserID: 4, Position: 1, IP: "10.0.0.1"},
+		{HackathonID: 1, VoterID: 9, TeamID: 2, Position: 2, IP: "10.0.0.1"},
+	}
+}
+
+func TestCastBallot(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	expectBallotChecks(mock, 0, 4)
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `votes` (`hackathon_id`,`voter_id`,`user_id`,`team_id`,`position`,`ip`,`created_at`) VALUES (?,?,?,?,?,?,?),(?,?,?,?,?,?,?)")).
+		WithArgs(1, 9, 4, 0, 1, "10.0.0.1", AnyTime{}, 1, 9, 0, 2, 2, "10.0.0.1", AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+
+	require.NoError(t, CastBallot(ballot(), 5))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCastBallotTwice(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	expectBallotChecks(mock, 2, 0)
+	mock.ExpectRollback()
+
+	require.Equal(t, ErrAlreadyVoted, CastBallot(ballot(), 5))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCastBallotTooManyFromIP(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	expectBallotChecks(mock, 0, 5)
+	mock.ExpectRollback()
+
+	require.Equal(t, ErrTooManyBallots, CastBallot(ballot(), 5))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
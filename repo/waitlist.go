@@ -0,0 +1,133 @@
+package repo
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+var ErrAlreadyParticipant = errors.New("user already participates in this hackathon")
+
+// Promotion is a user moved off the waitlist of a hackathon into a seat that was freed
+type Promotion struct {
+	Hackathon models.Hackathon
+	User      models.User
+}
+
+// lockHackathon holds the hackathon row until the transaction ends, so that seats
+// are counted and handed out by one registration or withdrawal at a time
+func lockHackathon(tx *gorm.DB, hackathonID uint) error {
+	var hackathon models.Hackathon
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", hackathonID).First(&hackathon).Error
+}
+
+// JoinHackathon registers the participant for a hackathon with maxParticipants
+// seats. When every seat is taken the user is put on the waitlist instead and their
+// position on it is returned; the position is 0 when they got a seat.
+func JoinHackathon(participant *models.Participant, maxParticipants int) (int64, error) {
+	var position int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockHackathon(tx, uint(participant.HackathonId)); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.Participant{}).Where("hackathon_id = ?", participant.HackathonId).Count(&count).Error; err != nil {
+			return err
+		}
+		if count < int64(maxParticipants) {
+			return tx.Create(participant).Error
+		}
+
+		var existing []models.Participant
+		result := tx.Where("hackathon_id = ? AND user_id = ?", participant.HackathonId, participant.UserId).Limit(1).Find(&existing)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return ErrAlreadyParticipant
+		}
+
+		entry := models.WaitlistEntry{HackathonID: uint(participant.HackathonId), UserID: uint(participant.UserId)}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.WaitlistEntry{}).Where("hackathon_id = ? AND id <= ?", entry.HackathonID, entry.ID).Count(&position).Error
+	})
+	return position, err
+}
+
+// WithdrawParticipant takes the user off a hackathon with maxParticipants seats,
+// or off its waitlist. The seat they free goes to the first user on the waitlist,
+// who is returned so they can be told; nil is returned when nobody was promoted.
+// It fails with gorm.ErrRecordNotFound when the user was neither registered nor
+// waiting.
+func WithdrawParticipant(hackathonID uint, userID uint, maxParticipants int) (*models.User, error) {
+	var promoted *models.User
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockHackathon(tx, hackathonID); err != nil {
+			return err
+		}
+
+		if err := leaveTeam(tx, hackathonID, userID); err != nil {
+			return err
+		}
+
+		// withdrawn participants are removed for good so they can register again
+		result := tx.Unscoped().Where("hackathon_id = ? AND user_id = ?", hackathonID, userID).Delete(&models.Participant{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			result = tx.Where("hackathon_id = ? AND user_id = ?", hackathonID, userID).Delete(&models.WaitlistEntry{})
+			if result.Error == nil && result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+			return result.Error
+		}
+
+		var err error
+		promoted, err = promoteWaitlisted(tx, hackathonID, maxParticipants)
+		return err
+	})
+	return promoted, err
+}
+
+// promoteWaitlisted hands a free seat of a hackathon with maxParticipants seats,
+// locked by the transaction, to the first user on its waitlist. The user is
+// returned so they can be told; nil is returned when nobody was promoted.
+func promoteWaitlisted(tx *gorm.DB, hackathonID uint, maxParticipants int) (*models.User, error) {
+	if maxParticipants == 0 {
+		return nil, nil
+	}
+
+	var count int64
+	if err := tx.Model(&models.Participant{}).Where("hackathon_id = ?", hackathonID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= int64(maxParticipants) {
+		return nil, nil
+	}
+
+	// users deleted while waiting are passed over
+	var next []models.WaitlistEntry
+	if err := tx.Joins("JOIN users ON users.id = waitlist_entries.user_id AND users.deleted_at IS NULL").
+		Where("waitlist_entries.hackathon_id = ?", hackathonID).Order("waitlist_entries.id").Limit(1).Find(&next).Error; err != nil || len(next) == 0 {
+		return nil, err
+	}
+	participant := models.Participant{HackathonId: int(hackathonID), UserId: int(next[0].UserID)}
+	if err := tx.Create(&participant).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Delete(&next[0]).Error; err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := tx.Where("id = ?", next[0].UserID).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,141 @@
+package repo
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"win-a-thon/database"
+	"win-a-thon/models"
+)
+
+const lockHackathonQuery = "SELECT `id` FROM `hackathons` WHERE id = ? AND `hackathons`.`deleted_at` IS NULL ORDER BY `hackathons`.`id` LIMIT 1 FOR UPDATE"
+
+func TestJoinHackathonFull(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockHackathonQuery)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL LIMIT 1")).
+		WithArgs(1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id"}))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `waitlist_entries` (`hackathon_id`,`user_id`,`created_at`) VALUES (?,?,?)")).
+		WithArgs(1, 3, AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `waitlist_entries` WHERE hackathon_id = ? AND id <= ?")).
+		WithArgs(1, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(4))
+	mock.ExpectCommit()
+
+	participant := models.Participant{HackathonId: 1, UserId: 3}
+	position, err := JoinHackathon(&participant, 2)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, position)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithdrawParticipantPromotesWaitlist(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockHackathonQuery)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL LIMIT 1")).
+		WithArgs(1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id", "team_id"}).AddRow(1, 3, nil))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `participants` WHERE hackathon_id = ? AND user_id = ?")).
+		WithArgs(1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT count(*) FROM `participants` WHERE hackathon_id = ? AND `participants`.`deleted_at` IS NULL")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `waitlist_entries`.`id`,`waitlist_entries`.`hackathon_id`,`waitlist_entries`.`user_id`,`waitlist_entries`.`created_at` FROM `waitlist_entries` JOIN users ON users.id = waitlist_entries.user_id AND users.deleted_at IS NULL WHERE waitlist_entries.hackathon_id = ? ORDER BY waitlist_entries.id LIMIT 1")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hackathon_id", "user_id"}).AddRow(7, 1, 5))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `participants` (`hackathon_id`,`user_id`,`demo_url`,`code_url`,`score`,`raw_score`,`judge_count`,`team_id`,`track_id`,`submitted_at`,`deleted_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs(1, 5, "", "", 0.0, 0.0, 0, nil, nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `waitlist_entries` WHERE `waitlist_entries`.`id` = ?")).
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `users` WHERE id = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1")).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email"}).AddRow(5, "next", "next@example.com"))
+	mock.ExpectCommit()
+
+	promoted, err := WithdrawParticipant(1, 3, 2)
+	require.NoError(t, err)
+	require.NotNil(t, promoted)
+	require.Equal(t, "next@example.com", promoted.Email)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithdrawParticipantNotRegistered(t *testing.T) {
+	db, mock, err = sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	database.DB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockHackathonQuery)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT * FROM `participants` WHERE (hackathon_id = ? AND user_id = ?) AND `participants`.`deleted_at` IS NULL LIMIT 1")).
+		WithArgs(1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"hackathon_id", "user_id"}))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `participants` WHERE hackathon_id = ? AND user_id = ?")).
+		WithArgs(1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `waitlist_entries` WHERE hackathon_id = ? AND user_id = ?")).
+		WithArgs(1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	promoted, err := WithdrawParticipant(1, 3, 2)
+	require.Equal(t, gorm.ErrRecordNotFound, err)
+	require.Nil(t, promoted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
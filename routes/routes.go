@@ -2,8 +2,10 @@ package routes
 
 import (
 	"fmt"
+	"github.com/BurntSushi/toml"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"win-a-thon/config"
 	"win-a-thon/controllers"
 	"win-a-thon/middlewares"
 	"win-a-thon/token"
@@ -19,6 +21,17 @@ func Setup() (*gin.Engine, error) {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	// no proxy is trusted unless configured, so that clients can't pick their address
+	// with X-Forwarded-For
+	appConfig := config.GetConfig()
+	if _, err := toml.DecodeFile("config/env.default.toml", &appConfig); err != nil {
+		fmt.Println(err)
+	}
+	r.TrustedProxies = appConfig.Application.TrustedProxies
+	if err := controllers.TrustProxies(r.TrustedProxies); err != nil {
+		return nil, err
+	}
+
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"data": "I'm alive! Are you?"})
 	})
@@ -41,25 +54,80 @@ func Setup() (*gin.Engine, error) {
 	protectedHackathons := r.Group("/hackathons").Use(middlewares.Authorization(tokenMaker))
 	{
 		protectedHackathons.POST("", controllers.CreateHackathon)
+		protectedHackathons.PATCH("/:hackathon_id", controllers.UpdateHackathon)
 		protectedHackathons.GET("/:hackathon_id/submissions", controllers.GetSubmissions)
+		protectedHackathons.GET("/:hackathon_id/submissions/timeline", controllers.GetSubmissionTimeline)
+		protectedHackathons.GET("/:hackathon_id/submissions/links", controllers.GetLinkReport)
 		protectedHackathons.GET("/:hackathon_id/participants", controllers.GetParticipants)
 		protectedHackathons.PATCH("/:hackathon_id/submissions/:username/judge", controllers.JudgeSubmission)
+		protectedHackathons.GET("/:hackathon_id/entries/:entry_id", controllers.GetEntry)
+		protectedHackathons.PATCH("/:hackathon_id/entries/:entry_id/judge", controllers.JudgeEntry)
 		protectedHackathons.POST("/:hackathon_id/notify/:username", controllers.NotifyParticipant)
 		protectedHackathons.POST("/:hackathon_id/notify", controllers.NotifyAll)
 		protectedHackathons.GET("/organise", controllers.ListOrganisedHackathons)
 		protectedHackathons.GET("/:hackathon_id/prize_distribution", controllers.PrizeDistribution)
 		protectedHackathons.POST("/:hackathon_id/participate", controllers.Participate)
+		protectedHackathons.DELETE("/:hackathon_id/participate", controllers.Withdraw)
+		protectedHackathons.GET("/:hackathon_id/teams", controllers.ListTeams)
+		protectedHackathons.POST("/:hackathon_id/teams", controllers.CreateTeam)
+		protectedHackathons.POST("/:hackathon_id/teams/:team_id/requests", controllers.RequestToJoinTeam)
+		protectedHackathons.PATCH("/:hackathon_id/teams/:team_id/requests/:request_id/:value", controllers.RespondToJoinRequest)
+		protectedHackathons.GET("/:hackathon_id/team", controllers.GetTeam)
+		protectedHackathons.POST("/:hackathon_id/tracks", controllers.CreateTrack)
+		protectedHackathons.DELETE("/:hackathon_id/tracks/:track_id", controllers.DeleteTrack)
+		protectedHackathons.POST("/:hackathon_id/prizes", controllers.CreatePrize)
+		protectedHackathons.DELETE("/:hackathon_id/prizes/:prize_id", controllers.DeletePrize)
+		protectedHackathons.PATCH("/:hackathon_id/prizes/:prize_id/award/:username", controllers.AwardPrize)
+		protectedHackathons.GET("/:hackathon_id/judges", controllers.ListJudges)
+		protectedHackathons.POST("/:hackathon_id/judges/:username", controllers.InviteJudge)
+		protectedHackathons.DELETE("/:hackathon_id/judges/:username", controllers.RemoveJudge)
+		protectedHackathons.GET("/:hackathon_id/assignments", controllers.ListAssignments)
+		protectedHackathons.POST("/:hackathon_id/assignments", controllers.AssignJudges)
+		protectedHackathons.GET("/:hackathon_id/conflicts", controllers.GetConflictReport)
+		protectedHackathons.POST("/:hackathon_id/conflicts/:username", controllers.DeclareConflict)
+		protectedHackathons.POST("/:hackathon_id/votes", controllers.CastVotes)
+		protectedHackathons.GET("/:hackathon_id/votes", controllers.GetVoteTally)
+		protectedHackathons.POST("/:hackathon_id/rubric", controllers.AddCriterion)
+		protectedHackathons.DELETE("/:hackathon_id/rubric/:criterion_id", controllers.DeleteCriterion)
+		protectedHackathons.GET("/:hackathon_id/scores", controllers.GetScores)
+		protectedHackathons.GET("/:hackathon_id/scores/mine", controllers.GetMyScores)
+		protectedHackathons.GET("/:hackathon_id/feedback/mine", controllers.GetMyFeedback)
+		protectedHackathons.GET("/:hackathon_id/scores/judges", controllers.GetJudgeReport)
+		protectedHackathons.POST("/:hackathon_id/team/join", controllers.JoinTeam)
+		protectedHackathons.DELETE("/:hackathon_id/team", controllers.LeaveTeam)
 		protectedHackathons.PATCH("/:hackathon_id/submit", controllers.UpdateSubmission)
+		protectedHackathons.GET("/:hackathon_id/artifacts", controllers.ListArtifacts)
+		protectedHackathons.DELETE("/:hackathon_id/artifacts/:artifact_id", controllers.DeleteArtifact)
 		protectedHackathons.PATCH("/:hackathon_id/approve/:value", controllers.GetAdminApproval)
+		protectedHackathons.PATCH("/:hackathon_id/request_approval", controllers.RequestApproval)
+		protectedHackathons.PATCH("/:hackathon_id/archive", controllers.ArchiveHackathon)
+		protectedHackathons.PATCH("/:hackathon_id/cancel", controllers.CancelHackathon)
+		protectedHackathons.GET("/:hackathon_id/history", controllers.GetHackathonHistory)
 		protectedHackathons.GET("/unapproved", controllers.ListUnapprovedHackathons)
 		protectedHackathons.GET("/:hackathon_id/user/:username/submission", controllers.GetSubmissionOfParticipant)
 	}
 
+	admin := r.Group("/admin").Use(middlewares.Authorization(tokenMaker))
+	{
+		admin.GET("/deleted/users", controllers.ListDeletedUsers)
+		admin.GET("/deleted/hackathons", controllers.ListDeletedHackathons)
+		admin.PATCH("/users/:user_id/restore", controllers.RestoreUser)
+		admin.PATCH("/hackathons/:hackathon_id/restore", controllers.RestoreHackathon)
+		admin.DELETE("/users/:user_id", controllers.PurgeUser)
+		admin.DELETE("/hackathons/:hackathon_id", controllers.PurgeHackathon)
+	}
+
 	hackathons := r.Group("/hackathons")
 	{
 		hackathons.GET("", controllers.ListHackathons)
+		hackathons.GET("/search", controllers.SearchHackathons)
 		hackathons.GET("/:hackathon_id", controllers.ViewHackathonDetails)
 		hackathons.GET("/:hackathon_id/leaderboard", controllers.GetLeaderboard)
+		hackathons.GET("/:hackathon_id/tracks", controllers.ListTracks)
+		hackathons.GET("/:hackathon_id/prizes", controllers.ListPrizes)
+		hackathons.GET("/:hackathon_id/rubric", controllers.GetRubric)
+		hackathons.GET("/:hackathon_id/ballot", controllers.GetBallot)
+		hackathons.GET("/:hackathon_id/artifacts/:artifact_id/download", controllers.DownloadArtifact)
 	}
 
 	return r, nil
@@ -0,0 +1,112 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"win-a-thon/config"
+)
+
+// ErrInfected is returned by a scanner that found a virus in a file
+var ErrInfected = errors.New("the file is infected")
+
+// Scanner checks an uploaded file for viruses, failing with ErrInfected when it
+// finds one and with another error when it couldn't scan the file
+type Scanner interface {
+	Scan(ctx context.Context, name string, content io.Reader) error
+}
+
+// ScannerFunc lets a function be registered as a scanner
+type ScannerFunc func(ctx context.Context, name string, content io.Reader) error
+
+func (scan ScannerFunc) Scan(ctx context.Context, name string, content io.Reader) error {
+	return scan(ctx, name, content)
+}
+
+var (
+	mutex    sync.RWMutex
+	scanners []Scanner
+)
+
+// Register adds a scanner every upload goes through from now on
+func Register(scanner Scanner) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	scanners = append(scanners, scanner)
+}
+
+// Reset removes every registered scanner
+func Reset() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	scanners = nil
+}
+
+// File runs an uploaded file through every registered scanner in turn, opening it
+// afresh for each, and stops at the first that doesn't pass it. Files pass when no
+// scanner is registered.
+func File(ctx context.Context, name string, open func() (io.ReadCloser, error)) error {
+	mutex.RLock()
+	registered := append([]Scanner(nil), scanners...)
+	mutex.RUnlock()
+
+	for _, scanner := range registered {
+		content, err := open()
+		if err != nil {
+			return err
+		}
+		err = scanner.Scan(ctx, name, content)
+		content.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Command scans files by piping them to an external command, like clamdscan, that
+// exits with 0 when the file is clean and 1 when it is infected
+type Command struct {
+	Path string
+	Args []string
+}
+
+func (command Command) Scan(ctx context.Context, _ string, content io.Reader) error {
+	cmd := exec.CommandContext(ctx, command.Path, command.Args...)
+	cmd.Stdin = content
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == 1 {
+		return ErrInfected
+	}
+	if err != nil {
+		return fmt.Errorf("%s failed: %v: %s", command.Path, err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+// Setup reads the scan command of the artifacts settings and, unless there is
+// none, registers it as a scanner
+func Setup() {
+	appConfig := config.GetConfig()
+	if _, err := toml.DecodeFile("config/env.default.toml", &appConfig); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fields := strings.Fields(appConfig.Artifacts.ScanCommand)
+	if len(fields) == 0 {
+		fmt.Println("artifact virus scanning disabled")
+		return
+	}
+	Register(Command{Path: fields[0], Args: fields[1:]})
+}
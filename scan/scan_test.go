@@ -0,0 +1,61 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func opener(content string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(content)), nil
+	}
+}
+
+func TestFile(t *testing.T) {
+	defer Reset()
+	ctx := context.Background()
+	require.NoError(t, File(ctx, "deck.pdf", opener("anything")))
+
+	scanned := 0
+	Register(ScannerFunc(func(_ context.Context, _ string, content io.Reader) error {
+		scanned++
+		read, err := ioutil.ReadAll(content)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(read, []byte("EICAR")) {
+			return ErrInfected
+		}
+		return nil
+	}))
+	Register(ScannerFunc(func(_ context.Context, _ string, content io.Reader) error {
+		scanned++
+		return nil
+	}))
+
+	require.NoError(t, File(ctx, "deck.pdf", opener("slides")))
+	require.Equal(t, 2, scanned)
+	// the second scanner isn't asked once the first finds a virus
+	require.Equal(t, ErrInfected, File(ctx, "deck.pdf", opener("X5O!P%@AP EICAR test")))
+	require.Equal(t, 3, scanned)
+}
+
+func TestCommand(t *testing.T) {
+	ctx := context.Background()
+	// exits with 1, like clamdscan, when the file holds the test signature
+	command := Command{Path: "sh", Args: []string{"-c", "if grep -q EICAR; then exit 1; fi"}}
+
+	require.NoError(t, command.Scan(ctx, "deck.pdf", strings.NewReader("slides")))
+	require.Equal(t, ErrInfected, command.Scan(ctx, "deck.pdf", strings.NewReader("EICAR test")))
+
+	broken := Command{Path: "sh", Args: []string{"-c", "echo database missing; exit 2"}}
+	err := broken.Scan(ctx, "deck.pdf", strings.NewReader("slides"))
+	require.Error(t, err)
+	require.NotEqual(t, ErrInfected, err)
+	require.Contains(t, err.Error(), "database missing")
+}
@@ -0,0 +1,117 @@
+package scoring
+
+import (
+	"math"
+	"sort"
+	"win-a-thon/models"
+)
+
+// Ways the scores of each judge are put on a common scale before they are combined,
+// so entries don't win or lose on which judges they happened to get
+const (
+	ZScore = "z_score"
+	Rank   = "rank"
+)
+
+// IsValidNormalization reports whether method is one of the normalization methods.
+// Empty leaves the scores as the judges gave them.
+func IsValidNormalization(method string) bool {
+	switch method {
+	case "", ZScore, Rank:
+		return true
+	}
+	return false
+}
+
+// Normalize puts the scores of every judge on a common scale and returns the
+// normalized score at the index of each score.
+//
+// With ZScore a score becomes how many standard deviations it lies from the mean
+// of its judge, mapped back onto the mean and spread of all the scores so it reads
+// like a raw one. With Rank it becomes the percentile it ranks at among the scores
+// of its judge, from 0 for their lowest to 100 for their highest, ties sharing one.
+func Normalize(method string, scores []models.JudgeScore) []float64 {
+	normalized := make([]float64, len(scores))
+	byJudge := make(map[uint][]float64)
+	for _, score := range scores {
+		byJudge[score.JudgeID] = append(byJudge[score.JudgeID], score.Score)
+	}
+
+	switch method {
+	case ZScore:
+		all := make([]float64, len(scores))
+		for i, score := range scores {
+			all[i] = score.Score
+		}
+		pooled := Summarize(all)
+
+		judges := make(map[uint]Summary)
+		for judgeID, given := range byJudge {
+			judges[judgeID] = Summarize(given)
+		}
+		for i, score := range scores {
+			judge := judges[score.JudgeID]
+			z := 0.0
+			if judge.StdDev > 0 {
+				z = (score.Score - judge.Mean) / judge.StdDev
+			}
+			normalized[i] = pooled.Mean + z*pooled.StdDev
+		}
+	case Rank:
+		for i, score := range scores {
+			given := byJudge[score.JudgeID]
+			if len(given) == 1 {
+				normalized[i] = 50
+				continue
+			}
+			below, equal := 0, 0
+			for _, other := range given {
+				if other < score.Score {
+					below++
+				} else if other == score.Score {
+					equal++
+				}
+			}
+			normalized[i] = 100 * (float64(below) + float64(equal-1)/2) / float64(len(given)-1)
+		}
+	default:
+		for i, score := range scores {
+			normalized[i] = score.Score
+		}
+	}
+	return normalized
+}
+
+// Summary describes the spread of a set of scores
+type Summary struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+	Min    float64 `json:"min"`
+	Median float64 `json:"median"`
+	Max    float64 `json:"max"`
+}
+
+// Summarize describes the spread of scores, using the population standard deviation
+func Summarize(scores []float64) Summary {
+	if len(scores) == 0 {
+		return Summary{}
+	}
+
+	sorted := append([]float64(nil), scores...)
+	sort.Float64s(sorted)
+
+	summary := Summary{
+		Count:  len(sorted),
+		Mean:   Aggregate(Mean, sorted),
+		Min:    sorted[0],
+		Median: Aggregate(Median, sorted),
+		Max:    sorted[len(sorted)-1],
+	}
+	variance := 0.0
+	for _, score := range sorted {
+		variance += (score - summary.Mean) * (score - summary.Mean)
+	}
+	summary.StdDev = math.Sqrt(variance / float64(len(sorted)))
+	return summary
+}
@@ -0,0 +1,49 @@
+package scoring
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"win-a-thon/models"
+)
+
+// a harsh judge and a lenient one who agree on how the entries they scored compare
+var judged = []models.JudgeScore{
+	{JudgeID: 1, UserID: 10, Score: 2},
+	{JudgeID: 1, UserID: 11, Score: 4},
+	{JudgeID: 2, UserID: 12, Score: 8},
+	{JudgeID: 2, UserID: 13, Score: 10},
+}
+
+func TestNormalizeZScore(t *testing.T) {
+	normalized := Normalize(ZScore, judged)
+
+	// the best entry of each judge ends up level, whichever judge scored it
+	require.InDelta(t, normalized[1], normalized[3], 1e-9)
+	require.InDelta(t, normalized[0], normalized[2], 1e-9)
+	require.Greater(t, normalized[1], normalized[0])
+
+	// and the scores keep the mean of the hackathon
+	require.InDelta(t, 6.0, Aggregate(Mean, normalized), 1e-9)
+}
+
+func TestNormalizeRank(t *testing.T) {
+	scores := append(judged, models.JudgeScore{JudgeID: 1, UserID: 14, Score: 4}, models.JudgeScore{JudgeID: 3, UserID: 10, Score: 7})
+
+	require.Equal(t, []float64{0, 75, 0, 100, 75, 50}, Normalize(Rank, scores))
+}
+
+func TestNormalizeNone(t *testing.T) {
+	require.Equal(t, []float64{2, 4, 8, 10}, Normalize("", judged))
+}
+
+func TestSummarize(t *testing.T) {
+	summary := Summarize([]float64{4, 2, 8, 6})
+	require.Equal(t, 4, summary.Count)
+	require.Equal(t, 5.0, summary.Mean)
+	require.Equal(t, 5.0, summary.Median)
+	require.Equal(t, 2.0, summary.Min)
+	require.Equal(t, 8.0, summary.Max)
+	require.InDelta(t, 2.236, summary.StdDev, 1e-3)
+
+	require.Equal(t, Summary{}, Summarize(nil))
+}
@@ -0,0 +1,148 @@
+package scoring
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"win-a-thon/models"
+)
+
+// Tie-breakers order entries with the same score. Any number of them may be given
+// in order, each one breaking the ties the ones before it leave.
+const (
+	// EarliestSubmission puts the entry submitted first ahead
+	EarliestSubmission = "earliest_submission"
+	// CriterionPrefix followed by the name of a criterion of the rubric puts the
+	// entry with more points on it ahead
+	CriterionPrefix = "criterion:"
+)
+
+// ErrInvalidTieBreaker is returned for tie-breakers that are neither of the above
+var ErrInvalidTieBreaker = errors.New("Tie-breakers should be earliest_submission or criterion:<name>")
+
+// Standing is the place of an entry on the leaderboard of a hackathon
+type Standing struct {
+	Members     []models.Participant // the participant competing alone or the members of a team
+	Score       float64
+	SubmittedAt *time.Time         // when the entry was last submitted, nil when it never was
+	Criteria    map[string]float64 // points on each criterion by name, averaged over the judges
+	Rank        int
+}
+
+// ParseTieBreakers splits the tie-breakers of a hackathon, separated by commas.
+// Without any, entries with the same score share their rank.
+func ParseTieBreakers(spec string) ([]string, error) {
+	var tieBreakers []string
+	for _, tieBreaker := range strings.Split(spec, ",") {
+		tieBreaker = strings.TrimSpace(tieBreaker)
+		if tieBreaker == "" {
+			continue
+		}
+		if _, ok := TieBreakerCriterion(tieBreaker); !ok && tieBreaker != EarliestSubmission {
+			return nil, ErrInvalidTieBreaker
+		}
+		tieBreakers = append(tieBreakers, tieBreaker)
+	}
+	return tieBreakers, nil
+}
+
+// TieBreakerCriterion is the name of the criterion a tie-breaker compares points
+// on, when it does
+func TieBreakerCriterion(tieBreaker string) (string, bool) {
+	if !strings.HasPrefix(tieBreaker, CriterionPrefix) {
+		return "", false
+	}
+	name := strings.TrimSpace(strings.TrimPrefix(tieBreaker, CriterionPrefix))
+	return name, name != ""
+}
+
+// RankStandings orders standings by score, then by each tie-breaker in turn, and gives them
+// standard competition ranks: entries still tied share a rank and as many ranks
+// after them are skipped, as in 1, 2, 2, 4. Entries sharing a rank are listed by
+// the ID of their team or participant so the order stays the same every time.
+func RankStandings(standings []Standing, tieBreakers []string) {
+	sort.SliceStable(standings, func(i, j int) bool {
+		if order := compare(standings[i], standings[j], tieBreakers); order != 0 {
+			return order < 0
+		}
+		return entryOrder(standings[i]) < entryOrder(standings[j])
+	})
+
+	for i := range standings {
+		if i > 0 && compare(standings[i-1], standings[i], tieBreakers) == 0 {
+			standings[i].Rank = standings[i-1].Rank
+		} else {
+			standings[i].Rank = i + 1
+		}
+	}
+}
+
+// compare is below 0 when a ranks ahead of b, above 0 when b does and 0 when they
+// are tied
+func compare(a Standing, b Standing, tieBreakers []string) int {
+	if order := higherFirst(a.Score, b.Score); order != 0 {
+		return order
+	}
+
+	for _, tieBreaker := range tieBreakers {
+		order := 0
+		if name, ok := TieBreakerCriterion(tieBreaker); ok {
+			order = higherFirst(points(a, name), points(b, name))
+		} else if tieBreaker == EarliestSubmission {
+			order = earlierFirst(a.SubmittedAt, b.SubmittedAt)
+		}
+		if order != 0 {
+			return order
+		}
+	}
+	return 0
+}
+
+// points on a criterion, below any real points when the entry got none
+func points(standing Standing, criterion string) float64 {
+	if points, ok := standing.Criteria[criterion]; ok {
+		return points
+	}
+	return math.Inf(-1)
+}
+
+func higherFirst(a float64, b float64) int {
+	// scores combined from different judges may differ by rounding alone
+	if math.Abs(a-b) < 1e-9 || math.IsInf(a, -1) && math.IsInf(b, -1) {
+		return 0
+	}
+	if a > b {
+		return -1
+	}
+	return 1
+}
+
+// earlierFirst puts entries that were never submitted last
+func earlierFirst(a *time.Time, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	case a.Before(*b):
+		return -1
+	case b.Before(*a):
+		return 1
+	}
+	return 0
+}
+
+// entryOrder sorts teams after participants competing alone, each by ID
+func entryOrder(standing Standing) uint64 {
+	if len(standing.Members) == 0 {
+		return math.MaxUint64
+	}
+	if teamID := standing.Members[0].TeamID; teamID != nil {
+		return 1<<32 + uint64(*teamID)
+	}
+	return uint64(standing.Members[0].UserId)
+}
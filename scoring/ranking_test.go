@@ -0,0 +1,59 @@
+package scoring
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"win-a-thon/models"
+)
+
+func standing(userID int, score float64) Standing {
+	return Standing{Members: []models.Participant{{UserId: userID}}, Score: score}
+}
+
+func ranks(standings []Standing) ([]int, []int) {
+	var users, ranks []int
+	for _, s := range standings {
+		users = append(users, s.Members[0].UserId)
+		ranks = append(ranks, s.Rank)
+	}
+	return users, ranks
+}
+
+func TestRankStandingsSharesTies(t *testing.T) {
+	standings := []Standing{standing(4, 70), standing(3, 90), standing(2, 70), standing(1, 50)}
+
+	RankStandings(standings, nil)
+	users, got := ranks(standings)
+	require.Equal(t, []int{3, 2, 4, 1}, users)
+	require.Equal(t, []int{1, 2, 2, 4}, got)
+}
+
+func TestRankStandingsBreaksTies(t *testing.T) {
+	early := time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	a, b, c, d := standing(1, 70), standing(2, 70), standing(3, 70), standing(4, 70)
+	a.Criteria = map[string]float64{"Innovation": 8}
+	b.Criteria = map[string]float64{"Innovation": 9}
+	c.Criteria = map[string]float64{"Innovation": 8}
+	a.SubmittedAt, c.SubmittedAt = &late, &early
+	standings := []Standing{a, b, c, d}
+
+	RankStandings(standings, []string{"criterion:Innovation", EarliestSubmission})
+	users, got := ranks(standings)
+	// d has no points on the criterion, a and c are split by who submitted first
+	require.Equal(t, []int{2, 3, 1, 4}, users)
+	require.Equal(t, []int{1, 2, 3, 4}, got)
+}
+
+func TestParseTieBreakers(t *testing.T) {
+	tieBreakers, err := ParseTieBreakers(" criterion:Design, earliest_submission ,")
+	require.NoError(t, err)
+	require.Equal(t, []string{"criterion:Design", EarliestSubmission}, tieBreakers)
+
+	_, err = ParseTieBreakers("criterion: ")
+	require.Equal(t, ErrInvalidTieBreaker, err)
+	_, err = ParseTieBreakers("coin_toss")
+	require.Equal(t, ErrInvalidTieBreaker, err)
+}
@@ -0,0 +1,76 @@
+package scoring
+
+import (
+	"sort"
+	"win-a-thon/models"
+)
+
+// Ways the scores judges give an entry are combined into its leaderboard score
+const (
+	Mean        = "mean"
+	Median      = "median"
+	TrimmedMean = "trimmed_mean"
+)
+
+// IsValid reports whether method is one of the aggregation methods. Empty stands
+// for the mean.
+func IsValid(method string) bool {
+	switch method {
+	case "", Mean, Median, TrimmedMean:
+		return true
+	}
+	return false
+}
+
+// Aggregate combines the scores the judges gave an entry. The trimmed mean drops
+// the lowest and the highest score once there are at least three of them, so a
+// single harsh or generous judge can't swing the result.
+func Aggregate(method string, scores []float64) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), scores...)
+	sort.Float64s(sorted)
+
+	switch method {
+	case Median:
+		middle := len(sorted) / 2
+		if len(sorted)%2 == 1 {
+			return sorted[middle]
+		}
+		return (sorted[middle-1] + sorted[middle]) / 2
+	case TrimmedMean:
+		if len(sorted) >= 3 {
+			sorted = sorted[1 : len(sorted)-1]
+		}
+	}
+
+	sum := 0.0
+	for _, score := range sorted {
+		sum += score
+	}
+	return sum / float64(len(sorted))
+}
+
+// Weighted totals the points a judge gave for each criterion of a rubric into a
+// score out of 100, where each criterion counts as much as its weight
+func Weighted(criteria []models.Criterion, breakdown []models.CriterionScore) float64 {
+	points := make(map[uint]float64)
+	for _, score := range breakdown {
+		points[score.CriterionID] = score.Points
+	}
+
+	total, weights := 0.0, 0.0
+	for _, criterion := range criteria {
+		if criterion.MaxPoints <= 0 {
+			continue
+		}
+		total += criterion.Weight * points[criterion.ID] / criterion.MaxPoints
+		weights += criterion.Weight
+	}
+	if weights == 0 {
+		return 0
+	}
+	return 100 * total / weights
+}
@@ -0,0 +1,41 @@
+package scoring
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"win-a-thon/models"
+)
+
+func TestAggregate(t *testing.T) {
+	scores := []float64{9, 2, 7, 6}
+
+	require.Equal(t, 6.0, Aggregate(Mean, scores))
+	require.Equal(t, 6.0, Aggregate("", scores))
+	require.Equal(t, 6.5, Aggregate(Median, scores))
+	require.Equal(t, 6.5, Aggregate(TrimmedMean, scores))
+	require.Equal(t, 7.0, Aggregate(Median, []float64{9, 2, 7}))
+
+	// too few scores to trim
+	require.Equal(t, 5.0, Aggregate(TrimmedMean, []float64{2, 8}))
+	require.Equal(t, 0.0, Aggregate(Median, nil))
+
+	// the scores are left in the order the judges gave them
+	require.Equal(t, []float64{9, 2, 7, 6}, scores)
+}
+
+func TestIsValid(t *testing.T) {
+	require.True(t, IsValid(""))
+	require.True(t, IsValid(TrimmedMean))
+	require.False(t, IsValid("max"))
+}
+
+func TestWeighted(t *testing.T) {
+	criteria := []models.Criterion{
+		{ID: 1, Name: "Innovation", MaxPoints: 10, Weight: 3},
+		{ID: 2, Name: "Execution", MaxPoints: 5, Weight: 1},
+	}
+
+	require.Equal(t, 100.0, Weighted(criteria, []models.CriterionScore{{CriterionID: 1, Points: 10}, {CriterionID: 2, Points: 5}}))
+	require.Equal(t, 70.0, Weighted(criteria, []models.CriterionScore{{CriterionID: 1, Points: 8}, {CriterionID: 2, Points: 2}}))
+	require.Equal(t, 0.0, Weighted(nil, nil))
+}
@@ -0,0 +1,76 @@
+package scoring
+
+import (
+	"sort"
+	"win-a-thon/models"
+)
+
+// VoteCount is the People's Choice tally of an entry, kept apart from the score the
+// judges give it
+type VoteCount struct {
+	UserID uint // 0 for teams
+	TeamID uint // 0 for participants competing alone
+	Points int
+	Votes  int
+	Rank   int
+}
+
+// TallyVotes counts the votes of a hackathon with ballots of up to ballotSize ranked
+// votes. A first choice earns ballotSize points, a second choice one point less and
+// so on, so a single vote earns one point. Entries are ranked by points, then by
+// number of votes, and share their rank when both are the same.
+func TallyVotes(votes []models.Vote, ballotSize int) []VoteCount {
+	if ballotSize < 1 {
+		ballotSize = 1
+	}
+
+	type entry struct{ userID, teamID uint }
+	counts := make(map[entry]*VoteCount)
+	tally := make([]VoteCount, 0)
+	order := make([]entry, 0)
+	for _, vote := range votes {
+		key := entry{vote.UserID, vote.TeamID}
+		if _, ok := counts[key]; !ok {
+			counts[key] = &VoteCount{UserID: vote.UserID, TeamID: vote.TeamID}
+			order = append(order, key)
+		}
+		if points := ballotSize - vote.Position + 1; points > 0 {
+			counts[key].Points += points
+		}
+		counts[key].Votes++
+	}
+	for _, key := range order {
+		tally = append(tally, *counts[key])
+	}
+
+	// below 0 when a ranks ahead of b, 0 when they are tied
+	ahead := func(a VoteCount, b VoteCount) int {
+		if a.Points != b.Points {
+			return b.Points - a.Points
+		}
+		return b.Votes - a.Votes
+	}
+	sort.SliceStable(tally, func(i, j int) bool {
+		if order := ahead(tally[i], tally[j]); order != 0 {
+			return order < 0
+		}
+		return voteOrder(tally[i]) < voteOrder(tally[j])
+	})
+	for i := range tally {
+		if i > 0 && ahead(tally[i-1], tally[i]) == 0 {
+			tally[i].Rank = tally[i-1].Rank
+		} else {
+			tally[i].Rank = i + 1
+		}
+	}
+	return tally
+}
+
+// voteOrder sorts teams after participants competing alone, each by ID, as the
+// leaderboard does
+func voteOrder(count VoteCount) uint64 {
+	if count.TeamID != 0 {
+		return 1<<32 + uint64(count.TeamID)
+	}
+	return uint64(count.UserID)
+}
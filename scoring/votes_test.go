@@ -0,0 +1,42 @@
+package scoring
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"win-a-thon/models"
+)
+
+func TestTallyVotesSingleVote(t *testing.T) {
+	votes := []models.Vote{
+		{VoterID: 1, UserID: 4, Position: 1},
+		{VoterID: 2, TeamID: 1, Position: 1},
+		{VoterID: 3, UserID: 4, Position: 1},
+		{VoterID: 5, UserID: 3, Position: 1},
+	}
+
+	tally := TallyVotes(votes, 0)
+	require.Equal(t, []VoteCount{
+		{UserID: 4, Points: 2, Votes: 2, Rank: 1},
+		{UserID: 3, Points: 1, Votes: 1, Rank: 2},
+		{TeamID: 1, Points: 1, Votes: 1, Rank: 2},
+	}, tally)
+}
+
+func TestTallyVotesRanked(t *testing.T) {
+	votes := []models.Vote{
+		{VoterID: 1, UserID: 4, Position: 1},
+		{VoterID: 1, UserID: 3, Position: 2},
+		{VoterID: 1, TeamID: 1, Position: 3},
+		{VoterID: 2, UserID: 3, Position: 1},
+		{VoterID: 2, UserID: 4, Position: 2},
+		{VoterID: 6, TeamID: 1, Position: 1},
+	}
+
+	// user 4 and user 3 earn 3+2 points, team 1 earns 1+3 points
+	tally := TallyVotes(votes, 3)
+	require.Equal(t, []VoteCount{
+		{UserID: 3, Points: 5, Votes: 2, Rank: 1},
+		{UserID: 4, Points: 5, Votes: 2, Rank: 1},
+		{TeamID: 1, Points: 4, Votes: 2, Rank: 3},
+	}, tally)
+}
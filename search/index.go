@@ -0,0 +1,245 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"win-a-thon/models"
+)
+
+// Weights of the indexed hackathon fields, a hit in the title counts the most
+const (
+	titleWeight        = 3.0
+	organisationWeight = 2.0
+	descriptionWeight  = 1.0
+)
+
+// Match qualities, an exact term beats a prefix which beats a misspelling
+const (
+	exactMatch  = 1.0
+	prefixMatch = 0.7
+	fuzzyMatch  = 0.5
+)
+
+// Result is a hackathon matching a query together with its relevance
+type Result struct {
+	ID    uint    `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// Index is an in-memory inverted index over hackathon titles, descriptions and
+// organisation names. It is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[uint]float64
+	docTerms map[uint][]string
+}
+
+// NewIndex returns an empty index
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[uint]float64),
+		docTerms: make(map[uint][]string),
+	}
+}
+
+// Tokenize lower-cases text and splits it into letter and digit runs
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Add indexes a hackathon, replacing what was indexed before under the same id
+func (index *Index) Add(hackathon models.Hackathon) {
+	weights := make(map[string]float64)
+	for _, field := range []struct {
+		text   string
+		weight float64
+	}{
+		{hackathon.Title, titleWeight},
+		{hackathon.OrganisationName, organisationWeight},
+		{hackathon.Description, descriptionWeight},
+	} {
+		counts := make(map[string]int)
+		for _, term := range Tokenize(field.text) {
+			counts[term]++
+		}
+		for term, count := range counts {
+			weights[term] += field.weight * (1 + math.Log(float64(count)))
+		}
+	}
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	index.remove(hackathon.ID)
+	terms := make([]string, 0, len(weights))
+	for term, weight := range weights {
+		if index.postings[term] == nil {
+			index.postings[term] = make(map[uint]float64)
+		}
+		index.postings[term][hackathon.ID] = weight
+		terms = append(terms, term)
+	}
+	index.docTerms[hackathon.ID] = terms
+}
+
+// Remove drops a hackathon from the index
+func (index *Index) Remove(id uint) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	index.remove(id)
+}
+
+func (index *Index) remove(id uint) {
+	for _, term := range index.docTerms[id] {
+		delete(index.postings[term], id)
+		if len(index.postings[term]) == 0 {
+			delete(index.postings, term)
+		}
+	}
+	delete(index.docTerms, id)
+}
+
+// Len returns the number of indexed hackathons
+func (index *Index) Len() int {
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+	return len(index.docTerms)
+}
+
+// Search ranks the indexed hackathons against a free text query. Every query
+// term is matched exactly, as the prefix of an indexed term, or with a small
+// number of typos, and hackathons matching more of the query rank higher.
+func (index *Index) Search(query string, limit int) []Result {
+	queryTerms := Tokenize(query)
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+
+	documents := float64(len(index.docTerms))
+	scores := make(map[uint]float64)
+	matched := make(map[uint]int)
+
+	for _, queryTerm := range queryTerms {
+		best := make(map[uint]float64)
+		for term, posting := range index.postings {
+			quality := matchQuality(queryTerm, term)
+			if quality == 0 {
+				continue
+			}
+			idf := math.Log(1 + documents/float64(len(posting)))
+			for id, weight := range posting {
+				if score := quality * weight * idf; score > best[id] {
+					best[id] = score
+				}
+			}
+		}
+		for id, score := range best {
+			scores[id] += score
+			matched[id]++
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		coverage := float64(matched[id]) / float64(len(queryTerms))
+		results = append(results, Result{ID: id, Score: math.Round(score*coverage*1000) / 1000})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// matchQuality tells how well an indexed term matches a query term, 0 meaning not at all
+func matchQuality(queryTerm string, term string) float64 {
+	if term == queryTerm {
+		return exactMatch
+	}
+	if strings.HasPrefix(term, queryTerm) {
+		return prefixMatch
+	}
+
+	allowed := allowedTypos(queryTerm)
+	if allowed == 0 {
+		return 0
+	}
+	if editDistance(queryTerm, term, allowed) <= allowed {
+		return fuzzyMatch
+	}
+	query := []rune(queryTerm)
+	if runes := []rune(term); len(runes) > len(query) && editDistance(queryTerm, string(runes[:len(query)]), allowed) <= allowed {
+		return fuzzyMatch * prefixMatch
+	}
+	return 0
+}
+
+// allowedTypos grows with the length of the query term so short words stay strict
+func allowedTypos(term string) int {
+	switch length := len([]rune(term)); {
+	case length >= 8:
+		return 2
+	case length >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// editDistance is the Damerau-Levenshtein distance between a and b, giving up
+// with max+1 as soon as it is known to exceed max
+func editDistance(a string, b string, max int) int {
+	s, t := []rune(a), []rune(b)
+	if diff := len(s) - len(t); diff > max || -diff > max {
+		return max + 1
+	}
+
+	previous2 := make([]int, len(t)+1)
+	previous := make([]int, len(t)+1)
+	current := make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				current[j] = minInt(current[j], previous2[j-2]+1)
+			}
+			rowMin = minInt(rowMin, current[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		previous2, previous, current = previous, current, previous2
+	}
+	return previous[len(t)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
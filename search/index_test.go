@@ -0,0 +1,79 @@
+package search
+
+import (
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"win-a-thon/models"
+)
+
+func newHackathon(id uint, title string, organisation string, description string) models.Hackathon {
+	return models.Hackathon{
+		Model:            gorm.Model{ID: id},
+		Title:            title,
+		OrganisationName: organisation,
+		Description:      description,
+	}
+}
+
+func testIndex() *Index {
+	index := NewIndex()
+	index.Add(newHackathon(1, "Fintech Sprint", "Razorpay", "Build payment products for small businesses."))
+	index.Add(newHackathon(2, "Open Source Jam", "Fintech Labs", "Contribute to open source tooling."))
+	index.Add(newHackathon(3, "AI for Good", "Data Guild", "Machine learning for social impact, fintech welcome."))
+	index.Add(newHackathon(4, "Climate Hack", "Green Earth", "Tools for a cooler planet."))
+	return index
+}
+
+func ids(results []Result) []uint {
+	var ids []uint
+	for _, result := range results {
+		ids = append(ids, result.ID)
+	}
+	return ids
+}
+
+func TestSearchRanksTitleAboveOtherFields(t *testing.T) {
+	results := testIndex().Search("fintech", 10)
+	require.Equal(t, []uint{1, 2, 3}, ids(results))
+	require.Greater(t, results[0].Score, results[1].Score)
+	require.Greater(t, results[1].Score, results[2].Score)
+}
+
+func TestSearchPrefixAndTypos(t *testing.T) {
+	index := testIndex()
+
+	require.Equal(t, []uint{4}, ids(index.Search("clim", 10)))
+	require.Equal(t, []uint{4}, ids(index.Search("climte", 10)))
+	require.Equal(t, []uint{1, 2, 3}, ids(index.Search("fintehc", 10)))
+	require.Equal(t, []uint{3}, ids(index.Search("AI", 10)))
+	require.Empty(t, index.Search("blockchain", 10))
+}
+
+func TestSearchPrefersDocumentsMatchingEveryTerm(t *testing.T) {
+	results := testIndex().Search("open source fintech", 10)
+	require.Equal(t, uint(2), results[0].ID)
+}
+
+func TestSearchAfterUpdateAndRemove(t *testing.T) {
+	index := testIndex()
+
+	index.Add(newHackathon(4, "Ocean Hack", "Blue Planet", "Clean seas."))
+	require.Empty(t, index.Search("climate", 10))
+	require.Equal(t, []uint{4}, ids(index.Search("ocean", 10)))
+
+	index.Remove(4)
+	require.Empty(t, index.Search("ocean", 10))
+	require.Equal(t, 3, index.Len())
+}
+
+func TestSearchLimit(t *testing.T) {
+	require.Len(t, testIndex().Search("fintech", 2), 2)
+}
+
+func TestEditDistance(t *testing.T) {
+	require.Equal(t, 0, editDistance("hack", "hack", 2))
+	require.Equal(t, 1, editDistance("hcak", "hack", 2))
+	require.Equal(t, 1, editDistance("hackk", "hack", 2))
+	require.Equal(t, 3, editDistance("hack", "planet", 2))
+}
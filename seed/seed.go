@@ -0,0 +1,304 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+	"win-a-thon/assignment"
+	"win-a-thon/database"
+	"win-a-thon/lifecycle"
+	"win-a-thon/models"
+	"win-a-thon/repo"
+	"win-a-thon/utils"
+)
+
+// DefaultPassword is the password every seeded account can log in with
+const DefaultPassword = "winathon123"
+
+var (
+	firstNames    = []string{"Aarav", "Priya", "Rohan", "Ananya", "Kabir", "Isha", "Vivaan", "Meera", "Arjun", "Sara", "Dev", "Nisha", "Kunal", "Tara", "Yash", "Zoya"}
+	lastNames     = []string{"Sharma", "Iyer", "Mehta", "Reddy", "Kapoor", "Nair", "Gupta", "Das", "Singh", "Rao", "Bose", "Jain"}
+	organisations = []string{"Razorpay", "Open Source Society", "Fintech Labs", "IIT Delhi", "Cloud Natives", "Data Guild", "Student Devs"}
+	themes        = []string{"Fintech", "AI for Good", "Open Source", "Climate Tech", "HealthTech", "Web3", "EdTech", "Mobility", "Cyber Security", "Dev Tools"}
+	formats       = []string{"Hack", "Sprint", "Jam", "Challenge", "Summit Hack", "Buildathon"}
+)
+
+// Phase is the point in the hackathon lifecycle a seeded hackathon is placed in
+type Phase string
+
+const (
+	PhaseUpcoming  Phase = "upcoming"
+	PhaseActive    Phase = "active"
+	PhaseJudging   Phase = "judging"
+	PhaseCompleted Phase = "completed"
+)
+
+var phases = []Phase{PhaseUpcoming, PhaseActive, PhaseJudging, PhaseCompleted}
+
+// layout places the seeded hackathons so that every lifecycle status has some
+var layout = []struct {
+	phase  Phase
+	status string
+}{
+	{PhaseUpcoming, lifecycle.StatusDraft},
+	{PhaseUpcoming, lifecycle.StatusPendingApproval},
+	{PhaseUpcoming, lifecycle.StatusPendingApproval},
+	{PhaseUpcoming, lifecycle.StatusRejected},
+	{PhaseUpcoming, lifecycle.StatusRegistrationOpen},
+	{PhaseUpcoming, lifecycle.StatusRegistrationOpen},
+	{PhaseActive, lifecycle.StatusRunning},
+	{PhaseActive, lifecycle.StatusRunning},
+	{PhaseActive, lifecycle.StatusCancelled},
+	{PhaseJudging, lifecycle.StatusJudging},
+	{PhaseJudging, lifecycle.StatusJudging},
+	{PhaseCompleted, lifecycle.StatusResultsPublished},
+	{PhaseCompleted, lifecycle.StatusResultsPublished},
+	{PhaseCompleted, lifecycle.StatusArchived},
+}
+
+// Dataset is the full set of demo records derived from one seed value
+type Dataset struct {
+	Users        []models.User
+	Hackathons   []SeedHackathon
+	Participants []SeedParticipant
+}
+
+// SeedHackathon is a hackathon together with the indexes of its organiser and of
+// the judges it invited in Dataset.Users
+type SeedHackathon struct {
+	Hackathon models.Hackathon
+	Phase     Phase
+	Organiser int
+	Judges    []int
+}
+
+// SeedParticipant links a user and a hackathon of the dataset by their indexes
+type SeedParticipant struct {
+	Hackathon int
+	User      int
+	DemoUrl   string
+	CodeUrl   string
+	Scores    []SeedScore
+}
+
+// SeedScore is the score a judge, by index in Dataset.Users, gave an entry
+type SeedScore struct {
+	Judge int
+	Score int
+}
+
+// Generate builds the demo dataset for a seed value. The same seed always yields
+// the same users, hackathons, judges and scores; only the timeline is anchored on now.
+func Generate(seed int64, now time.Time) Dataset {
+	rng := rand.New(rand.NewSource(seed))
+	now = now.Truncate(time.Hour)
+
+	var data Dataset
+
+	for i := 0; i < 2; i++ {
+		data.Users = append(data.Users, newUser(rng, fmt.Sprintf("admin%d", i+1), true))
+	}
+	for i := 0; i < 18; i++ {
+		first := firstNames[rng.Intn(len(firstNames))]
+		data.Users = append(data.Users, newUser(rng, fmt.Sprintf("%s%d", strings.ToLower(first), i+1), false))
+	}
+
+	title := 0
+	for _, planned := range layout {
+		data.Hackathons = append(data.Hackathons, newHackathon(rng, &title, planned.phase, planned.status, now, len(data.Users)))
+	}
+
+	for h := range data.Hackathons {
+		hackathon := &data.Hackathons[h]
+		if !hackathon.Hackathon.AdminApproved {
+			continue
+		}
+
+		// nobody takes part in a hackathon they organise or judge
+		taken := map[int]bool{hackathon.Organiser: true}
+		var participants []SeedParticipant
+		count := 3 + rng.Intn(6)
+		for _, u := range rng.Perm(len(data.Users)) {
+			if count == 0 {
+				break
+			}
+			if taken[u] || data.Users[u].IsAdmin {
+				continue
+			}
+			count--
+			taken[u] = true
+
+			participant := SeedParticipant{Hackathon: h, User: u}
+			if hackathon.Phase != PhaseUpcoming && (hackathon.Phase != PhaseActive || rng.Intn(2) == 0) {
+				repoName := strings.ToLower(strings.ReplaceAll(hackathon.Hackathon.Title, " ", "-"))
+				participant.CodeUrl = fmt.Sprintf("https://github.com/%s/%s", data.Users[u].Username, repoName)
+				participant.DemoUrl = fmt.Sprintf("https://%s-%s.example.com", data.Users[u].Username, repoName)
+			}
+			participants = append(participants, participant)
+		}
+
+		if hackathon.Phase != PhaseUpcoming {
+			for _, u := range rng.Perm(len(data.Users)) {
+				if len(hackathon.Judges) == 2 {
+					break
+				}
+				if taken[u] || data.Users[u].IsAdmin {
+					continue
+				}
+				taken[u] = true
+				hackathon.Judges = append(hackathon.Judges, u)
+			}
+		}
+
+		// judges score the submissions once the hackathon ends, all of them by the
+		// time results are out, except those of colleagues
+		for i := range participants {
+			participant := &participants[i]
+			if participant.CodeUrl == "" || (hackathon.Phase != PhaseJudging && hackathon.Phase != PhaseCompleted) {
+				continue
+			}
+			for _, judge := range hackathon.Judges {
+				if data.Users[judge].SameOrganisation(data.Users[participant.User]) {
+					continue
+				}
+				if hackathon.Phase == PhaseJudging && rng.Intn(2) == 0 {
+					continue
+				}
+				participant.Scores = append(participant.Scores, SeedScore{Judge: judge, Score: 40 + rng.Intn(61)})
+			}
+		}
+		data.Participants = append(data.Participants, participants...)
+	}
+
+	return data
+}
+
+func newUser(rng *rand.Rand, username string, isAdmin bool) models.User {
+	first := firstNames[rng.Intn(len(firstNames))]
+	last := lastNames[rng.Intn(len(lastNames))]
+	return models.User{
+		Username:     username,
+		FullName:     first + " " + last,
+		Email:        username + "@winathon.dev",
+		LinkedIn:     "https://www.linkedin.com/in/" + username,
+		GitHub:       "https://github.com/" + username,
+		Organisation: organisations[rng.Intn(len(organisations))],
+		IsAdmin:      isAdmin,
+	}
+}
+
+func newHackathon(rng *rand.Rand, title *int, phase Phase, status string, now time.Time, users int) SeedHackathon {
+	*title++
+	theme := themes[rng.Intn(len(themes))]
+	format := formats[rng.Intn(len(formats))]
+
+	var start time.Time
+	switch phase {
+	case PhaseUpcoming:
+		start = now.AddDate(0, 0, 7+rng.Intn(21))
+	case PhaseActive:
+		start = now.Add(-time.Duration(2+rng.Intn(20)) * time.Hour)
+	case PhaseJudging:
+		start = now.AddDate(0, 0, -3)
+	case PhaseCompleted:
+		start = now.AddDate(0, 0, -30-rng.Intn(60))
+	}
+	end := start.Add(48 * time.Hour)
+	result := end.Add(48 * time.Hour)
+
+	// cancelled hackathons keep the approval they had
+	approved := lifecycle.IsApproved(status) || status == lifecycle.StatusCancelled
+	organiser := 2 + rng.Intn(users-2)
+	if approved && rng.Intn(3) == 0 {
+		organiser = rng.Intn(2)
+	}
+
+	hackathon := SeedHackathon{
+		Hackathon: models.Hackathon{
+			Title:            fmt.Sprintf("%s %s #%d", theme, format, *title),
+			StartingTime:     start,
+			EndingTime:       end,
+			ResultTime:       result,
+			OrganisationName: organisations[rng.Intn(len(organisations))],
+			Description:      fmt.Sprintf("A 48 hour %s event for builders working on %s.", strings.ToLower(format), strings.ToLower(theme)),
+			AdminApproved:    approved,
+			Status:           status,
+		},
+		Phase:     phase,
+		Organiser: organiser,
+	}
+	if status == lifecycle.StatusCancelled {
+		hackathon.Hackathon.CancellationReason = "The venue is no longer available."
+	}
+	return hackathon
+}
+
+// Run generates the dataset for seed and writes it to the database. Records are
+// matched on their natural keys, so running it again updates rather than duplicates them.
+func Run(seed int64) error {
+	data := Generate(seed, time.Now())
+
+	hashedPassword, err := utils.HashPassword(DefaultPassword)
+	if err != nil {
+		return err
+	}
+
+	users := make([]models.User, len(data.Users))
+	for i, user := range data.Users {
+		user.HashedPassword = hashedPassword
+		if err := database.DB.Where(models.User{Username: user.Username}).Attrs(user).FirstOrCreate(&users[i]).Error; err != nil {
+			return fmt.Errorf("cannot seed user %s: %w", user.Username, err)
+		}
+	}
+
+	hackathons := make([]models.Hackathon, len(data.Hackathons))
+	for i, seeded := range data.Hackathons {
+		hackathon := seeded.Hackathon
+		hackathon.OrganiserID = int(users[seeded.Organiser].ID)
+		if err := database.DB.Where(models.Hackathon{Title: hackathon.Title}).Assign(hackathon).FirstOrCreate(&hackathons[i]).Error; err != nil {
+			return fmt.Errorf("cannot seed hackathon %s: %w", hackathon.Title, err)
+		}
+	}
+
+	judges := 0
+	for i, seeded := range data.Hackathons {
+		for _, u := range seeded.Judges {
+			judge := models.Judge{HackathonID: hackathons[i].ID, UserID: users[u].ID}
+			if err := database.DB.Where(judge).FirstOrCreate(&judge).Error; err != nil {
+				return fmt.Errorf("cannot seed judge of %s: %w", hackathons[i].Title, err)
+			}
+			judges++
+		}
+	}
+
+	// the scores of the entries are combined from the ones judges give, as they are
+	// when judges score through the API
+	scores := 0
+	for _, seeded := range data.Participants {
+		participant := models.Participant{
+			HackathonId: int(hackathons[seeded.Hackathon].ID),
+			UserId:      int(users[seeded.User].ID),
+		}
+		submission := models.Participant{
+			DemoUrl: seeded.DemoUrl,
+			CodeUrl: seeded.CodeUrl,
+		}
+		if err := database.DB.Where(participant).Assign(submission).FirstOrCreate(&participant).Error; err != nil {
+			return fmt.Errorf("cannot seed participant: %w", err)
+		}
+
+		for _, score := range seeded.Scores {
+			judged := models.JudgeScore{JudgeID: users[score.Judge].ID, Score: float64(score.Score)}
+			entry := assignment.Entry{UserID: users[seeded.User].ID}
+			if err := repo.JudgeEntry(&judged, hackathons[seeded.Hackathon], entry); err != nil {
+				return fmt.Errorf("cannot seed score: %w", err)
+			}
+			scores++
+		}
+	}
+
+	fmt.Printf("seeded %d users, %d hackathons, %d judges, %d participants and %d scores (password %q)\n",
+		len(users), len(hackathons), judges, len(data.Participants), scores, DefaultPassword)
+	return nil
+}
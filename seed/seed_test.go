@@ -0,0 +1,73 @@
+package seed
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"win-a-thon/lifecycle"
+)
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	now := time.Date(2021, 8, 2, 10, 30, 0, 0, time.UTC)
+
+	first := Generate(42, now)
+	second := Generate(42, now)
+	require.Equal(t, first, second)
+
+	other := Generate(7, now)
+	require.NotEqual(t, first.Users, other.Users)
+}
+
+func TestGeneratePhases(t *testing.T) {
+	now := time.Date(2021, 8, 2, 10, 0, 0, 0, time.UTC)
+	data := Generate(1, now)
+
+	seen := map[Phase]bool{}
+	statuses := map[string]bool{}
+	pending := 0
+	for _, seeded := range data.Hackathons {
+		hackathon := seeded.Hackathon
+		statuses[hackathon.Status] = true
+		if !hackathon.AdminApproved {
+			pending++
+			continue
+		}
+		seen[seeded.Phase] = true
+		// the stored status is where the timeline has the hackathon already
+		require.Equal(t, hackathon.Status, lifecycle.Current(hackathon, now))
+
+		switch seeded.Phase {
+		case PhaseUpcoming:
+			require.True(t, now.Before(hackathon.StartingTime))
+		case PhaseActive:
+			require.True(t, now.After(hackathon.StartingTime) && now.Before(hackathon.EndingTime))
+		case PhaseJudging:
+			require.True(t, now.After(hackathon.EndingTime) && now.Before(hackathon.ResultTime))
+		case PhaseCompleted:
+			require.True(t, now.After(hackathon.ResultTime))
+		}
+		require.False(t, data.Users[seeded.Organiser].Username == "")
+	}
+	require.Len(t, seen, len(phases))
+	require.NotZero(t, pending)
+	for _, status := range []string{lifecycle.StatusDraft, lifecycle.StatusPendingApproval, lifecycle.StatusRejected,
+		lifecycle.StatusRegistrationOpen, lifecycle.StatusRunning, lifecycle.StatusJudging,
+		lifecycle.StatusResultsPublished, lifecycle.StatusArchived, lifecycle.StatusCancelled} {
+		require.True(t, statuses[status], status)
+	}
+
+	scored := 0
+	for _, participant := range data.Participants {
+		hackathon := data.Hackathons[participant.Hackathon]
+		require.NotEqual(t, hackathon.Organiser, participant.User)
+		require.NotContains(t, hackathon.Judges, participant.User)
+		require.False(t, data.Users[participant.User].IsAdmin)
+		for _, score := range participant.Scores {
+			require.Contains(t, []Phase{PhaseJudging, PhaseCompleted}, hackathon.Phase)
+			require.Contains(t, hackathon.Judges, score.Judge)
+			require.False(t, data.Users[score.Judge].SameOrganisation(data.Users[participant.User]))
+			scored++
+		}
+	}
+	require.NotZero(t, scored)
+}
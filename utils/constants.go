@@ -1,7 +1,52 @@
 package utils
 
+import "time"
+
 const (
 	TokenSymmetricKey       = "12345678901234567890123456789012"
 	AccessTokenDuration     = "150m"
 	AuthorizationPayloadKey = "authorization_payload"
 )
+
+// Values of the value path parameter of the admin approval endpoint
+const (
+	ApprovalReject  = "0"
+	ApprovalApprove = "1"
+)
+
+// MaxReasonLength is how many characters the reason given for cancelling or
+// rejecting a hackathon or declaring a conflict of interest can have
+const MaxReasonLength = 500
+
+// Limits on People's Choice voting, against ballots stuffed from throwaway accounts
+const (
+	// VoterAccountAge is how long after signing up an account may vote
+	VoterAccountAge = 72 * time.Hour
+	// MaxBallotsPerIP is how many accounts may vote in a hackathon from one IP address
+	MaxBallotsPerIP = 5
+)
+
+// Limits on the files uploaded with submissions
+const (
+	// MaxArtifactSize is the largest file that can be uploaded, in bytes
+	MaxArtifactSize = 50 << 20
+	// MaxArtifactsPerUpload is how many files one submission can upload
+	MaxArtifactsPerUpload = 3
+	// MaxArtifactsPerEntry is how many files an entry can keep
+	MaxArtifactsPerEntry = 10
+	// ArtifactLinkTTL is how long a download link to an artifact works
+	ArtifactLinkTTL = 15 * time.Minute
+)
+
+// ArtifactTypes are the types of file that can be uploaded, as sniffed from their
+// content. Slide decks and documents saved as .pptx, .key or .docx sniff as zip.
+var ArtifactTypes = map[string]bool{
+	"application/pdf":    true,
+	"application/zip":    true,
+	"application/x-gzip": true,
+	"image/png":          true,
+	"image/jpeg":         true,
+	"image/gif":          true,
+	"video/mp4":          true,
+	"video/webm":         true,
+}
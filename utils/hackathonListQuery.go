@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Hackathon statuses a listing can be filtered on, derived from the hackathon timeline
+const (
+	StatusUpcoming  = "upcoming"
+	StatusActive    = "active"
+	StatusJudging   = "judging"
+	StatusCompleted = "completed"
+)
+
+// SortOrder is a column a hackathon listing can be ordered on
+type SortOrder struct {
+	Column string
+	Desc   bool
+}
+
+// HackathonSorts maps the accepted sort query values to their columns
+var HackathonSorts = map[string]SortOrder{
+	"starting_time_desc": {"starting_time", true},
+	"starting_time_asc":  {"starting_time", false},
+	"ending_time_desc":   {"ending_time", true},
+	"ending_time_asc":    {"ending_time", false},
+	"created_at_desc":    {"created_at", true},
+	"title_asc":          {"title", false},
+}
+
+const DefaultHackathonSort = "starting_time_desc"
+
+// HackathonListQuery holds the filtering, sorting and pagination options of a hackathon listing
+type HackathonListQuery struct {
+	Status           string    `form:"status"`
+	OrganisationName string    `form:"organisation_name"`
+	From             time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To               time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort             string    `form:"sort"`
+	Limit            int       `form:"limit"`
+	Cursor           string    `form:"cursor"`
+}
+
+// Validate fills in the defaults and rejects unknown statuses, sorts and page sizes
+func (q *HackathonListQuery) Validate() error {
+	switch q.Status {
+	case "", StatusUpcoming, StatusActive, StatusJudging, StatusCompleted:
+	default:
+		return errors.New("status should be one of upcoming, active, judging or completed")
+	}
+
+	if q.Sort == "" {
+		q.Sort = DefaultHackathonSort
+	}
+	if _, ok := HackathonSorts[q.Sort]; !ok {
+		return errors.New("unknown sort order")
+	}
+
+	if q.Limit == 0 {
+		q.Limit = DefaultPageSize
+	}
+	if q.Limit < 0 || q.Limit > MaxPageSize {
+		return errors.New("limit should be between 1 and 100")
+	}
+
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return errors.New("to should be after from")
+	}
+
+	if q.Cursor != "" {
+		if _, err := DecodeCursor(q.Cursor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cursor points at the last row of a page: its value in the sort column and its id
+type Cursor struct {
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+// EncodeCursor returns the opaque form of a cursor used in query strings
+func EncodeCursor(cursor Cursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor
+func DecodeCursor(encoded string) (Cursor, error) {
+	var cursor Cursor
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, errors.New("invalid cursor")
+	}
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.ID == 0 {
+		return cursor, errors.New("invalid cursor")
+	}
+	return cursor, nil
+}
+
+// PageInfo is returned next to every paginated listing
+type PageInfo struct {
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Next       string `json:"next,omitempty"`
+}
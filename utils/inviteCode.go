@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// NewInviteCode returns a random code participants can share to let others join their team
+func NewInviteCode() (string, error) {
+	code := make([]byte, 8)
+	if _, err := rand.Read(code); err != nil {
+		return "", fmt.Errorf("failed to generate invite code: %w", err)
+	}
+	return hex.EncodeToString(code), nil
+}
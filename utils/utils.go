@@ -1,5 +1,7 @@
 package utils
 
+import "time"
+
 type UpdateProfileRequest struct {
 	Email        string `json:"email"`
 	LinkedIn     string `json:"linked_in"`
@@ -7,3 +9,86 @@ type UpdateProfileRequest struct {
 	WebLink      string `json:"web_link"`
 	Organisation string `json:"organisation"`
 }
+
+// UpdateHackathonRequest holds the hackathon fields an organiser may edit, empty ones are left unchanged
+type UpdateHackathonRequest struct {
+	Title            string    `json:"title"`
+	Description      string    `json:"description"`
+	OrganisationName string    `json:"organisation_name"`
+	StartingTime     time.Time `json:"starting_time"`
+	EndingTime       time.Time `json:"ending_time"`
+	ResultTime       time.Time `json:"result_time"`
+	// each end of the registration window is only replaced when given, the window is
+	// removed with clear_registration
+	RegistrationOpensAt  *time.Time `json:"registration_opens_at"`
+	RegistrationClosesAt *time.Time `json:"registration_closes_at"`
+	ClearRegistration    bool       `json:"clear_registration"`
+	ScoreAggregation     string     `json:"score_aggregation"`
+	// normalization is turned off with "none"
+	ScoreNormalization   string `json:"score_normalization"`
+	ReviewsPerSubmission *int   `json:"reviews_per_submission"`
+	BlindJudging         *bool  `json:"blind_judging"`
+	// tie-breakers are cleared with an empty string
+	TieBreakers *string `json:"tie_breakers"`
+	// each voting setting is only replaced when given, voting is turned off with clear_voting
+	VotingClosesAt *time.Time `json:"voting_closes_at"`
+	VotesPerUser   *int       `json:"votes_per_user"`
+	ClearVoting    bool       `json:"clear_voting"`
+}
+
+type CancelHackathonRequest struct {
+	Reason string `json:"reason"`
+}
+
+type RejectHackathonRequest struct {
+	Reason string `json:"reason"`
+}
+
+type CreateTeamRequest struct {
+	Name string `json:"name"`
+}
+
+type JoinTeamRequest struct {
+	InviteCode string `json:"invite_code"`
+}
+
+type CreateTrackRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ParticipateRequest optionally picks the track to compete in when registering
+type ParticipateRequest struct {
+	TrackID *uint `json:"track_id"`
+}
+
+// JudgeSubmissionRequest is the score a judge gives an entry, as points per criterion
+// when the hackathon has a rubric, with feedback for the participants and notes only
+// the organiser sees
+type JudgeSubmissionRequest struct {
+	Score    float64           `json:"score"`
+	Criteria []CriterionPoints `json:"criteria"`
+	Feedback string            `json:"feedback"`
+	Notes    string            `json:"notes"`
+}
+
+type CriterionPoints struct {
+	CriterionID uint    `json:"criterion_id"`
+	Points      float64 `json:"points"`
+}
+
+type CreateCriterionRequest struct {
+	Name      string  `json:"name"`
+	MaxPoints float64 `json:"max_points"`
+	Weight    float64 `json:"weight"`
+}
+
+// DeclareConflictRequest explains the conflict of interest a judge has with a participant
+type DeclareConflictRequest struct {
+	Reason string `json:"reason"`
+}
+
+// CastVotesRequest holds the IDs of the entries a user votes for, most preferred first
+type CastVotesRequest struct {
+	EntryIDs []string `json:"entry_ids"`
+}